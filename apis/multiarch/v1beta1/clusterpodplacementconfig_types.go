@@ -18,14 +18,53 @@ package v1beta1
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common"
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common/plugins"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+const (
+	DefaultWebhookWorkerPoolSize     int32 = 50
+	DefaultGateRemovalWorkerPoolSize int32 = 100
+	DefaultMaxConcurrentInspections  int32 = 5
+
+	DefaultExcludedOwnerKind = "DaemonSet"
+
+	DefaultImageInspectionRetryInitialInterval       = 500 * time.Millisecond
+	DefaultImageInspectionRetryMaxInterval           = 10 * time.Second
+	DefaultImageInspectionRetryMultiplier            = 2.0
+	DefaultImageInspectionRetryMaxRetries      int32 = 3
+
+	DefaultImageInspectionCircuitBreakerFailureThreshold int32 = 5
+	DefaultImageInspectionCircuitBreakerResetTimeout           = 30 * time.Second
+
+	DefaultMaxGateDuration = 10 * time.Minute
+
+	DefaultDelayedEventBackoffDuration       = 2 * time.Millisecond
+	DefaultDelayedEventBackoffFactor         = 2.0
+	DefaultDelayedEventBackoffSteps    int32 = 15
+	DefaultDelayedEventBackoffJitter         = 0.0
+
+	DefaultCacheJitterFraction = 0.2
+
+	DefaultLogSamplingRate = 1.0
+
+	DefaultImageInspectionErrorRateThreshold int32 = 20
+
+	DefaultVirtualNodePoolLabel = "type"
+
+	// CustomCABundleKey is the key of the PEM bundle in the Secret referenced by the CustomCABundleSecretRef.
+	CustomCABundleKey = "ca-bundle.crt"
 )
 
 // ClusterPodPlacementConfigSpec defines the desired state of ClusterPodPlacementConfig
@@ -37,6 +76,13 @@ type ClusterPodPlacementConfigSpec struct {
 	// +kubebuilder:default=Normal
 	LogVerbosity common.LogVerbosityLevel `json:"logVerbosity,omitempty"`
 
+	// LogSamplingRate is the fraction, from 0 to 1, of the pods ignored by the pod placement webhook whose admission
+	// is logged at the TraceAll verbosity, e.g., "0.01" to log 1% of them, to reduce the log noise of the clusters
+	// admitting many pods. The errors and the less verbose logs are never sampled. Defaults to "1".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	LogSamplingRate string `json:"logSamplingRate,omitempty"`
+
 	// NamespaceSelector selects the namespaces where the pod placement operand can process the nodeAffinity
 	// of the pods. If left empty, all the namespaces are considered.
 	// The default sample allows to exclude all the namespaces where the
@@ -48,6 +94,613 @@ type ClusterPodPlacementConfigSpec struct {
 	// This field is optional and will be omitted from the output if not set.
 	// +optional
 	Plugins *plugins.Plugins `json:"plugins,omitempty"`
+
+	// WebhookWorkerPoolSize is the number of workers the pod placement webhook uses to publish
+	// the events of the pods it gates. Defaults to 50.
+	// +optional
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=1
+	WebhookWorkerPoolSize int32 `json:"webhookWorkerPoolSize,omitempty"`
+
+	// DelayedEventBackoff configures the exponential backoff the pod placement webhook uses to get the pods it gates
+	// from the API server, in order to publish their events once they are created. On large clusters, a jitter
+	// spreads the requests following a mass creation of pods.
+	// +optional
+	DelayedEventBackoff *DelayedEventBackoff `json:"delayedEventBackoff,omitempty"`
+
+	// GateRemovalWorkerPoolSize is the maximum number of pods the pod placement controller
+	// processes concurrently to remove the scheduling gate. Defaults to 100.
+	// +optional
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=1
+	GateRemovalWorkerPoolSize int32 `json:"gateRemovalWorkerPoolSize,omitempty"`
+
+	// MaxConcurrentInspections is the maximum number of distinct images of a pod the pod placement operands
+	// inspect concurrently. Defaults to 5.
+	// +optional
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentInspections int32 `json:"maxConcurrentInspections,omitempty"`
+
+	// EnforceArchitectureCompatibility enables a validating webhook that rejects, at admission time,
+	// the pods whose images do not support any common architecture. The Jobs and CronJobs whose pod templates
+	// use such images are admitted with a warning.
+	// Defaults to false.
+	// +optional
+	EnforceArchitectureCompatibility bool `json:"enforceArchitectureCompatibility,omitempty"`
+
+	// ImageInspectionRetryPolicy configures the retries of the image inspections failing with transient errors,
+	// like the 5xx responses of the registries or the connection resets.
+	// +optional
+	ImageInspectionRetryPolicy *ImageInspectionRetryPolicy `json:"imageInspectionRetryPolicy,omitempty"`
+
+	// ImageInspectionCircuitBreaker configures the circuit breakers that skip the inspection of the images
+	// hosted by the registries that are persistently failing.
+	// +optional
+	ImageInspectionCircuitBreaker *ImageInspectionCircuitBreaker `json:"imageInspectionCircuitBreaker,omitempty"`
+
+	// CacheJitterFraction is the fraction of the TTL of the image inspection cache entries that is randomly added
+	// to or subtracted from it, so that the entries added together, e.g., after a restart of the operands, are not
+	// inspected again all at once. It must be lower than 1. Defaults to "0.2".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^0(\.[0-9]+)?$`
+	CacheJitterFraction string `json:"cacheJitterFraction,omitempty"`
+
+	// PinDigestInCache enables the resolution of the tags of the images to their digest before their inspection, so
+	// that the image inspection cache is keyed by digest: a tag updated between the inspection of its image and the
+	// scheduling of the pods, e.g., from a multi-arch image to a single-arch one, does not hit the entry of the previous
+	// image. Each lookup of a tagged image costs a request to its registry. Defaults to false.
+	// +optional
+	PinDigestInCache bool `json:"pinDigestInCache,omitempty"`
+
+	// RegistryTimeouts maps the registry hostnames, e.g., mirror.example.com:5000, to the maximum duration of the
+	// inspections of the images they host, so that the slow registries, like some air-gapped mirrors, fail fast
+	// into the InspectionTimeoutFallback. The inspections of the images hosted by the other registries are only
+	// bound by the global timeout, which also applies when it expires earlier. The durations must be positive.
+	// +optional
+	RegistryTimeouts map[string]metav1.Duration `json:"registryTimeouts,omitempty"`
+
+	// DisableGateInjection is an emergency switch that stops the pod placement operand from gating the new pods.
+	// When it is set to true, the scheduling gate is also removed from the pods that are currently gated, which are
+	// scheduled without the architecture-aware node affinity. Defaults to false.
+	// +optional
+	DisableGateInjection bool `json:"disableGateInjection,omitempty"`
+
+	// EnableArchitectureLabels lets the pod placement operand label the pods with the architectures they support,
+	// e.g., multiarch.openshift.io/arm64, and with multiarch.openshift.io/single-arch,
+	// multiarch.openshift.io/multi-arch or multiarch.openshift.io/no-supported-arch. When it is set to false, the
+	// node affinity of the pods is still set, but these labels are not, e.g., to limit the cardinality of the labels.
+	// Defaults to true.
+	// +optional
+	// +kubebuilder:default=true
+	EnableArchitectureLabels *bool `json:"enableArchitectureLabels,omitempty"`
+
+	// TrimToClusterArchitectures trims the architectures supported by the images of the pods to the ones of the
+	// nodes of the cluster when the pod placement operand computes their node affinity. The pods whose images support
+	// none of the architectures of the nodes are then reported as not supported as soon as they are
+	// processed. The pods processed before the nodes of a new architecture join the cluster are not updated.
+	// Defaults to false.
+	// +optional
+	TrimToClusterArchitectures bool `json:"trimToClusterArchitectures,omitempty"`
+
+	// RequireArchLabelOnNodes lets the pod placement operand add a requirement on the existence of the
+	// kubernetes.io/arch label to every node selector term of the required node affinity of the pods, so that they are
+	// not scheduled on the nodes lacking the label, e.g., in the clusters with nodes not managed by the kubelet.
+	// The terms requiring the label to be in a set of architectures already exclude these nodes. Defaults to false.
+	// +optional
+	RequireArchLabelOnNodes bool `json:"requireArchLabelOnNodes,omitempty"`
+
+	// RepairStaleArchitectureAffinity lets the pod placement operand repair the pods whose architecture requirement,
+	// e.g., set by a previous version of the operator, differs from the one of their images. The requirement of the images
+	// is added to the one of the pods still gated, e.g., by other operators; the other pods, e.g., the scheduled ones, are
+	// labeled with multiarch.openshift.io/stale-architecture-affinity and get an event. The pods are never deleted. Each
+	// pod is checked once. The pods given the InspectionTimeoutFallback are skipped. Defaults to false.
+	// +optional
+	RepairStaleArchitectureAffinity bool `json:"repairStaleArchitectureAffinity,omitempty"`
+
+	// PerNamespaceMetrics adds the namespace label to the per-namespace metrics of the gated and processed pods.
+	// It is disabled by default to avoid a cardinality explosion in the clusters with thousands of namespaces:
+	// in that case, the namespace label of these metrics is empty.
+	// Defaults to false.
+	// +optional
+	PerNamespaceMetrics bool `json:"perNamespaceMetrics,omitempty"`
+
+	// MaxGateDuration is the maximum time a pod can stay gated. The scheduling gate of the pods gated for longer,
+	// e.g., because the images cannot be inspected, is forcibly removed, so that the scheduler can evaluate them
+	// without the architecture-aware node affinity. Defaults to 10m.
+	// +optional
+	MaxGateDuration *metav1.Duration `json:"maxGateDuration,omitempty"`
+
+	// PodExclusionLabelSelector selects the pods the pod placement webhook does not gate.
+	// The pods whose labels match the selector are admitted without the scheduling gate and their
+	// nodeAffinity is not modified. If not set, no pods are excluded.
+	// +optional
+	PodExclusionLabelSelector *metav1.LabelSelector `json:"podExclusionLabelSelector,omitempty"`
+
+	// Proxy configures the proxy the pod placement operands use to reach the registries to inspect the images.
+	// Its fields override the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables of the operator, which
+	// the operands otherwise inherit.
+	// +optional
+	Proxy *ImageInspectionProxy `json:"proxy,omitempty"`
+
+	// EnablePprof enables the pprof handlers of the pod placement components at /debug/pprof/ on the port 8083.
+	// The port is not exposed by any Service and must not be exposed outside the cluster.
+	// Defaults to false.
+	// +optional
+	EnablePprof bool `json:"enablePprof,omitempty"`
+
+	// SchedulingGateNameOverride is the name of the scheduling gate the pod placement operand adds to the pods and
+	// removes once their node affinity is set. It allows, e.g., running a canary instance of the operator in the same
+	// cluster. The pods gated with a previous name are not ungated by the operand after the name changes.
+	// Defaults to "multiarch.openshift.io/scheduling-gate".
+	// +optional
+	// +kubebuilder:validation:MaxLength=316
+	SchedulingGateNameOverride string `json:"schedulingGateNameOverride,omitempty"`
+
+	// ManagedSchedulingGates are the names of the additional scheduling gates the pod placement operand adds to the
+	// pods, e.g., for the sequential stages of a processing pipeline such as a security scan. Each gate is removed
+	// independently, by its own controller, once its stage lists it in the
+	// multiarch.openshift.io/completed-scheduling-gates annotation of the pod. The pods gated with a gate removed
+	// from the list are not ungated by the operand.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:MaxLength=316
+	ManagedSchedulingGates []string `json:"managedSchedulingGates,omitempty"`
+
+	// GlobalImagePullSecretRef references the Secret holding the registry credentials the pod placement controllers
+	// use to inspect the images of all the pods, in addition to the pods' image pull secrets. The pods' image pull
+	// secrets take precedence on the credentials of the same registries. The Secret must be of type
+	// kubernetes.io/dockerconfigjson or kubernetes.io/dockercfg.
+	// Defaults to the cluster global pull secret, openshift-config/pull-secret.
+	// +optional
+	GlobalImagePullSecretRef *corev1.SecretReference `json:"globalImagePullSecretRef,omitempty"`
+
+	// CustomCABundleSecretRef references the Secret holding, in its ca-bundle.crt key, the PEM bundle of the
+	// certificate authorities the pod placement operands trust, in addition to the system ones, to verify the TLS
+	// certificates of all the registries, e.g., an internal enterprise CA. The Secret must be in the namespace of the
+	// operator. The operands are rolled out when the bundle changes. The per-registry certificates of the nodes'
+	// /etc/docker/certs.d directories are still honoured.
+	// +optional
+	CustomCABundleSecretRef *corev1.SecretReference `json:"customCABundleSecretRef,omitempty"`
+
+	// ImageInspectionErrorRateThreshold is the percentage of failed image inspections over the last ten minutes
+	// above which the CacheHealthy condition is set to False, with the Degraded reason.
+	// Defaults to 20.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	ImageInspectionErrorRateThreshold *int32 `json:"imageInspectionErrorRateThreshold,omitempty"`
+
+	// InspectionTimeoutFallback is the strategy applied to the pods whose images inspection times out, e.g.,
+	// because a registry is slow. With "block", the pods stay gated and the inspection is retried. With "allow-all",
+	// the node affinity of the pods allows all the supported architectures. With "allow-configured", it allows the
+	// FallbackArchitectures.
+	// Defaults to "block".
+	// +optional
+	// +kubebuilder:default=block
+	InspectionTimeoutFallback InspectionTimeoutFallback `json:"inspectionTimeoutFallback,omitempty"`
+
+	// FallbackArchitectures are the architectures the node affinity of the pods allows when the inspection of their
+	// images times out and InspectionTimeoutFallback is "allow-configured".
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Enum=amd64;arm64;ppc64le;s390x
+	FallbackArchitectures []string `json:"fallbackArchitectures,omitempty"`
+
+	// VirtualNodeTolerationStrategy sets how the pod placement operand handles the pods targeting the virtual nodes,
+	// e.g., the ones of the virtual-kubelet providers like AWS Fargate or Azure ACI, that may not expose the
+	// kubernetes.io/arch label. With "skip", the architecture-aware node affinity is not set for the pods matching
+	// the VirtualNodeMatchers. Defaults to "none".
+	// +optional
+	// +kubebuilder:default=none
+	VirtualNodeTolerationStrategy VirtualNodeTolerationStrategy `json:"virtualNodeTolerationStrategy,omitempty"`
+
+	// VirtualNodeMatchers selects the pods targeting the virtual nodes.
+	// +optional
+	VirtualNodeMatchers *VirtualNodeMatchers `json:"virtualNodeMatchers,omitempty"`
+
+	// TrustedOwnerKinds are the owner kinds the pod placement operand trusts in the
+	// multiarch.openshift.io/owner-kind annotation of the pods, e.g., DaemonSet. The pods annotated with one of
+	// these kinds are treated as infrastructure pods and ignored, like the pods owned by a DaemonSet. This supports
+	// the third-party operators creating DaemonSet-like pods without owner references.
+	// If empty, the annotation is ignored.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:MinLength=1
+	TrustedOwnerKinds []string `json:"trustedOwnerKinds,omitempty"`
+
+	// ExcludedOwnerKinds are the kinds of the controllers whose pods the pod placement operand ignores, e.g.,
+	// VirtualMachine. Only the owner references with controller set to true are considered. The pods controlled by
+	// a DaemonSet are always ignored.
+	// Defaults to ["DaemonSet"].
+	// +optional
+	// +listType=set
+	// +kubebuilder:default={"DaemonSet"}
+	// +kubebuilder:validation:items:MinLength=1
+	ExcludedOwnerKinds []string `json:"excludedOwnerKinds,omitempty"`
+
+	// ExcludedOwnerReferenceKinds are the kinds and API groups of the owners whose pods the pod placement operand
+	// ignores, e.g., the Node owning the static pods, the kubevirt.io VirtualMachineInstance, or any custom resource.
+	// Unlike the ExcludedOwnerKinds, they can match the owner references with controller not set to true.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	ExcludedOwnerReferenceKinds []ExcludedOwnerRef `json:"excludedOwnerReferenceKinds,omitempty"`
+
+	// IgnoreHostNetworkPods lets the pod placement operand ignore the pods with hostNetwork or hostPID set to true,
+	// which are usually infrastructure components, like the CNI plugins or the monitoring agents, with scheduling
+	// constraints of their own. Defaults to false.
+	// +optional
+	IgnoreHostNetworkPods bool `json:"ignoreHostNetworkPods,omitempty"`
+
+	// UniversalImages are the images the pod placement operand treats as supporting all the architectures, without
+	// inspecting them, e.g., the architecture-neutral images or the ones guaranteed to be multi-arch by policy. Each
+	// entry matches the image as set in the pod spec, either exactly or as a glob pattern, e.g.,
+	// registry.example.com/base/*. The * wildcard does not match the / separator.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:MinLength=1
+	UniversalImages []string `json:"universalImages,omitempty"`
+}
+
+// VirtualNodeTolerationStrategy is the strategy applied to the pods targeting the virtual nodes.
+// +kubebuilder:validation:Enum=none;skip
+type VirtualNodeTolerationStrategy string
+
+const (
+	VirtualNodeTolerationStrategyNone VirtualNodeTolerationStrategy = "none"
+	VirtualNodeTolerationStrategySkip VirtualNodeTolerationStrategy = "skip"
+)
+
+// VirtualNodeMatchers selects the pods targeting the virtual nodes, without listing the nodes.
+type VirtualNodeMatchers struct {
+	// Namespaces are the glob patterns, e.g., "fargate-*", of the namespaces whose pods run on virtual nodes.
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NodePools are the glob patterns of the names of the virtual node pools. The pods whose nodeSelector sets the
+	// NodePoolLabel to a matching name run on virtual nodes.
+	// +optional
+	// +listType=set
+	NodePools []string `json:"nodePools,omitempty"`
+
+	// NodePoolLabel is the label holding the name of the node pool of the nodes.
+	// Defaults to "type", as in the "type: virtual-kubelet" node selector of the virtual-kubelet providers.
+	// +optional
+	// +kubebuilder:default=type
+	NodePoolLabel string `json:"nodePoolLabel,omitempty"`
+}
+
+// GetNodePoolLabel returns the configured NodePoolLabel or its default value if it is not set.
+func (m *VirtualNodeMatchers) GetNodePoolLabel() string {
+	if m.NodePoolLabel == "" {
+		return DefaultVirtualNodePoolLabel
+	}
+	return m.NodePoolLabel
+}
+
+// InspectionTimeoutFallback is the strategy applied to the pods whose images inspection times out.
+// +kubebuilder:validation:Enum=block;allow-all;allow-configured
+type InspectionTimeoutFallback string
+
+const (
+	InspectionTimeoutFallbackBlock           InspectionTimeoutFallback = "block"
+	InspectionTimeoutFallbackAllowAll        InspectionTimeoutFallback = "allow-all"
+	InspectionTimeoutFallbackAllowConfigured InspectionTimeoutFallback = "allow-configured"
+)
+
+// ImageInspectionProxy defines the proxy settings of the image inspections.
+type ImageInspectionProxy struct {
+	// HTTPProxy is the URL of the proxy for the HTTP requests to the registries.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for the HTTPS requests to the registries.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames, domains, IP addresses and CIDRs of the registries to reach
+	// without the proxy.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// ImageInspectionCircuitBreaker defines when the inspections of the images of a registry are skipped.
+type ImageInspectionCircuitBreaker struct {
+	// FailureThreshold is the number of consecutive transient errors of a registry that opens its circuit.
+	// Zero disables the circuit breakers. Defaults to 5.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// ResetTimeout is the time after which an open circuit lets a single inspection through to probe
+	// the registry. Defaults to 30s.
+	// +optional
+	ResetTimeout *metav1.Duration `json:"resetTimeout,omitempty"`
+}
+
+// GetFailureThreshold returns the configured FailureThreshold or its default value if it is not set.
+func (c *ImageInspectionCircuitBreaker) GetFailureThreshold() int32 {
+	if c == nil || c.FailureThreshold == nil || *c.FailureThreshold < 0 {
+		return DefaultImageInspectionCircuitBreakerFailureThreshold
+	}
+	return *c.FailureThreshold
+}
+
+// GetResetTimeout returns the configured ResetTimeout or its default value if it is not set.
+func (c *ImageInspectionCircuitBreaker) GetResetTimeout() time.Duration {
+	if c == nil || c.ResetTimeout == nil || c.ResetTimeout.Duration <= 0 {
+		return DefaultImageInspectionCircuitBreakerResetTimeout
+	}
+	return c.ResetTimeout.Duration
+}
+
+// ImageInspectionRetryPolicy defines the exponential backoff used to retry the image inspections.
+type ImageInspectionRetryPolicy struct {
+	// InitialInterval is the time to wait before the first retry. Defaults to 500ms.
+	// +optional
+	InitialInterval *metav1.Duration `json:"initialInterval,omitempty"`
+
+	// MaxInterval caps the time to wait between two retries. Defaults to 10s.
+	// +optional
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+
+	// Multiplier is the factor the time to wait is multiplied by after each retry. Defaults to "2".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Multiplier string `json:"multiplier,omitempty"`
+
+	// MaxRetries is the maximum number of retries of an image inspection. Zero disables the retries.
+	// Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+}
+
+// GetInitialInterval returns the configured InitialInterval or its default value if it is not set.
+func (p *ImageInspectionRetryPolicy) GetInitialInterval() time.Duration {
+	if p == nil || p.InitialInterval == nil || p.InitialInterval.Duration <= 0 {
+		return DefaultImageInspectionRetryInitialInterval
+	}
+	return p.InitialInterval.Duration
+}
+
+// GetMaxInterval returns the configured MaxInterval or its default value if it is not set.
+func (p *ImageInspectionRetryPolicy) GetMaxInterval() time.Duration {
+	if p == nil || p.MaxInterval == nil || p.MaxInterval.Duration <= 0 {
+		return DefaultImageInspectionRetryMaxInterval
+	}
+	return p.MaxInterval.Duration
+}
+
+// GetMultiplier returns the configured Multiplier or its default value if it is not set or not valid.
+func (p *ImageInspectionRetryPolicy) GetMultiplier() float64 {
+	if p == nil {
+		return DefaultImageInspectionRetryMultiplier
+	}
+	multiplier, err := strconv.ParseFloat(p.Multiplier, 64)
+	if err != nil || multiplier < 1 {
+		return DefaultImageInspectionRetryMultiplier
+	}
+	return multiplier
+}
+
+// GetMaxRetries returns the configured MaxRetries or its default value if it is not set.
+func (p *ImageInspectionRetryPolicy) GetMaxRetries() int32 {
+	if p == nil || p.MaxRetries == nil || *p.MaxRetries < 0 {
+		return DefaultImageInspectionRetryMaxRetries
+	}
+	return *p.MaxRetries
+}
+
+// ExcludedOwnerRef identifies the owners whose pods the pod placement operand ignores.
+type ExcludedOwnerRef struct {
+	// Kind is the kind of the owner, e.g., VirtualMachineInstance.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9]*$`
+	Kind string `json:"kind"`
+
+	// APIGroup is the API group of the owner, e.g., kubevirt.io. It is empty for the core API group, e.g., for the
+	// Node owning the static pods.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	APIGroup string `json:"apiGroup,omitempty"`
+
+	// RequireControllerTrue restricts the exclusion to the pods the owner reference of which has controller set to
+	// true. When false, all the pods with an owner reference of the kind are ignored. Defaults to false.
+	// +optional
+	RequireControllerTrue bool `json:"requireControllerTrue,omitempty"`
+}
+
+// Matches returns true if the owner reference is of the kind and API group of the ExcludedOwnerRef, and it is a
+// controller reference if RequireControllerTrue is set.
+func (r ExcludedOwnerRef) Matches(ownerReference metav1.OwnerReference) bool {
+	if ownerReference.Kind != r.Kind {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(ownerReference.APIVersion)
+	if err != nil || gv.Group != r.APIGroup {
+		return false
+	}
+	return !r.RequireControllerTrue || (ownerReference.Controller != nil && *ownerReference.Controller)
+}
+
+// DelayedEventBackoff defines the exponential backoff used to get the pods gated by the pod placement webhook.
+type DelayedEventBackoff struct {
+	// Duration is the time to wait before the first retry. Defaults to 2ms.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// Factor is the factor the time to wait is multiplied by after each retry. Defaults to "2".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Factor string `json:"factor,omitempty"`
+
+	// Steps is the maximum number of attempts to get a pod. Defaults to 15.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Steps *int32 `json:"steps,omitempty"`
+
+	// Jitter is the maximum fraction of the time to wait that is randomly added to it. Zero disables the jitter.
+	// Defaults to "0".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Jitter string `json:"jitter,omitempty"`
+}
+
+// GetDuration returns the configured Duration or its default value if it is not set.
+func (b *DelayedEventBackoff) GetDuration() time.Duration {
+	if b == nil || b.Duration == nil || b.Duration.Duration <= 0 {
+		return DefaultDelayedEventBackoffDuration
+	}
+	return b.Duration.Duration
+}
+
+// GetFactor returns the configured Factor or its default value if it is not set or not valid.
+func (b *DelayedEventBackoff) GetFactor() float64 {
+	if b == nil {
+		return DefaultDelayedEventBackoffFactor
+	}
+	factor, err := strconv.ParseFloat(b.Factor, 64)
+	if err != nil || factor < 1 {
+		return DefaultDelayedEventBackoffFactor
+	}
+	return factor
+}
+
+// GetSteps returns the configured Steps or its default value if it is not set.
+func (b *DelayedEventBackoff) GetSteps() int32 {
+	if b == nil || b.Steps == nil || *b.Steps < 1 {
+		return DefaultDelayedEventBackoffSteps
+	}
+	return *b.Steps
+}
+
+// GetJitter returns the configured Jitter or its default value if it is not set or not valid.
+func (b *DelayedEventBackoff) GetJitter() float64 {
+	if b == nil {
+		return DefaultDelayedEventBackoffJitter
+	}
+	jitter, err := strconv.ParseFloat(b.Jitter, 64)
+	if err != nil || jitter < 0 {
+		return DefaultDelayedEventBackoffJitter
+	}
+	return jitter
+}
+
+// GetWebhookWorkerPoolSize returns the configured WebhookWorkerPoolSize or its default value if it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetWebhookWorkerPoolSize() int32 {
+	if s.WebhookWorkerPoolSize <= 0 {
+		return DefaultWebhookWorkerPoolSize
+	}
+	return s.WebhookWorkerPoolSize
+}
+
+// GetGateRemovalWorkerPoolSize returns the configured GateRemovalWorkerPoolSize or its default value if it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetGateRemovalWorkerPoolSize() int32 {
+	if s.GateRemovalWorkerPoolSize <= 0 {
+		return DefaultGateRemovalWorkerPoolSize
+	}
+	return s.GateRemovalWorkerPoolSize
+}
+
+// GetRegistryTimeouts returns the positive RegistryTimeouts as durations.
+func (s *ClusterPodPlacementConfigSpec) GetRegistryTimeouts() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(s.RegistryTimeouts))
+	for registry, timeout := range s.RegistryTimeouts {
+		if timeout.Duration > 0 {
+			timeouts[registry] = timeout.Duration
+		}
+	}
+	return timeouts
+}
+
+// GetCacheJitterFraction returns the configured CacheJitterFraction or its default value if it is not set or not
+// valid.
+func (s *ClusterPodPlacementConfigSpec) GetCacheJitterFraction() float64 {
+	jitterFraction, err := strconv.ParseFloat(s.CacheJitterFraction, 64)
+	if err != nil || jitterFraction < 0 || jitterFraction >= 1 {
+		return DefaultCacheJitterFraction
+	}
+	return jitterFraction
+}
+
+// GetLogSamplingRate returns the configured LogSamplingRate or its default value if it is not set or not valid.
+func (s *ClusterPodPlacementConfigSpec) GetLogSamplingRate() float64 {
+	samplingRate, err := strconv.ParseFloat(s.LogSamplingRate, 64)
+	if err != nil || samplingRate < 0 || samplingRate > 1 {
+		return DefaultLogSamplingRate
+	}
+	return samplingRate
+}
+
+// GetExcludedOwnerKinds returns the configured ExcludedOwnerKinds or their default value if they are not set.
+func (s *ClusterPodPlacementConfigSpec) GetExcludedOwnerKinds() []string {
+	if s.ExcludedOwnerKinds == nil {
+		return []string{DefaultExcludedOwnerKind}
+	}
+	return s.ExcludedOwnerKinds
+}
+
+// GetMaxConcurrentInspections returns the configured MaxConcurrentInspections or its default value if it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetMaxConcurrentInspections() int32 {
+	if s.MaxConcurrentInspections <= 0 {
+		return DefaultMaxConcurrentInspections
+	}
+	return s.MaxConcurrentInspections
+}
+
+// GetMaxGateDuration returns the configured MaxGateDuration or its default value if it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetMaxGateDuration() time.Duration {
+	if s.MaxGateDuration == nil || s.MaxGateDuration.Duration <= 0 {
+		return DefaultMaxGateDuration
+	}
+	return s.MaxGateDuration.Duration
+}
+
+// GetSchedulingGateName returns the configured SchedulingGateNameOverride or the default scheduling gate name if it
+// is not set.
+func (s *ClusterPodPlacementConfigSpec) GetSchedulingGateName() string {
+	if s.SchedulingGateNameOverride == "" {
+		return utils.SchedulingGateName
+	}
+	return s.SchedulingGateNameOverride
+}
+
+// GetGlobalImagePullSecretNamespacedName returns the namespace and the name of the configured
+// GlobalImagePullSecretRef or of the cluster global pull secret if it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetGlobalImagePullSecretNamespacedName() (namespace, name string) {
+	if s.GlobalImagePullSecretRef == nil {
+		return utils.DefaultGlobalPullSecretNamespace, utils.DefaultGlobalPullSecretName
+	}
+	return s.GlobalImagePullSecretRef.Namespace, s.GlobalImagePullSecretRef.Name
+}
+
+// GetImageInspectionErrorRateThreshold returns the configured ImageInspectionErrorRateThreshold or its default value
+// if it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetImageInspectionErrorRateThreshold() int32 {
+	if s.ImageInspectionErrorRateThreshold == nil {
+		return DefaultImageInspectionErrorRateThreshold
+	}
+	return *s.ImageInspectionErrorRateThreshold
+}
+
+// GetInspectionTimeoutFallback returns the configured InspectionTimeoutFallback or InspectionTimeoutFallbackBlock if
+// it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetInspectionTimeoutFallback() InspectionTimeoutFallback {
+	if s.InspectionTimeoutFallback == "" {
+		return InspectionTimeoutFallbackBlock
+	}
+	return s.InspectionTimeoutFallback
+}
+
+// GetEnableArchitectureLabels returns the configured EnableArchitectureLabels or true if it is not set.
+func (s *ClusterPodPlacementConfigSpec) GetEnableArchitectureLabels() bool {
+	if s.EnableArchitectureLabels == nil {
+		return true
+	}
+	return *s.EnableArchitectureLabels
 }
 
 // ClusterPodPlacementConfigStatus defines the observed state of ClusterPodPlacementConfig
@@ -194,6 +847,33 @@ func (s *ClusterPodPlacementConfigStatus) buildConditions() {
 	})
 }
 
+// BuildHealthConditions sets the health conditions in the ClusterPodPlacementConfig object:
+//   - WebhookReady: if the pod placement webhook is available and its mutating webhook configuration exists
+//   - CacheHealthy: the status, reason and message computed by the operator from the image inspections reported
+//     by the pod placement controller
+func (s *ClusterPodPlacementConfigStatus) BuildHealthConditions(webhookReady bool,
+	cacheHealthStatus metav1.ConditionStatus, cacheHealthReason, cacheHealthMessage string) {
+	if s.Conditions == nil {
+		s.Conditions = []metav1.Condition{}
+	}
+	webhookReadyReason := ReadyReason
+	if !webhookReady {
+		webhookReadyReason = DegradedReason
+	}
+	v1helpers.SetCondition(&s.Conditions, metav1.Condition{
+		Type:    WebhookReadyType,
+		Status:  conditionFromBool(webhookReady),
+		Reason:  webhookReadyReason,
+		Message: fmt.Sprintf(WebhookReadyMsg, notFromBool(webhookReady)),
+	})
+	v1helpers.SetCondition(&s.Conditions, metav1.Condition{
+		Type:    CacheHealthyType,
+		Status:  cacheHealthStatus,
+		Reason:  cacheHealthReason,
+		Message: cacheHealthMessage,
+	})
+}
+
 // ClusterPodPlacementConfig defines the configuration for the architecture aware pod placement operand.
 // Users can only deploy a single object named "cluster".
 // Creating the object enables the operand.
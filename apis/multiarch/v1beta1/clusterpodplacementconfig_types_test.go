@@ -247,3 +247,70 @@ func TestClusterPodPlacementConfigStatus_Build(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterPodPlacementConfigSpec_GetCacheJitterFraction(t *testing.T) {
+	tests := []struct {
+		name                string
+		cacheJitterFraction string
+		want                float64
+	}{
+		{name: "not set", want: DefaultCacheJitterFraction},
+		{name: "valid fraction", cacheJitterFraction: "0.35", want: 0.35},
+		{name: "jitter disabled", cacheJitterFraction: "0", want: 0},
+		{name: "fraction not lower than 1", cacheJitterFraction: "1", want: DefaultCacheJitterFraction},
+		{name: "not a number", cacheJitterFraction: "foo", want: DefaultCacheJitterFraction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ClusterPodPlacementConfigSpec{CacheJitterFraction: tt.cacheJitterFraction}
+			if got := spec.GetCacheJitterFraction(); got != tt.want {
+				t.Errorf("GetCacheJitterFraction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterPodPlacementConfigSpec_GetLogSamplingRate(t *testing.T) {
+	tests := []struct {
+		name            string
+		logSamplingRate string
+		want            float64
+	}{
+		{name: "not set", want: DefaultLogSamplingRate},
+		{name: "valid rate", logSamplingRate: "0.01", want: 0.01},
+		{name: "sampling out all the logs", logSamplingRate: "0", want: 0},
+		{name: "sampling in all the logs", logSamplingRate: "1.0", want: 1},
+		{name: "rate greater than 1", logSamplingRate: "1.5", want: DefaultLogSamplingRate},
+		{name: "negative rate", logSamplingRate: "-0.5", want: DefaultLogSamplingRate},
+		{name: "not a number", logSamplingRate: "foo", want: DefaultLogSamplingRate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ClusterPodPlacementConfigSpec{LogSamplingRate: tt.logSamplingRate}
+			if got := spec.GetLogSamplingRate(); got != tt.want {
+				t.Errorf("GetLogSamplingRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterPodPlacementConfigSpec_GetEnableArchitectureLabels(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name                     string
+		enableArchitectureLabels *bool
+		want                     bool
+	}{
+		{name: "not set", want: true},
+		{name: "enabled", enableArchitectureLabels: &enabled, want: true},
+		{name: "disabled", enableArchitectureLabels: &disabled, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ClusterPodPlacementConfigSpec{EnableArchitectureLabels: tt.enableArchitectureLabels}
+			if got := spec.GetEnableArchitectureLabels(); got != tt.want {
+				t.Errorf("GetEnableArchitectureLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,10 +19,20 @@ package v1beta1
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 )
 
 // +kubebuilder:webhook:path=/validate-multiarch-openshift-io-v1beta1-clusterpodplacementconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=multiarch.openshift.io,resources=clusterpodplacementconfigs,verbs=create;update,versions=v1beta1,name=validate-clusterpodplacementconfig.multiarch.openshift.io,admissionReviewVersions=v1
@@ -34,6 +44,11 @@ func (c *ClusterPodPlacementConfig) SetupWebhookWithManager(mgr ctrl.Manager) er
 		Complete()
 }
 
+// maxOwnerKindLength and ownerKindRegexp validate the kinds of the ExcludedOwnerReferenceKinds, as the CRD schema does.
+const maxOwnerKindLength = 63
+
+var ownerKindRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
 type ClusterPodPlacementConfigValidator struct {
 }
 
@@ -42,7 +57,22 @@ func (v *ClusterPodPlacementConfigValidator) ValidateCreate(ctx context.Context,
 }
 
 func (v *ClusterPodPlacementConfigValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
-	return v.validate(newObj)
+	warnings, err = v.validate(newObj)
+	oldCPPC, oldOK := oldObj.(*ClusterPodPlacementConfig)
+	newCPPC, newOK := newObj.(*ClusterPodPlacementConfig)
+	if err == nil && oldOK && newOK && oldCPPC.Spec.GetSchedulingGateName() != newCPPC.Spec.GetSchedulingGateName() {
+		warnings = append(warnings, fmt.Sprintf("the pods gated with the %q scheduling gate will not be ungated by "+
+			"the pod placement operand", oldCPPC.Spec.GetSchedulingGateName()))
+	}
+	if err == nil && oldOK && newOK {
+		for _, name := range oldCPPC.Spec.ManagedSchedulingGates {
+			if !slices.Contains(newCPPC.Spec.ManagedSchedulingGates, name) {
+				warnings = append(warnings, fmt.Sprintf("the pods gated with the %q scheduling gate will not be "+
+					"ungated by the pod placement operand", name))
+			}
+		}
+	}
+	return warnings, err
 }
 
 func (v *ClusterPodPlacementConfigValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
@@ -54,6 +84,91 @@ func (v *ClusterPodPlacementConfigValidator) validate(obj runtime.Object) (warni
 	if !ok {
 		return nil, errors.New("not a ClusterPodPlacementConfig")
 	}
+	if name := cppc.Spec.SchedulingGateNameOverride; name != "" {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid .spec.schedulingGateNameOverride %q: %s", name, strings.Join(errs, "; "))
+		}
+	}
+	for _, name := range cppc.Spec.ManagedSchedulingGates {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid scheduling gate %q in the .spec.managedSchedulingGates: %s", name,
+				strings.Join(errs, "; "))
+		}
+		if name == cppc.Spec.GetSchedulingGateName() {
+			return nil, fmt.Errorf("the .spec.managedSchedulingGates must not include the %q scheduling gate "+
+				"of the pod placement operand", name)
+		}
+	}
+	if ref := cppc.Spec.GlobalImagePullSecretRef; ref != nil && (ref.Namespace == "" || ref.Name == "") {
+		return nil, errors.New("the .spec.globalImagePullSecretRef must set both the namespace and the name of the Secret")
+	}
+	if ref := cppc.Spec.CustomCABundleSecretRef; ref != nil {
+		if ref.Name == "" {
+			return nil, errors.New("the .spec.customCABundleSecretRef must set the name of the Secret")
+		}
+		if ref.Namespace != "" && ref.Namespace != utils.Namespace() {
+			return nil, fmt.Errorf("the Secret of the .spec.customCABundleSecretRef must be in the %q namespace "+
+				"of the operator", utils.Namespace())
+		}
+	}
+	if cppc.Spec.InspectionTimeoutFallback == InspectionTimeoutFallbackAllowConfigured &&
+		len(cppc.Spec.FallbackArchitectures) == 0 {
+		return nil, errors.New("the .spec.fallbackArchitectures must not be empty when the " +
+			".spec.inspectionTimeoutFallback is allow-configured")
+	}
+	if matchers := cppc.Spec.VirtualNodeMatchers; matchers != nil {
+		for _, pattern := range append(slices.Clone(matchers.Namespaces), matchers.NodePools...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q in the .spec.virtualNodeMatchers: %w", pattern, err)
+			}
+		}
+	}
+	excludedOwnerRefs := map[ExcludedOwnerRef]struct{}{}
+	for _, ref := range cppc.Spec.ExcludedOwnerReferenceKinds {
+		if len(ref.Kind) > maxOwnerKindLength || !ownerKindRegexp.MatchString(ref.Kind) {
+			return nil, fmt.Errorf("invalid kind %q in the .spec.excludedOwnerReferenceKinds: it must be an "+
+				"alphanumeric string of at most %d characters starting with a letter", ref.Kind, maxOwnerKindLength)
+		}
+		if ref.APIGroup != "" {
+			if errs := validation.IsDNS1123Subdomain(ref.APIGroup); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid API group %q in the .spec.excludedOwnerReferenceKinds: %s",
+					ref.APIGroup, strings.Join(errs, "; "))
+			}
+		}
+		key := ExcludedOwnerRef{Kind: ref.Kind, APIGroup: ref.APIGroup}
+		if _, ok := excludedOwnerRefs[key]; ok {
+			return nil, fmt.Errorf("duplicate kind %q of the API group %q in the .spec.excludedOwnerReferenceKinds",
+				ref.Kind, ref.APIGroup)
+		}
+		excludedOwnerRefs[key] = struct{}{}
+	}
+	for _, pattern := range cppc.Spec.UniversalImages {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in the .spec.universalImages: %w", pattern, err)
+		}
+	}
+	for _, registry := range slices.Sorted(maps.Keys(cppc.Spec.RegistryTimeouts)) {
+		if registry == "" || strings.ContainsAny(registry, ",=/ ") {
+			return nil, fmt.Errorf("invalid registry %q in the .spec.registryTimeouts: it must be a hostname, "+
+				"optionally followed by a port", registry)
+		}
+		if cppc.Spec.RegistryTimeouts[registry].Duration <= 0 {
+			return nil, fmt.Errorf("the timeout of the registry %q in the .spec.registryTimeouts must be positive", registry)
+		}
+	}
+	if proxy := cppc.Spec.Proxy; proxy != nil {
+		for _, field := range []struct{ name, value string }{
+			{"httpProxy", proxy.HTTPProxy},
+			{"httpsProxy", proxy.HTTPSProxy},
+		} {
+			if field.value == "" {
+				continue
+			}
+			if u, err := url.Parse(field.value); err != nil || u.Scheme == "" || u.Host == "" {
+				return nil, fmt.Errorf("invalid .spec.proxy.%s %q: it must be an absolute URL", field.name, field.value)
+			}
+		}
+	}
 	if cppc.Spec.Plugins == nil || cppc.Spec.Plugins.NodeAffinityScoring == nil {
 		return nil, nil
 	}
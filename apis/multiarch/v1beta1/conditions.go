@@ -10,6 +10,8 @@ const (
 	DegradedType                             = "Degraded"
 	ProgressingType                          = "Progressing"
 	DeprovisioningType                       = "Deprovisioning"
+	CacheHealthyType                         = "CacheHealthy"
+	WebhookReadyType                         = "WebhookReady"
 
 	MutatingWebhookConfigurationReadyMsg = "The mutating webhook configuration is %sready."
 	PodPlacementControllerRolledOutMsg   = "The pod placement controller is %sfully rolled out."
@@ -21,4 +23,11 @@ const (
 	PendingDeprovisioningMsg             = "Some pods may still have the " + utils.SchedulingGateName +
 		"scheduling gate. The pod placement controller is updating them and will terminate."
 	AllComponentsReady = "AllComponentsReady"
+
+	// The reasons of the CacheHealthy and WebhookReady conditions
+	ReadyReason    = "Ready"
+	DegradedReason = "Degraded"
+	UnknownReason  = "Unknown"
+
+	WebhookReadyMsg = "The pod placement webhook is %sserving the admission requests."
 )
@@ -22,6 +22,7 @@ package v1beta1
 
 import (
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common/plugins"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -98,6 +99,98 @@ func (in *ClusterPodPlacementConfigSpec) DeepCopyInto(out *ClusterPodPlacementCo
 		*out = new(plugins.Plugins)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DelayedEventBackoff != nil {
+		in, out := &in.DelayedEventBackoff, &out.DelayedEventBackoff
+		*out = new(DelayedEventBackoff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageInspectionRetryPolicy != nil {
+		in, out := &in.ImageInspectionRetryPolicy, &out.ImageInspectionRetryPolicy
+		*out = new(ImageInspectionRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageInspectionCircuitBreaker != nil {
+		in, out := &in.ImageInspectionCircuitBreaker, &out.ImageInspectionCircuitBreaker
+		*out = new(ImageInspectionCircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnableArchitectureLabels != nil {
+		in, out := &in.EnableArchitectureLabels, &out.EnableArchitectureLabels
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxGateDuration != nil {
+		in, out := &in.MaxGateDuration, &out.MaxGateDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PodExclusionLabelSelector != nil {
+		in, out := &in.PodExclusionLabelSelector, &out.PodExclusionLabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ImageInspectionProxy)
+		**out = **in
+	}
+	if in.GlobalImagePullSecretRef != nil {
+		in, out := &in.GlobalImagePullSecretRef, &out.GlobalImagePullSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.CustomCABundleSecretRef != nil {
+		in, out := &in.CustomCABundleSecretRef, &out.CustomCABundleSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.ImageInspectionErrorRateThreshold != nil {
+		in, out := &in.ImageInspectionErrorRateThreshold, &out.ImageInspectionErrorRateThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FallbackArchitectures != nil {
+		in, out := &in.FallbackArchitectures, &out.FallbackArchitectures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VirtualNodeMatchers != nil {
+		in, out := &in.VirtualNodeMatchers, &out.VirtualNodeMatchers
+		*out = new(VirtualNodeMatchers)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrustedOwnerKinds != nil {
+		in, out := &in.TrustedOwnerKinds, &out.TrustedOwnerKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedSchedulingGates != nil {
+		in, out := &in.ManagedSchedulingGates, &out.ManagedSchedulingGates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedOwnerKinds != nil {
+		in, out := &in.ExcludedOwnerKinds, &out.ExcludedOwnerKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedOwnerReferenceKinds != nil {
+		in, out := &in.ExcludedOwnerReferenceKinds, &out.ExcludedOwnerReferenceKinds
+		*out = make([]ExcludedOwnerRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.UniversalImages != nil {
+		in, out := &in.UniversalImages, &out.UniversalImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RegistryTimeouts != nil {
+		in, out := &in.RegistryTimeouts, &out.RegistryTimeouts
+		*out = make(map[string]v1.Duration, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodPlacementConfigSpec.
@@ -146,3 +239,138 @@ func (in *ClusterPodPlacementConfigValidator) DeepCopy() *ClusterPodPlacementCon
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DelayedEventBackoff) DeepCopyInto(out *DelayedEventBackoff) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DelayedEventBackoff.
+func (in *DelayedEventBackoff) DeepCopy() *DelayedEventBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(DelayedEventBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExcludedOwnerRef) DeepCopyInto(out *ExcludedOwnerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExcludedOwnerRef.
+func (in *ExcludedOwnerRef) DeepCopy() *ExcludedOwnerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ExcludedOwnerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageInspectionCircuitBreaker) DeepCopyInto(out *ImageInspectionCircuitBreaker) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ResetTimeout != nil {
+		in, out := &in.ResetTimeout, &out.ResetTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageInspectionCircuitBreaker.
+func (in *ImageInspectionCircuitBreaker) DeepCopy() *ImageInspectionCircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageInspectionCircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageInspectionProxy) DeepCopyInto(out *ImageInspectionProxy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageInspectionProxy.
+func (in *ImageInspectionProxy) DeepCopy() *ImageInspectionProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageInspectionProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageInspectionRetryPolicy) DeepCopyInto(out *ImageInspectionRetryPolicy) {
+	*out = *in
+	if in.InitialInterval != nil {
+		in, out := &in.InitialInterval, &out.InitialInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxInterval != nil {
+		in, out := &in.MaxInterval, &out.MaxInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageInspectionRetryPolicy.
+func (in *ImageInspectionRetryPolicy) DeepCopy() *ImageInspectionRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageInspectionRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNodeMatchers) DeepCopyInto(out *VirtualNodeMatchers) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNodeMatchers.
+func (in *VirtualNodeMatchers) DeepCopy() *VirtualNodeMatchers {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNodeMatchers)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-multiarch is a kubectl plugin listing the pods that are waiting for the removal of the scheduling gate
+// of the pod placement operand. Installed in the PATH, it is run as `kubectl multiarch`.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func main() {
+	var namespace string
+	flag.StringVar(&namespace, "namespace", "", "The namespace of the pods to list. All namespaces if empty.")
+	flag.Parse()
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load the kubeconfig: %v\n", err)
+		os.Exit(1)
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create the client: %v\n", err)
+		os.Exit(1)
+	}
+	pods, err := listGatedPods(context.Background(), clientSet, namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to list the gated pods: %v\n", err)
+		os.Exit(1)
+	}
+	if err := printGatedPods(os.Stdout, pods, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to print the gated pods: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// listGatedPods returns the pods of the namespace, or of all the namespaces if empty, that are labelled as waiting
+// for the removal of the scheduling gate, sorted by namespace and name.
+func listGatedPods(ctx context.Context, clientSet kubernetes.Interface, namespace string) ([]corev1.Pod, error) {
+	podList, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", utils.SchedulingGateLabel, utils.SchedulingGateLabelValueGated),
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// printGatedPods writes the table of the gated pods to w. The age of the pods is computed relative to now.
+func printGatedPods(w io.Writer, pods []corev1.Pod, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tNAME\tAGE\tIMAGES\tARCHITECTURES")
+	for _, pod := range pods {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", pod.Namespace, pod.Name,
+			humanDuration(now.Sub(pod.CreationTimestamp.Time)),
+			orNone(podImages(&pod)), orNone(detectedArchitectures(&pod)))
+	}
+	return tw.Flush()
+}
+
+// podImages returns the comma-separated images of the init and regular containers of the pod, without duplicates
+// and in the order they appear in the pod spec.
+func podImages(pod *corev1.Pod) string {
+	seen := sets.New[string]()
+	images := make([]string, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if seen.Has(container.Image) {
+				continue
+			}
+			seen.Insert(container.Image)
+			images = append(images, container.Image)
+		}
+	}
+	return strings.Join(images, ",")
+}
+
+// detectedArchitectures returns the comma-separated sorted architectures the pod placement operand labelled the
+// pod with. The labels are only set once the images of the pod are inspected.
+func detectedArchitectures(pod *corev1.Pod) string {
+	architectures := sets.New[string]()
+	for _, architecture := range append(sets.List(utils.AllSupportedArchitecturesSet()),
+		utils.ArchitectureArmV6, utils.ArchitectureArmV7) {
+		if _, ok := pod.Labels[utils.ArchLabelValue(architecture)]; ok {
+			architectures.Insert(architecture)
+		}
+	}
+	if _, ok := pod.Labels[utils.WasmArchLabel]; ok {
+		architectures.Insert(utils.ArchitectureWasm32)
+	}
+	return strings.Join(sets.List(architectures), ",")
+}
+
+// humanDuration returns the duration in the short format of the AGE column of kubectl, e.g., 42s, 5m, 3h or 2d.
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "<invalid>"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func gatedPod(namespace, name string, created time.Time, labels map[string]string, images ...string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{utils.SchedulingGateLabel: utils.SchedulingGateLabelValueGated},
+		},
+	}
+	for k, v := range labels {
+		pod.Labels[k] = v
+	}
+	for _, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Image: image})
+	}
+	return pod
+}
+
+func TestPrintGatedPods(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	initContainerPod := gatedPod("ns1", "with-init", now.Add(-3*time.Hour), nil, "quay.io/app:v1")
+	initContainerPod.Spec.InitContainers = []corev1.Container{{Image: "quay.io/init:v1"}, {Image: "quay.io/app:v1"}}
+	buf := &bytes.Buffer{}
+	g.Expect(printGatedPods(buf, []corev1.Pod{
+		gatedPod("ns1", "multi-arch", now.Add(-42*time.Second), map[string]string{
+			utils.MultiArchLabel:                                  "",
+			utils.ArchLabelValue(utils.ArchitectureArm64):         "",
+			utils.ArchLabelValue(utils.ArchitectureAmd64):         "",
+			utils.ArchLabelValue(utils.ArchitectureArmV7):         "",
+			utils.WasmArchLabel:                                   "",
+			utils.ArchLabelValue(utils.ArchitecturePpc64le) + "x": "",
+		}, "quay.io/multi:v1", "quay.io/sidecar:v1"),
+		gatedPod("ns2", "not-inspected", now.Add(-72*time.Hour), nil, "quay.io/single:v1"),
+		initContainerPod,
+	}, now)).To(Succeed())
+	g.Expect(buf.String()).To(Equal(
+		"NAMESPACE   NAME            AGE   IMAGES                                ARCHITECTURES\n" +
+			"ns1         multi-arch      42s   quay.io/multi:v1,quay.io/sidecar:v1   amd64,arm/v7,arm64,wasm32\n" +
+			"ns2         not-inspected   3d    quay.io/single:v1                     <none>\n" +
+			"ns1         with-init       3h    quay.io/init:v1,quay.io/app:v1        <none>\n"))
+}
+
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{-time.Second, "<invalid>"},
+		{59 * time.Second, "59s"},
+		{time.Minute, "1m"},
+		{59 * time.Minute, "59m"},
+		{47 * time.Hour, "47h"},
+		{48 * time.Hour, "2d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(humanDuration(tt.duration)).To(Equal(tt.want))
+		})
+	}
+}
+
+// TestListGatedPods runs the listing against a fake Kubernetes API server.
+func TestListGatedPods(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		wantPath  string
+	}{
+		{
+			name:     "all namespaces",
+			wantPath: "/api/v1/pods",
+		},
+		{
+			name:      "single namespace",
+			namespace: "ns1",
+			wantPath:  "/api/v1/namespaces/ns1/pods",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			now := time.Now()
+			var gotPath, gotSelector string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotSelector = r.URL.Query().Get("labelSelector")
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(&corev1.PodList{
+					TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"},
+					Items: []corev1.Pod{
+						gatedPod("ns2", "b", now, nil, "quay.io/b:v1"),
+						gatedPod("ns1", "c", now, nil, "quay.io/c:v1"),
+						gatedPod("ns1", "a", now, nil, "quay.io/a:v1"),
+					},
+				})
+			}))
+			defer server.Close()
+			clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			pods, err := listGatedPods(context.Background(), clientSet, tt.namespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gotPath).To(Equal(tt.wantPath))
+			g.Expect(gotSelector).To(Equal(utils.SchedulingGateLabel + "=" + utils.SchedulingGateLabelValueGated))
+			names := make([]string, 0, len(pods))
+			for _, pod := range pods {
+				names = append(names, pod.Namespace+"/"+pod.Name)
+			}
+			g.Expect(names).To(Equal([]string{"ns1/a", "ns1/c", "ns2/b"}))
+		})
+	}
+}
+
+func TestListGatedPods_Error(t *testing.T) {
+	g := NewGomegaWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = listGatedPods(context.Background(), clientSet, "")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -19,14 +19,20 @@ package operator
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	errorutils "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -34,14 +40,18 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
+	ocpconfigv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/library-go/pkg/operator/events"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common"
 	multiarchv1beta1 "github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement"
 	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 )
 
@@ -65,11 +75,22 @@ const (
 	clusterPodPlacementConfigNotReady   = "cluster pod placement config is not ready yet. re-queueing"
 )
 
+const (
+	// healthConditionsResyncPeriod is the period of the refresh of the health conditions, which depend on the
+	// reports of the operands rather than on the objects the reconciler watches.
+	healthConditionsResyncPeriod = time.Minute
+	// imageInspectionHealthStaleness is the age after which the image inspection report of the pod placement
+	// controller is not considered anymore.
+	imageInspectionHealthStaleness = 5 * time.Minute
+)
+
 //+kubebuilder:rbac:groups=multiarch.openshift.io,resources=clusterpodplacementconfigs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=multiarch.openshift.io,resources=clusterpodplacementconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=multiarch.openshift.io,resources=clusterpodplacementconfigs/finalizers,verbs=update
 //+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;update;patch;create;delete;list;watch
 //+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations/status,verbs=get
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;update;patch;create;delete;list;watch
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations/status,verbs=get
 
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch;create;delete
@@ -98,6 +119,8 @@ const (
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;update;patch;create;delete
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;update;patch;create;delete
 
+//+kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
+
 // Reconcile reconciles the ClusterPodPlacementConfig object against the actual cluster state, and then
 // perform operations to make the cluster state reflect the state specified by
 // the user.
@@ -128,7 +151,10 @@ func (r *ClusterPodPlacementConfigReconciler) Reconcile(ctx context.Context, req
 		// Only execute deletion if the object is being deleted and the finalizer is present
 		return ctrl.Result{}, r.handleDelete(ctx, clusterPodPlacementConfig)
 	}
-	return ctrl.Result{}, r.reconcile(ctx, clusterPodPlacementConfig)
+	if err = r.reconcile(ctx, clusterPodPlacementConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: healthConditionsResyncPeriod}, nil
 }
 
 func (r *ClusterPodPlacementConfigReconciler) ensureNamespaceLabels(ctx context.Context) error {
@@ -191,14 +217,70 @@ func (r *ClusterPodPlacementConfigReconciler) dependentsStatusToClusterPodPlacem
 		log.Error(err, "Unable to get the mutating webhook configuration")
 		return err
 	}
+	// err == nil means the MutatingWebhookConfiguration is available
+	mutatingWebhookConfigurationAvailable := err == nil
+	imageInspectionHealth, err := r.ClientSet.CoreV1().ConfigMaps(utils.Namespace()).Get(ctx, utils.ImageInspectionHealthConfigMapName, metav1.GetOptions{})
+	if client.IgnoreNotFound(err) != nil {
+		log.Error(err, "Unable to get the image inspection health report")
+		return err
+	}
+	if err != nil {
+		imageInspectionHealth = nil
+	}
 	config.Status.Build(
 		isDeploymentAvailable(podPlacementController), isDeploymentAvailable(podPlacementWebhook),
 		isDeploymentUpToDate(podPlacementController), isDeploymentUpToDate(podPlacementWebhook),
-		// err == nil means the MutatingWebhookConfiguration is available
-		err == nil, !config.DeletionTimestamp.IsZero())
+		mutatingWebhookConfigurationAvailable, !config.DeletionTimestamp.IsZero())
+	cacheHealthStatus, cacheHealthReason, cacheHealthMessage := imageInspectionCacheHealth(imageInspectionHealth,
+		config.Spec.GetImageInspectionErrorRateThreshold(), time.Now())
+	config.Status.BuildHealthConditions(isDeploymentAvailable(podPlacementWebhook) && mutatingWebhookConfigurationAvailable,
+		cacheHealthStatus, cacheHealthReason, cacheHealthMessage)
 	return nil
 }
 
+// imageInspectionCacheHealth returns the status, reason and message of the CacheHealthy condition, given the
+// ConfigMap the pod placement controller reports the image inspections of the last rolling window in. The cache is
+// degraded when the percentage of failed inspections exceeds the threshold. The health is unknown when the report
+// is missing, invalid or stale, e.g., because the pod placement controller is not running.
+func imageInspectionCacheHealth(report *corev1.ConfigMap, threshold int32, now time.Time) (metav1.ConditionStatus, string, string) {
+	if report == nil {
+		return metav1.ConditionUnknown, multiarchv1beta1.UnknownReason,
+			"The pod placement controller has not reported the image inspections yet."
+	}
+	updateTime, err := time.Parse(time.RFC3339, report.Data[utils.ImageInspectionHealthUpdateTimeKey])
+	if err != nil {
+		return metav1.ConditionUnknown, multiarchv1beta1.UnknownReason,
+			"The image inspections report of the pod placement controller has an invalid update time."
+	}
+	if age := now.Sub(updateTime); age > imageInspectionHealthStaleness {
+		return metav1.ConditionUnknown, multiarchv1beta1.UnknownReason,
+			fmt.Sprintf("The image inspections report of the pod placement controller was last updated %s ago.",
+				age.Round(time.Second))
+	}
+	inspections, err := strconv.ParseUint(report.Data[utils.ImageInspectionHealthInspectionsKey], 10, 64)
+	if err != nil {
+		return metav1.ConditionUnknown, multiarchv1beta1.UnknownReason,
+			"The image inspections report of the pod placement controller has an invalid number of inspections."
+	}
+	failures, err := strconv.ParseUint(report.Data[utils.ImageInspectionHealthFailuresKey], 10, 64)
+	if err != nil {
+		return metav1.ConditionUnknown, multiarchv1beta1.UnknownReason,
+			"The image inspections report of the pod placement controller has an invalid number of failures."
+	}
+	window := report.Data[utils.ImageInspectionHealthWindowKey]
+	if inspections == 0 {
+		return metav1.ConditionTrue, multiarchv1beta1.ReadyReason,
+			fmt.Sprintf("No images were inspected in the last %s.", window)
+	}
+	errorRate := float64(failures) * 100 / float64(inspections)
+	message := fmt.Sprintf("%d of the %d image inspections of the last %s failed (%.1f%%, threshold %d%%).",
+		failures, inspections, window, errorRate, threshold)
+	if errorRate > float64(threshold) {
+		return metav1.ConditionFalse, multiarchv1beta1.DegradedReason, message
+	}
+	return metav1.ConditionTrue, multiarchv1beta1.ReadyReason, message
+}
+
 // handleDelete handles the deletion of the PodPlacement operand's resources.
 func (r *ClusterPodPlacementConfigReconciler) handleDelete(ctx context.Context,
 	clusterPodPlacementConfig *multiarchv1beta1.ClusterPodPlacementConfig) error {
@@ -213,6 +295,22 @@ func (r *ClusterPodPlacementConfigReconciler) handleDelete(ctx context.Context,
 			NamespacedTypedClient: r.ClientSet.AdmissionregistrationV1().MutatingWebhookConfigurations(),
 			ObjName:               utils.PodMutatingWebhookConfigurationName,
 		},
+		{
+			NamespacedTypedClient: r.ClientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations(),
+			ObjName:               utils.PodValidatingWebhookConfigurationName,
+		},
+		{
+			NamespacedTypedClient: r.ClientSet.CoreV1().ConfigMaps(utils.Namespace()),
+			ObjName:               utils.ImageInspectionCacheConfigMapName,
+		},
+		{
+			NamespacedTypedClient: r.ClientSet.CoreV1().ConfigMaps(utils.Namespace()),
+			ObjName:               utils.ImageInspectionCacheGenerationConfigMapName,
+		},
+		{
+			NamespacedTypedClient: r.ClientSet.CoreV1().ConfigMaps(utils.Namespace()),
+			ObjName:               utils.ImageInspectionHealthConfigMapName,
+		},
 		{
 			NamespacedTypedClient: r.ClientSet.CoreV1().Services(utils.Namespace()),
 			ObjName:               utils.PodPlacementWebhookName,
@@ -264,7 +362,7 @@ func (r *ClusterPodPlacementConfigReconciler) handleDelete(ctx context.Context,
 		for _, pod := range pods.Items {
 			for _, sg := range pod.Spec.SchedulingGates {
 				log.V(2).Info("Pod has scheduling gate", "pod", pod.Name, "gate", sg.Name)
-				if sg.Name == utils.SchedulingGateName {
+				if sg.Name == clusterPodPlacementConfig.Spec.GetSchedulingGateName() {
 					log.Info("Found pod with the pod placement scheduling gate", "pod", pod.Name)
 					found = true
 				}
@@ -275,8 +373,15 @@ func (r *ClusterPodPlacementConfigReconciler) handleDelete(ctx context.Context,
 		}
 	}
 
-	// The pods have been ungated and no other errors occurred, so we can remove the finalizer
 	log.Info("Pods have been ungated")
+	// The labels set by the operator are not useful anymore and would be left on the pods indefinitely
+	log.Info("Removing the operator-managed labels from the pods")
+	if err = podplacement.NewOperatorManagedLabelsCleaner(r.ClientSet).Cleanup(ctx); err != nil {
+		log.Error(err, "Unable to remove the operator-managed labels from the pods")
+		return err
+	}
+
+	// The pods have been ungated and cleaned up and no other errors occurred, so we can remove the finalizer
 	log = log.WithValues("finalizer", utils.PodPlacementFinalizerName)
 	ppcDeployment := &appsv1.Deployment{}
 	err = r.Get(ctx, client.ObjectKey{
@@ -367,6 +472,16 @@ func (r *ClusterPodPlacementConfigReconciler) reconcile(ctx context.Context, clu
 		log.Error(err, "Unable to ensure namespace labels")
 		return errorutils.NewAggregate([]error{err, r.updateStatus(ctx, clusterPodPlacementConfig)})
 	}
+	clusterProxy, err := r.getClusterProxy(ctx)
+	if err != nil {
+		log.Error(err, "Unable to get the cluster-wide proxy")
+		return errorutils.NewAggregate([]error{err, r.updateStatus(ctx, clusterPodPlacementConfig)})
+	}
+	customCABundle, err := r.getCustomCABundle(ctx, clusterPodPlacementConfig)
+	if err != nil {
+		log.Error(err, "Unable to get the custom CA bundle")
+		return errorutils.NewAggregate([]error{err, r.updateStatus(ctx, clusterPodPlacementConfig)})
+	}
 	objects := []client.Object{
 		// The finalizer will not affect the reconciliation of ReplicaSets and Pods
 		// when updates to the ClusterPodPlacementConfig are made.
@@ -406,8 +521,8 @@ func (r *ClusterPodPlacementConfigReconciler) reconcile(ctx context.Context, clu
 				Namespace: utils.Namespace(),
 			},
 		}),
-		buildControllerDeployment(clusterPodPlacementConfig),
-		buildWebhookDeployment(clusterPodPlacementConfig),
+		buildControllerDeployment(clusterPodPlacementConfig, clusterProxy, customCABundle),
+		buildWebhookDeployment(clusterPodPlacementConfig, clusterProxy, customCABundle),
 	}
 	// We ensure the MutatingWebHookConfiguration is created and present only if the operand is ready to serve the admission request and add/remove the scheduling gate.
 	shouldEnsureMWC := clusterPodPlacementConfig.Status.CanDeployMutatingWebhook()
@@ -419,6 +534,15 @@ func (r *ClusterPodPlacementConfigReconciler) reconcile(ctx context.Context, clu
 		log.Info("Deleting the mutating webhook configuration as the operand is not ready to serve the admission request or remove the scheduling gate")
 		_ = r.ClientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().Delete(ctx, utils.PodMutatingWebhookConfigurationName, metav1.DeleteOptions{})
 	}
+	// The ValidatingWebhookConfiguration is opt-in and follows the lifecycle of the MutatingWebhookConfiguration.
+	if shouldEnsureMWC && clusterPodPlacementConfig.Spec.EnforceArchitectureCompatibility {
+		objects = append(objects, buildValidatingWebhookConfiguration(clusterPodPlacementConfig))
+	} else {
+		err := r.ClientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(ctx, utils.PodValidatingWebhookConfigurationName, metav1.DeleteOptions{})
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Unable to delete the validating webhook configuration")
+		}
+	}
 
 	// If the servicemonitors.monitoring.coreos.com CRD is available, we create the ServiceMonitor objects
 	if utils.IsResourceAvailable(ctx, r.DynamicClient, monitoringv1.SchemeGroupVersion.WithResource("servicemonitors")) {
@@ -510,6 +634,63 @@ func isDeploymentUpToDate(deployment *appsv1.Deployment) bool {
 		deployment.Status.ObservedGeneration == deployment.Generation
 }
 
+// getClusterProxy returns the OpenShift cluster-wide proxy, or nil when the cluster does not serve the
+// config.openshift.io/v1 Proxy API or the cluster Proxy does not exist.
+func (r *ClusterPodPlacementConfigReconciler) getClusterProxy(ctx context.Context) (*ocpconfigv1.Proxy, error) {
+	proxy := &ocpconfigv1.Proxy{}
+	err := r.Get(ctx, client.ObjectKey{Name: common.SingletonResourceObjectName}, proxy)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return proxy, nil
+}
+
+// clusterProxyToClusterPodPlacementConfig maps the events of the cluster-wide proxy to the ClusterPodPlacementConfig.
+func clusterProxyToClusterPodPlacementConfig(_ context.Context, obj client.Object) []ctrl.Request {
+	if obj.GetName() != common.SingletonResourceObjectName {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: common.SingletonResourceObjectName}}}
+}
+
+// getCustomCABundle returns the PEM bundle of the Secret referenced by the CustomCABundleSecretRef of the
+// ClusterPodPlacementConfig, or nil if it is not set. The Secret is in the namespace of the operator.
+func (r *ClusterPodPlacementConfigReconciler) getCustomCABundle(ctx context.Context,
+	clusterPodPlacementConfig *multiarchv1beta1.ClusterPodPlacementConfig) ([]byte, error) {
+	ref := clusterPodPlacementConfig.Spec.CustomCABundleSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+	secret, err := r.ClientSet.CoreV1().Secrets(utils.Namespace()).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	bundle, ok := secret.Data[multiarchv1beta1.CustomCABundleKey]
+	if !ok {
+		return nil, fmt.Errorf("the Secret %s/%s has no %s key", utils.Namespace(), ref.Name,
+			multiarchv1beta1.CustomCABundleKey)
+	}
+	return bundle, nil
+}
+
+// customCABundleSecretToClusterPodPlacementConfig maps the events of the Secret referenced by the
+// CustomCABundleSecretRef of the ClusterPodPlacementConfig to the latter.
+func (r *ClusterPodPlacementConfigReconciler) customCABundleSecretToClusterPodPlacementConfig(ctx context.Context,
+	obj client.Object) []ctrl.Request {
+	clusterPodPlacementConfig := &multiarchv1beta1.ClusterPodPlacementConfig{}
+	if err := r.Get(ctx, client.ObjectKey{Name: common.SingletonResourceObjectName}, clusterPodPlacementConfig); err != nil {
+		return nil
+	}
+	ref := clusterPodPlacementConfig.Spec.CustomCABundleSecretRef
+	if ref == nil || obj.GetNamespace() != utils.Namespace() || obj.GetName() != ref.Name {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: common.SingletonResourceObjectName}}}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterPodPlacementConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	c := ctrl.NewControllerManagedBy(mgr).
@@ -521,10 +702,18 @@ func (r *ClusterPodPlacementConfigReconciler) SetupWithManager(mgr ctrl.Manager)
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&corev1.ServiceAccount{}).
-		Owns(&admissionv1.MutatingWebhookConfiguration{})
+		Owns(&admissionv1.MutatingWebhookConfiguration{}).
+		Owns(&admissionv1.ValidatingWebhookConfiguration{}).
+		// Changes to the custom CA bundle are rolled out to the operands by re-building their deployments.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.customCABundleSecretToClusterPodPlacementConfig))
 	if utils.IsResourceAvailable(context.Background(), r.DynamicClient,
 		monitoringv1.SchemeGroupVersion.WithResource("servicemonitors")) {
 		c = c.Owns(&monitoringv1.ServiceMonitor{}).Owns(&monitoringv1.PrometheusRule{})
 	}
+	// Changes to the cluster-wide proxy are rolled out to the operands by re-building their deployments.
+	if _, err := mgr.GetRESTMapper().RESTMapping(ocpconfigv1.GroupVersion.WithKind("Proxy").GroupKind(),
+		ocpconfigv1.GroupVersion.Version); err == nil {
+		c = c.Watches(&ocpconfigv1.Proxy{}, handler.EnqueueRequestsFromMapFunc(clusterProxyToClusterPodPlacementConfig))
+	}
 	return c.Complete(r)
 }
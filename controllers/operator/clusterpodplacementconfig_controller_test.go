@@ -17,18 +17,26 @@ limitations under the License.
 package operator
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
+	"testing"
+	"time"
 
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	ocpconfigv1 "github.com/openshift/api/config/v1"
+
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common"
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
 	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
@@ -317,6 +325,42 @@ var _ = Describe("Controllers/ClusterPodPlacementConfig/ClusterPodPlacementConfi
 					g.Expect(mw.Webhooks[0].NamespaceSelector).To(Equal(ppc.Spec.NamespaceSelector))
 				}).Should(Succeed(), "the deployment "+utils.PodPlacementControllerName+" should be updated")
 			})
+			It("Should roll out the custom CA bundle to the operands", func() {
+				By("Creating the Secret of the custom CA bundle")
+				secret := builder.NewSecret().WithName("custom-ca-bundle").WithNameSpace(utils.Namespace()).
+					WithData(map[string][]byte{v1beta1.CustomCABundleKey: []byte("first bundle")}).Build()
+				Expect(k8sClient.Create(ctx, secret)).To(Succeed(), "failed to create the Secret")
+				DeferCleanup(func() {
+					Expect(crclient.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed())
+				})
+				By("Referencing the Secret in the ClusterPodPlacementConfig")
+				Eventually(func(g Gomega) {
+					ppc := &v1beta1.ClusterPodPlacementConfig{}
+					err := k8sClient.Get(ctx, crclient.ObjectKey{Name: common.SingletonResourceObjectName}, ppc)
+					g.Expect(err).NotTo(HaveOccurred(), "failed to get ClusterPodPlacementConfig", err)
+					ppc.Spec.CustomCABundleSecretRef = &corev1.SecretReference{Name: secret.Name}
+					err = k8sClient.Update(ctx, ppc)
+					g.Expect(err).NotTo(HaveOccurred(), "failed to update ClusterPodPlacementConfig", err)
+				}).Should(Succeed(), "the ClusterPodPlacementConfig should be updated")
+				verifyCustomCABundleHash := func(bundle string) {
+					for _, name := range []string{utils.PodPlacementControllerName, utils.PodPlacementWebhookName} {
+						Eventually(func(g Gomega) {
+							d := appsv1.Deployment{}
+							err := k8sClient.Get(ctx, crclient.ObjectKey{Name: name, Namespace: utils.Namespace()}, &d)
+							g.Expect(err).NotTo(HaveOccurred(), "failed to get deployment "+name, err)
+							g.Expect(d.Spec.Template.Annotations).To(HaveKeyWithValue(customCABundleHashAnnotation,
+								fmt.Sprintf("%x", sha256.Sum256([]byte(bundle)))))
+							g.Expect(d.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(
+								HaveField("MountPath", customCABundleMountPath)))
+						}).Should(Succeed(), "the deployment "+name+" should mount the custom CA bundle")
+					}
+				}
+				verifyCustomCABundleHash("first bundle")
+				By("Updating the custom CA bundle")
+				secret.Data[v1beta1.CustomCABundleKey] = []byte("second bundle")
+				Expect(k8sClient.Update(ctx, secret)).To(Succeed(), "failed to update the Secret")
+				verifyCustomCABundleHash("second bundle")
+			})
 			It("Should have finalizers", func() {
 				ppc := &v1beta1.ClusterPodPlacementConfig{}
 				err := k8sClient.Get(ctx, crclient.ObjectKeyFromObject(&v1beta1.ClusterPodPlacementConfig{
@@ -415,6 +459,7 @@ var _ = Describe("Controllers/ClusterPodPlacementConfig/ClusterPodPlacementConfi
 				Eventually(
 					framework.VerifyConditions(ctx, k8sClient,
 						framework.NewConditionTypeStatusTuple(v1beta1.AvailableType, corev1.ConditionTrue),
+						framework.NewConditionTypeStatusTuple(v1beta1.WebhookReadyType, corev1.ConditionTrue),
 						framework.NewConditionTypeStatusTuple(v1beta1.ProgressingType, corev1.ConditionFalse),
 						framework.NewConditionTypeStatusTuple(v1beta1.DegradedType, corev1.ConditionFalse),
 						framework.NewConditionTypeStatusTuple(v1beta1.PodPlacementControllerNotRolledOutType, corev1.ConditionFalse),
@@ -424,6 +469,47 @@ var _ = Describe("Controllers/ClusterPodPlacementConfig/ClusterPodPlacementConfi
 					)).Should(Succeed(), "the ClusterPodPlacementConfig should have the correct conditions")
 			})
 		})
+		When("the pod placement controller reports the image inspections", func() {
+			AfterEach(func() {
+				err := k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+					Name:      utils.ImageInspectionHealthConfigMapName,
+					Namespace: utils.Namespace(),
+				}})
+				Expect(crclient.IgnoreNotFound(err)).NotTo(HaveOccurred(), "failed to delete the image inspection health report", err)
+			})
+			It("should transition the CacheHealthy condition according to the error rate", func() {
+				By("Verifying the cache health is unknown until the first report")
+				Eventually(framework.VerifyConditions(ctx, k8sClient,
+					framework.NewConditionTypeStatusTuple(v1beta1.CacheHealthyType, corev1.ConditionUnknown),
+				)).Should(Succeed(), "the CacheHealthy condition should be Unknown")
+				By("Reporting an error rate above the threshold")
+				report := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      utils.ImageInspectionHealthConfigMapName,
+						Namespace: utils.Namespace(),
+					},
+					Data: map[string]string{
+						utils.ImageInspectionHealthInspectionsKey: "100",
+						utils.ImageInspectionHealthFailuresKey:    "50",
+						utils.ImageInspectionHealthWindowKey:      "10m0s",
+						utils.ImageInspectionHealthUpdateTimeKey:  time.Now().UTC().Format(time.RFC3339),
+					},
+				}
+				Expect(k8sClient.Create(ctx, report)).To(Succeed(), "failed to create the image inspection health report")
+				triggerReconcile()
+				Eventually(framework.VerifyConditions(ctx, k8sClient,
+					framework.NewConditionTypeStatusTuple(v1beta1.CacheHealthyType, corev1.ConditionFalse),
+					framework.NewConditionTypeStatusTuple(v1beta1.AvailableType, corev1.ConditionTrue),
+				)).Should(Succeed(), "the CacheHealthy condition should be False")
+				By("Reporting an error rate below the threshold")
+				report.Data[utils.ImageInspectionHealthFailuresKey] = "1"
+				Expect(k8sClient.Update(ctx, report)).To(Succeed(), "failed to update the image inspection health report")
+				triggerReconcile()
+				Eventually(framework.VerifyConditions(ctx, k8sClient,
+					framework.NewConditionTypeStatusTuple(v1beta1.CacheHealthyType, corev1.ConditionTrue),
+				)).Should(Succeed(), "the CacheHealthy condition should be True")
+			})
+		})
 		When("the pod placement controller is not available", func() {
 			It("should be degraded, progressing and no mutating webhook should be present", func() {
 				patchDeploymentStatus(utils.PodPlacementControllerName, NewGomegaWithT(GinkgoT()), func(d *appsv1.Deployment) {
@@ -460,6 +546,7 @@ var _ = Describe("Controllers/ClusterPodPlacementConfig/ClusterPodPlacementConfi
 				})
 				By("Verifying the conditions are correct")
 				Eventually(framework.VerifyConditions(ctx, k8sClient,
+					framework.NewConditionTypeStatusTuple(v1beta1.WebhookReadyType, corev1.ConditionFalse),
 					framework.NewConditionTypeStatusTuple(v1beta1.AvailableType, corev1.ConditionFalse),
 					framework.NewConditionTypeStatusTuple(v1beta1.ProgressingType, corev1.ConditionTrue),
 					framework.NewConditionTypeStatusTuple(v1beta1.DegradedType, corev1.ConditionTrue),
@@ -633,6 +720,20 @@ func patchDeploymentStatus(name string, g Gomega, patch func(*appsv1.Deployment)
 	g.Expect(err).NotTo(HaveOccurred(), "failed to update deployment "+name, err)
 }
 
+// triggerReconcile updates an annotation of the ClusterPodPlacementConfig to have it reconciled without waiting for
+// the periodic refresh of the health conditions.
+func triggerReconcile() {
+	Eventually(func(g Gomega) {
+		cppc := &v1beta1.ClusterPodPlacementConfig{}
+		g.Expect(k8sClient.Get(ctx, crclient.ObjectKey{Name: common.SingletonResourceObjectName}, cppc)).To(Succeed())
+		if cppc.Annotations == nil {
+			cppc.Annotations = map[string]string{}
+		}
+		cppc.Annotations["test.multiarch.openshift.io/trigger"] = time.Now().String()
+		g.Expect(k8sClient.Update(ctx, cppc)).To(Succeed())
+	}).Should(Succeed(), "failed to trigger the reconciliation of the ClusterPodPlacementConfig")
+}
+
 func setDeploymentReady(name string, g Gomega) {
 	patchDeploymentStatus(name, g, func(deployment *appsv1.Deployment) {
 		// This will simulate the deployment being available for the integration tests, letting the
@@ -656,3 +757,217 @@ func validateReconcile() {
 	Eventually(framework.ValidateCreation(k8sClient, ctx)).Should(Succeed(), "the ClusterPodPlacementConfig should be created")
 	By("The ClusterPodPlacementConfig is ready")
 }
+
+func TestImageInspectionCacheHealth(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	report := func(inspections, failures string, updateTime time.Time) *corev1.ConfigMap {
+		return &corev1.ConfigMap{Data: map[string]string{
+			utils.ImageInspectionHealthInspectionsKey: inspections,
+			utils.ImageInspectionHealthFailuresKey:    failures,
+			utils.ImageInspectionHealthWindowKey:      "10m0s",
+			utils.ImageInspectionHealthUpdateTimeKey:  updateTime.Format(time.RFC3339),
+		}}
+	}
+	tests := []struct {
+		name       string
+		report     *corev1.ConfigMap
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "no report",
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: v1beta1.UnknownReason,
+		},
+		{
+			name:       "stale report",
+			report:     report("100", "90", now.Add(-time.Hour)),
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: v1beta1.UnknownReason,
+		},
+		{
+			name:       "invalid report",
+			report:     report("many", "0", now),
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: v1beta1.UnknownReason,
+		},
+		{
+			name:       "no inspections",
+			report:     report("0", "0", now),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: v1beta1.ReadyReason,
+		},
+		{
+			name:       "error rate equal to the threshold",
+			report:     report("100", "20", now.Add(-time.Minute)),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: v1beta1.ReadyReason,
+		},
+		{
+			name:       "error rate above the threshold",
+			report:     report("100", "21", now),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: v1beta1.DegradedReason,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			status, reason, message := imageInspectionCacheHealth(tt.report, v1beta1.DefaultImageInspectionErrorRateThreshold, now)
+			g.Expect(status).To(Equal(tt.wantStatus))
+			g.Expect(reason).To(Equal(tt.wantReason))
+			g.Expect(message).NotTo(BeEmpty())
+		})
+	}
+}
+
+func TestProxyEnv(t *testing.T) {
+	clusterProxy := &ocpconfigv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{Name: common.SingletonResourceObjectName},
+		Spec: ocpconfigv1.ProxySpec{
+			HTTPSProxy: "http://ignored-spec-proxy:3128",
+		},
+		Status: ocpconfigv1.ProxyStatus{
+			HTTPProxy:  "http://cluster-proxy:3128",
+			HTTPSProxy: "http://cluster-proxy:3129",
+			NoProxy:    ".cluster.local,.svc,10.0.0.0/16",
+		},
+	}
+	tests := []struct {
+		name         string
+		proxy        *v1beta1.ImageInspectionProxy
+		clusterProxy *ocpconfigv1.Proxy
+		want         []corev1.EnvVar
+	}{
+		{
+			name: "inherits the proxy of the operator",
+			want: []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: "http://operator-proxy:3128"},
+				{Name: "HTTPS_PROXY", Value: "http://operator-proxy:3128"},
+				{Name: "NO_PROXY", Value: ".cluster.local"},
+			},
+		},
+		{
+			name: "overrides the proxy of the operator",
+			proxy: &v1beta1.ImageInspectionProxy{
+				HTTPSProxy: "http://registry-proxy:3128",
+				NoProxy:    "registry.example.com",
+			},
+			want: []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: "http://operator-proxy:3128"},
+				{Name: "HTTPS_PROXY", Value: "http://registry-proxy:3128"},
+				{Name: "NO_PROXY", Value: "registry.example.com"},
+			},
+		},
+		{
+			name:         "inherits the effective cluster-wide proxy",
+			clusterProxy: clusterProxy,
+			want: []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: "http://cluster-proxy:3128"},
+				{Name: "HTTPS_PROXY", Value: "http://cluster-proxy:3129"},
+				{Name: "NO_PROXY", Value: ".cluster.local,.svc,10.0.0.0/16"},
+			},
+		},
+		{
+			name:         "falls back to the proxy of the operator when the cluster-wide proxy is not configured",
+			clusterProxy: &ocpconfigv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: common.SingletonResourceObjectName}},
+			want: []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: "http://operator-proxy:3128"},
+				{Name: "HTTPS_PROXY", Value: "http://operator-proxy:3128"},
+				{Name: "NO_PROXY", Value: ".cluster.local"},
+			},
+		},
+		{
+			name: "overrides the cluster-wide proxy",
+			proxy: &v1beta1.ImageInspectionProxy{
+				HTTPSProxy: "http://registry-proxy:3128",
+			},
+			clusterProxy: clusterProxy,
+			want: []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: "http://cluster-proxy:3128"},
+				{Name: "HTTPS_PROXY", Value: "http://registry-proxy:3128"},
+				{Name: "NO_PROXY", Value: ".cluster.local,.svc,10.0.0.0/16"},
+			},
+		},
+	}
+	t.Setenv("HTTP_PROXY", "http://operator-proxy:3128")
+	t.Setenv("HTTPS_PROXY", "http://operator-proxy:3128")
+	t.Setenv("NO_PROXY", ".cluster.local")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cppc := builder.NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).Build()
+			cppc.Spec.Proxy = tt.proxy
+			g.Expect(proxyEnv(cppc, tt.clusterProxy)).To(Equal(tt.want))
+			for _, d := range []*appsv1.Deployment{
+				buildControllerDeployment(cppc, tt.clusterProxy, nil), buildWebhookDeployment(cppc, tt.clusterProxy, nil),
+			} {
+				g.Expect(d.Spec.Template.Spec.Containers[0].Env).To(ContainElements(tt.want))
+			}
+		})
+	}
+}
+
+func TestClusterProxyToClusterPodPlacementConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(clusterProxyToClusterPodPlacementConfig(context.Background(), &ocpconfigv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{Name: common.SingletonResourceObjectName},
+	})).To(Equal([]ctrl.Request{{NamespacedName: types.NamespacedName{Name: common.SingletonResourceObjectName}}}))
+	g.Expect(clusterProxyToClusterPodPlacementConfig(context.Background(), &ocpconfigv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+	})).To(BeEmpty())
+}
+
+func TestAddCustomCABundle(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cppc := builder.NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).Build()
+	for _, d := range []*appsv1.Deployment{
+		buildControllerDeployment(cppc, nil, nil), buildWebhookDeployment(cppc, nil, nil),
+	} {
+		g.Expect(d.Spec.Template.Annotations).NotTo(HaveKey(customCABundleHashAnnotation))
+		g.Expect(d.Spec.Template.Spec.Volumes).NotTo(ContainElement(HaveField("Name", customCABundleVolumeName)))
+		g.Expect(d.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "SSL_CERT_DIR")))
+	}
+
+	cppc.Spec.CustomCABundleSecretRef = &corev1.SecretReference{Name: "custom-ca-bundle"}
+	bundle := []byte("-----BEGIN CERTIFICATE-----")
+	for _, d := range []*appsv1.Deployment{
+		buildControllerDeployment(cppc, nil, bundle), buildWebhookDeployment(cppc, nil, bundle),
+	} {
+		g.Expect(d.Spec.Template.Annotations).To(HaveKeyWithValue(customCABundleHashAnnotation,
+			fmt.Sprintf("%x", sha256.Sum256(bundle))))
+		g.Expect(d.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: customCABundleVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  "custom-ca-bundle",
+					DefaultMode: utils.NewPtr(int32(420)),
+					Items:       []corev1.KeyToPath{{Key: v1beta1.CustomCABundleKey, Path: "custom-ca-bundle.pem"}},
+				},
+			},
+		}))
+		container := d.Spec.Template.Spec.Containers[0]
+		g.Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      customCABundleVolumeName,
+			MountPath: customCABundleMountPath,
+			ReadOnly:  true,
+		}))
+		g.Expect(container.Env).To(ContainElement(corev1.EnvVar{
+			Name:  "SSL_CERT_DIR",
+			Value: "/etc/ssl/certs:/etc/pki/tls/certs:" + customCABundleMountPath,
+		}))
+	}
+	// A different bundle rolls out the operands
+	g.Expect(buildControllerDeployment(cppc, nil, []byte("other")).Spec.Template.Annotations).NotTo(
+		HaveKeyWithValue(customCABundleHashAnnotation, fmt.Sprintf("%x", sha256.Sum256(bundle))))
+}
+
+func TestBuildValidatingWebhookConfiguration(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cppc := builder.NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).Build()
+	for _, w := range buildValidatingWebhookConfiguration(cppc).Webhooks {
+		g.Expect(w.TimeoutSeconds).To(HaveValue(Equal(validatingWebhookTimeoutSeconds)), w.Name)
+	}
+	// The validating webhooks inspect the images without the pull secrets of the pods
+	g.Expect(buildClusterRoleWebhook().Rules).NotTo(ContainElement(HaveField("Resources", ContainElement("secrets"))))
+}
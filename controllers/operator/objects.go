@@ -1,8 +1,11 @@
 package operator
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -12,9 +15,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	ocpconfigv1 "github.com/openshift/api/config/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
 	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 )
 
@@ -36,8 +41,22 @@ const (
 	requiredSCCAnnotation      = "openshift.io/required-scc"
 	requiredSCCRestrictedV2    = "restricted-v2"
 	requiredSCCHostmoundAnyUID = "hostmount-anyuid"
+
+	customCABundleVolumeName = "custom-ca-bundle"
+	customCABundleMountPath  = "/etc/pki/multiarch-tuning-operator/custom-ca"
+	// customCABundleHashAnnotation is set on the pod template of the operands to the SHA-256 hash of the custom CA
+	// bundle, see addCustomCABundle.
+	customCABundleHashAnnotation = "multiarch.openshift.io/custom-ca-bundle-hash"
+
+	// validatingWebhookTimeoutSeconds bounds the synchronous image inspections of the validating webhooks: the API
+	// server admits the objects when it expires, as their failure policy is Ignore.
+	validatingWebhookTimeoutSeconds int32 = 5
 )
 
+// systemCertDirs are the default directories the Go crypto/x509 package loads the system certificate pool from on
+// Linux. Setting SSL_CERT_DIR overrides them: they are kept along with the custom CA bundle.
+var systemCertDirs = []string{"/etc/ssl/certs", "/etc/pki/tls/certs"}
+
 func buildMutatingWebhookConfiguration(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig) *admissionv1.MutatingWebhookConfiguration {
 	return &admissionv1.MutatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -81,6 +100,105 @@ func buildMutatingWebhookConfiguration(clusterPodPlacementConfig *v1beta1.Cluste
 	}
 }
 
+func buildValidatingWebhookConfiguration(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig) *admissionv1.ValidatingWebhookConfiguration {
+	return &admissionv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: utils.PodValidatingWebhookConfigurationName,
+			Labels: map[string]string{
+				utils.OperandLabelKey:   operandName,
+				utils.ControllerNameKey: utils.PodPlacementWebhookName,
+			},
+			Annotations: map[string]string{
+				"service.beta.openshift.io/inject-cabundle": "true",
+			},
+		},
+		Webhooks: []admissionv1.ValidatingWebhook{
+			{
+				AdmissionReviewVersions: []string{"v1"},
+				ClientConfig: admissionv1.WebhookClientConfig{
+					Service: &admissionv1.ServiceReference{
+						Name:      utils.PodPlacementWebhookName,
+						Namespace: utils.Namespace(),
+						Path:      utils.NewPtr("/validate-pod-architecture"),
+					},
+				},
+				NamespaceSelector: clusterPodPlacementConfig.Spec.NamespaceSelector,
+				FailurePolicy:     utils.NewPtr(admissionv1.Ignore),
+				SideEffects:       utils.NewPtr(admissionv1.SideEffectClassNone),
+				TimeoutSeconds:    utils.NewPtr(validatingWebhookTimeoutSeconds),
+				Name:              utils.PodValidatingWebhookName,
+				Rules: []admissionv1.RuleWithOperations{
+					{
+						Operations: []admissionv1.OperationType{
+							admissionv1.Create,
+						},
+						Rule: admissionv1.Rule{
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"pods"},
+						},
+					},
+				},
+			},
+			{
+				AdmissionReviewVersions: []string{"v1"},
+				ClientConfig: admissionv1.WebhookClientConfig{
+					Service: &admissionv1.ServiceReference{
+						Name:      utils.PodPlacementWebhookName,
+						Namespace: utils.Namespace(),
+						Path:      utils.NewPtr("/validate-job-architecture"),
+					},
+				},
+				NamespaceSelector: clusterPodPlacementConfig.Spec.NamespaceSelector,
+				FailurePolicy:     utils.NewPtr(admissionv1.Ignore),
+				SideEffects:       utils.NewPtr(admissionv1.SideEffectClassNone),
+				TimeoutSeconds:    utils.NewPtr(validatingWebhookTimeoutSeconds),
+				Name:              utils.JobValidatingWebhookName,
+				Rules: []admissionv1.RuleWithOperations{
+					{
+						Operations: []admissionv1.OperationType{
+							admissionv1.Create,
+						},
+						Rule: admissionv1.Rule{
+							APIGroups:   []string{"batch"},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"jobs", "cronjobs"},
+						},
+					},
+				},
+			},
+			{
+				AdmissionReviewVersions: []string{"v1"},
+				ClientConfig: admissionv1.WebhookClientConfig{
+					Service: &admissionv1.ServiceReference{
+						Name:      utils.PodPlacementWebhookName,
+						Namespace: utils.Namespace(),
+						Path:      utils.NewPtr("/validate-workload-architecture"),
+					},
+				},
+				NamespaceSelector: clusterPodPlacementConfig.Spec.NamespaceSelector,
+				FailurePolicy:     utils.NewPtr(admissionv1.Ignore),
+				SideEffects:       utils.NewPtr(admissionv1.SideEffectClassNone),
+				TimeoutSeconds:    utils.NewPtr(validatingWebhookTimeoutSeconds),
+				Name:              utils.WorkloadValidatingWebhookName,
+				Rules: []admissionv1.RuleWithOperations{
+					{
+						Operations: []admissionv1.OperationType{
+							admissionv1.Create,
+							admissionv1.Update,
+						},
+						Rule: admissionv1.Rule{
+							APIGroups:   []string{"apps"},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"deployments", "statefulsets", "replicasets"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func buildService(name string) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -117,16 +235,93 @@ func buildService(name string) *corev1.Service {
 	}
 }
 
-func buildWebhookDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig) *appsv1.Deployment {
-	return buildDeployment(clusterPodPlacementConfig, utils.PodPlacementWebhookName, 3, utils.PodPlacementWebhookName, "",
-		"--enable-ppc-webhook", "--enable-cppc-informer",
+func buildWebhookDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig,
+	clusterProxy *ocpconfigv1.Proxy, customCABundle []byte) *appsv1.Deployment {
+	delayedEventBackoff := clusterPodPlacementConfig.Spec.DelayedEventBackoff
+	return buildDeployment(clusterPodPlacementConfig, clusterProxy, customCABundle, utils.PodPlacementWebhookName, 3, utils.PodPlacementWebhookName, "",
+		append([]string{"--enable-ppc-webhook", "--enable-cppc-informer",
+			fmt.Sprintf("--webhook-worker-pool-size=%d", clusterPodPlacementConfig.Spec.GetWebhookWorkerPoolSize()),
+			fmt.Sprintf("--delayed-event-backoff-duration=%s", delayedEventBackoff.GetDuration()),
+			fmt.Sprintf("--delayed-event-backoff-factor=%g", delayedEventBackoff.GetFactor()),
+			fmt.Sprintf("--delayed-event-backoff-steps=%d", delayedEventBackoff.GetSteps()),
+			fmt.Sprintf("--delayed-event-backoff-jitter=%g", delayedEventBackoff.GetJitter()),
+			fmt.Sprintf("--per-namespace-metrics=%t", clusterPodPlacementConfig.Spec.PerNamespaceMetrics),
+			fmt.Sprintf("--scheduling-gate-name=%s", clusterPodPlacementConfig.Spec.GetSchedulingGateName()),
+			fmt.Sprintf("--managed-scheduling-gates=%s", strings.Join(clusterPodPlacementConfig.Spec.ManagedSchedulingGates, ",")),
+		}, append(imageInspectionArgs(clusterPodPlacementConfig), pprofArgs(clusterPodPlacementConfig)...)...)...,
 	)
 
 }
 
-func buildControllerDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig) *appsv1.Deployment {
-	d := buildDeployment(clusterPodPlacementConfig, utils.PodPlacementControllerName, 2, utils.PodPlacementControllerName,
-		utils.PodPlacementFinalizerName, "--leader-elect", "--enable-ppc-controllers", "--enable-cppc-informer",
+// pprofArgs returns the arguments enabling the pprof handlers of the operands, if enabled in the
+// ClusterPodPlacementConfig.
+func pprofArgs(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig) []string {
+	if !clusterPodPlacementConfig.Spec.EnablePprof {
+		return nil
+	}
+	return []string{"--enable-pprof"}
+}
+
+// proxyEnv returns the proxy environment variables of the operands. The proxy settings of the
+// ClusterPodPlacementConfig override the effective ones of the OpenShift cluster-wide proxy, if any, which in turn
+// override the ones of the operator. The image inspections honour them through http.ProxyFromEnvironment.
+func proxyEnv(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig, clusterProxy *ocpconfigv1.Proxy) []corev1.EnvVar {
+	proxy := clusterPodPlacementConfig.Spec.Proxy
+	if proxy == nil {
+		proxy = &v1beta1.ImageInspectionProxy{}
+	}
+	clusterProxyStatus := ocpconfigv1.ProxyStatus{}
+	if clusterProxy != nil {
+		clusterProxyStatus = clusterProxy.Status
+	}
+	env := func(name string, overrides ...string) corev1.EnvVar {
+		for _, override := range overrides {
+			if override != "" {
+				return corev1.EnvVar{Name: name, Value: override}
+			}
+		}
+		return corev1.EnvVar{Name: name, Value: os.Getenv(name)}
+	}
+	return []corev1.EnvVar{
+		env("HTTP_PROXY", proxy.HTTPProxy, clusterProxyStatus.HTTPProxy),
+		env("HTTPS_PROXY", proxy.HTTPSProxy, clusterProxyStatus.HTTPSProxy),
+		env("NO_PROXY", proxy.NoProxy, clusterProxyStatus.NoProxy),
+	}
+}
+
+// imageInspectionArgs returns the arguments configuring the concurrency, the retries, the circuit breakers, the cache
+// and the per-registry timeouts of the image inspections in the operands.
+func imageInspectionArgs(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig) []string {
+	retryPolicy := clusterPodPlacementConfig.Spec.ImageInspectionRetryPolicy
+	circuitBreaker := clusterPodPlacementConfig.Spec.ImageInspectionCircuitBreaker
+	return []string{
+		fmt.Sprintf("--max-concurrent-inspections=%d", clusterPodPlacementConfig.Spec.GetMaxConcurrentInspections()),
+		fmt.Sprintf("--image-inspection-retry-initial-interval=%s", retryPolicy.GetInitialInterval()),
+		fmt.Sprintf("--image-inspection-retry-max-interval=%s", retryPolicy.GetMaxInterval()),
+		fmt.Sprintf("--image-inspection-retry-multiplier=%g", retryPolicy.GetMultiplier()),
+		fmt.Sprintf("--image-inspection-retry-max-retries=%d", retryPolicy.GetMaxRetries()),
+		fmt.Sprintf("--image-inspection-circuit-breaker-failure-threshold=%d", circuitBreaker.GetFailureThreshold()),
+		fmt.Sprintf("--image-inspection-circuit-breaker-reset-timeout=%s", circuitBreaker.GetResetTimeout()),
+		fmt.Sprintf("--image-inspection-cache-jitter-fraction=%g", clusterPodPlacementConfig.Spec.GetCacheJitterFraction()),
+		fmt.Sprintf("--image-inspection-pin-digest-in-cache=%t", clusterPodPlacementConfig.Spec.PinDigestInCache),
+		fmt.Sprintf("--image-inspection-registry-timeouts=%s",
+			image.RegistryTimeouts(clusterPodPlacementConfig.Spec.GetRegistryTimeouts())),
+	}
+}
+
+func buildControllerDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig,
+	clusterProxy *ocpconfigv1.Proxy, customCABundle []byte) *appsv1.Deployment {
+	globalPullSecretNamespace, globalPullSecretName := clusterPodPlacementConfig.Spec.GetGlobalImagePullSecretNamespacedName()
+	d := buildDeployment(clusterPodPlacementConfig, clusterProxy, customCABundle, utils.PodPlacementControllerName, 2, utils.PodPlacementControllerName,
+		utils.PodPlacementFinalizerName, append([]string{"--leader-elect", "--enable-ppc-controllers", "--enable-cppc-informer",
+			fmt.Sprintf("--gate-removal-worker-pool-size=%d", clusterPodPlacementConfig.Spec.GetGateRemovalWorkerPoolSize()),
+			fmt.Sprintf("--max-gate-duration=%s", clusterPodPlacementConfig.Spec.GetMaxGateDuration()),
+			fmt.Sprintf("--per-namespace-metrics=%t", clusterPodPlacementConfig.Spec.PerNamespaceMetrics),
+			fmt.Sprintf("--scheduling-gate-name=%s", clusterPodPlacementConfig.Spec.GetSchedulingGateName()),
+			fmt.Sprintf("--managed-scheduling-gates=%s", strings.Join(clusterPodPlacementConfig.Spec.ManagedSchedulingGates, ",")),
+			fmt.Sprintf("--global-pull-secret-namespace=%s", globalPullSecretNamespace),
+			fmt.Sprintf("--global-pull-secret-name=%s", globalPullSecretName),
+		}, append(imageInspectionArgs(clusterPodPlacementConfig), pprofArgs(clusterPodPlacementConfig)...)...)...,
 	)
 	if d.Spec.Template.Annotations == nil {
 		d.Spec.Template.Annotations = map[string]string{}
@@ -167,13 +362,14 @@ func buildControllerDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlac
 	return d
 }
 
-func buildDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig,
-	name string, replicas int32, serviceAccount string, finalizer string, args ...string) *appsv1.Deployment {
+func buildDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig, clusterProxy *ocpconfigv1.Proxy,
+	customCABundle []byte, name string, replicas int32, serviceAccount string, finalizer string,
+	args ...string) *appsv1.Deployment {
 	finalizers := make([]string, 0)
 	if finalizer != "" {
 		finalizers = append(finalizers, finalizer)
 	}
-	return &appsv1.Deployment{
+	d := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: utils.Namespace(),
@@ -239,25 +435,12 @@ func buildDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfi
 							Name:            name,
 							Image:           utils.Image(),
 							ImagePullPolicy: corev1.PullIfNotPresent,
-							Env: []corev1.EnvVar{
+							Env: append([]corev1.EnvVar{
 								{
 									Name:  "NAMESPACE",
 									Value: utils.Namespace(),
 								},
-								{
-									Name:  "HTTP_PROXY",
-									Value: os.Getenv("HTTP_PROXY"),
-								},
-								{
-									Name:  "HTTPS_PROXY",
-									Value: os.Getenv("HTTPS_PROXY"),
-								},
-
-								{
-									Name:  "NO_PROXY",
-									Value: os.Getenv("NO_PROXY"),
-								},
-							},
+							}, proxyEnv(clusterPodPlacementConfig, clusterProxy)...),
 							Args: append([]string{
 								"--health-probe-bind-address=:8081",
 								"--metrics-bind-address=:8443",
@@ -376,6 +559,47 @@ func buildDeployment(clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfi
 			},
 		},
 	}
+	addCustomCABundle(d, clusterPodPlacementConfig, customCABundle)
+	return d
+}
+
+// addCustomCABundle mounts the Secret referenced by the CustomCABundleSecretRef of the ClusterPodPlacementConfig, if
+// any, in the container of the operand deployment, and adds its directory to the SSL_CERT_DIR directories the system
+// certificate pool is loaded from. containers/image builds the TLS configuration of the image inspections on the
+// system certificate pool, which is only loaded once: the hash of the given bundle is set in an annotation of the pod
+// template, so that the operands are rolled out when it changes.
+func addCustomCABundle(d *appsv1.Deployment, clusterPodPlacementConfig *v1beta1.ClusterPodPlacementConfig,
+	customCABundle []byte) {
+	ref := clusterPodPlacementConfig.Spec.CustomCABundleSecretRef
+	if ref == nil {
+		return
+	}
+	d.Spec.Template.Annotations[customCABundleHashAnnotation] = fmt.Sprintf("%x", sha256.Sum256(customCABundle))
+	d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: customCABundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  ref.Name,
+				DefaultMode: utils.NewPtr(int32(420)),
+				Items: []corev1.KeyToPath{
+					{
+						Key:  v1beta1.CustomCABundleKey,
+						Path: "custom-ca-bundle.pem",
+					},
+				},
+			},
+		},
+	})
+	container := &d.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      customCABundleVolumeName,
+		MountPath: customCABundleMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "SSL_CERT_DIR",
+		Value: strings.Join(append(slices.Clone(systemCertDirs), customCABundleMountPath), ":"),
+	})
 }
 
 func buildClusterRole(name string, rules []rbacv1.PolicyRule) *rbacv1.ClusterRole {
@@ -496,6 +720,12 @@ func buildClusterRoleController() *rbacv1.ClusterRole {
 			Resources: []string{"configmaps", "secrets"},
 			Verbs:     []string{LIST, WATCH, GET},
 		},
+		{
+			// The ClusterArchInventory watches the architectures of the nodes
+			APIGroups: []string{""},
+			Resources: []string{"nodes"},
+			Verbs:     []string{LIST, WATCH, GET},
+		},
 		{
 			APIGroups: []string{"authentication.k8s.io"},
 			Resources: []string{"tokenreviews"},
@@ -55,6 +55,7 @@ import (
 
 	"go.uber.org/zap/zapcore"
 
+	ocpconfigv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/library-go/pkg/operator/events"
 
 	testingutils "github.com/openshift/multiarch-tuning-operator/pkg/testing/framework"
@@ -143,6 +144,8 @@ func startTestEnv() {
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
+	err = ocpconfigv1.Install(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
 	//+kubebuilder:scaffold:scheme
 
 	klog.Info("Applying CRDs to the test environment")
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	architecturePredicateCacheSize = 256
+	// architecturePredicateCacheTTL is short, as the cache only absorbs the bursts of pods with the same images, e.g.,
+	// the pods of a Deployment scaled up at once: it bounds the time the predicates are served after the entries of
+	// the images in the image inspection cache expire or are refreshed.
+	architecturePredicateCacheTTL = time.Minute
+)
+
+// architecturePredicateCache caches the architecture predicates of the sets of images, so that the pods with the
+// same images do not compute the intersection of their architectures again. Unlike the image inspection cache, it
+// caches the result of the intersection, not the architectures of the individual images.
+var architecturePredicateCache = expirable.NewLRU[string, architecturePredicate](architecturePredicateCacheSize, nil,
+	architecturePredicateCacheTTL)
+
+// digestPinner is implemented by the image.Facade.
+type digestPinner interface {
+	PinDigestInCache() bool
+}
+
+// architecturePredicate is an entry of the architecturePredicateCache.
+type architecturePredicate struct {
+	requirement   corev1.NodeSelectorRequirement
+	architectures []string
+}
+
+// architecturePredicateKey returns the key of the architecture predicate of the images of the pod in the
+// architecturePredicateCache. It is the key of the inspection of the images, i.e., the sorted image names, including
+// their digests, and the pull secrets, along with the cluster architectures the predicate is trimmed to. It returns
+// false if the predicate must not be cached, i.e., if the images of the pod skip the image inspection cache, or if
+// the image inspector pins the tags of the images to their digest: the key holds the tags, so a cached predicate
+// would bypass the resolution of the updated tags.
+func (pod *Pod) architecturePredicateKey(imageNamesSet sets.Set[containerImage], pullSecretDataList [][]byte) (string, bool) {
+	if pinner, ok := pod.imageInspector.(digestPinner); ok && pinner.PinDigestInCache() {
+		return "", false
+	}
+	for imageContainer := range imageNamesSet {
+		if imageContainer.skipCache {
+			return "", false
+		}
+	}
+	clusterArchitectures := "*"
+	if pod.clusterArchitectures != nil {
+		clusterArchitectures = strings.Join(sets.List(pod.clusterArchitectures), ",")
+	}
+	return inspectionKey(pod.imageInspector, imageNamesSet, pullSecretDataList) + "|" + clusterArchitectures, true
+}
+
+// cachedArchitecturePredicate returns a copy of the cached architecture predicate with the given key, as the callers
+// may mutate it.
+func cachedArchitecturePredicate(key string) (corev1.NodeSelectorRequirement, []string, bool) {
+	predicate, ok := architecturePredicateCache.Get(key)
+	if !ok {
+		return corev1.NodeSelectorRequirement{}, nil, false
+	}
+	return *predicate.requirement.DeepCopy(), slices.Clone(predicate.architectures), true
+}
+
+// cacheArchitecturePredicate adds a copy of the given architecture predicate to the architecturePredicateCache.
+func cacheArchitecturePredicate(key string, requirement corev1.NodeSelectorRequirement, architectures []string) {
+	architecturePredicateCache.Add(key, architecturePredicate{
+		requirement:   *requirement.DeepCopy(),
+		architectures: slices.Clone(architectures),
+	})
+}
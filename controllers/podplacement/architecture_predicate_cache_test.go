@@ -0,0 +1,141 @@
+package podplacement
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	. "github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// countingCache is an image.ICache counting the images it is asked for, and supporting amd64 and arm64 for any image.
+type countingCache struct {
+	calls atomic.Int32
+}
+
+func (c *countingCache) GetCompatibleArchitecturesSet(_ context.Context, _ string, _ string, _ bool,
+	_ [][]byte) (sets.Set[string], error) {
+	c.calls.Add(1)
+	return sets.New(utils.ArchitectureAmd64, utils.ArchitectureArm64), nil
+}
+
+func TestPod_getArchitecturePredicate_Cache(t *testing.T) {
+	metrics.InitPodPlacementControllerMetrics()
+	g := NewGomegaWithT(t)
+	cache := &countingCache{}
+	wantRequirement := corev1.NodeSelectorRequirement{
+		Key:      utils.ArchLabel,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+	}
+	images := []string{"quay.io/example/app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		"quay.io/example/sidecar:latest"}
+
+	pod := &Pod{Pod: *NewPod().WithContainersImages(images...).Build(), ctx: ctx, imageInspector: cache}
+	key, cacheable := pod.architecturePredicateKey(pod.imagesNamesSet(), nil)
+	g.Expect(cacheable).To(BeTrue())
+	g.Expect(architecturePredicateCache.Contains(key)).To(BeFalse())
+	requirement, architectures, err := pod.getArchitecturePredicate(nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(requirement).To(Equal(wantRequirement))
+	g.Expect(cache.calls.Load()).To(Equal(int32(len(images))))
+	g.Expect(architecturePredicateCache.Contains(key)).To(BeTrue(), "the predicate should be cached")
+	// The callers get a copy of the cached predicate
+	requirement.Values[0] = utils.ArchitectureS390x
+	architectures[0] = utils.ArchitectureS390x
+
+	// Processing a pod with the same images, in a different order
+	pod = &Pod{Pod: *NewPod().WithContainersImages(images[1], images[0]).Build(), ctx: ctx, imageInspector: cache}
+	requirement, architectures, err = pod.getArchitecturePredicate(nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(requirement).To(Equal(wantRequirement))
+	g.Expect(architectures).To(Equal([]string{utils.ArchitectureAmd64, utils.ArchitectureArm64}))
+	g.Expect(cache.calls.Load()).To(Equal(int32(len(images))), "the cached predicate should be used")
+	g.Expect(pod.Annotations).To(HaveKey(utils.InspectedImagesAnnotation))
+
+	// Processing a pod with the same images, trimmed to the cluster architectures
+	pod = &Pod{Pod: *NewPod().WithContainersImages(images...).Build(), ctx: ctx, imageInspector: cache,
+		clusterArchitectures: sets.New[string](utils.ArchitectureArm64)}
+	requirement, _, err = pod.getArchitecturePredicate(nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(requirement.Values).To(Equal([]string{utils.ArchitectureArm64}))
+	g.Expect(cache.calls.Load()).To(Equal(int32(2*len(images))), "the predicates of the cluster architectures differ")
+
+	// Processing a pod whose images skip the cache
+	pod = &Pod{Pod: *NewPod().WithContainersImages(images...).
+		WithAnnotations(utils.ForceRefreshAnnotation, "true").Build(), ctx: ctx, imageInspector: cache}
+	_, cacheable = pod.architecturePredicateKey(pod.imagesNamesSet(), nil)
+	g.Expect(cacheable).To(BeFalse())
+	_, _, err = pod.getArchitecturePredicate(nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cache.calls.Load()).To(Equal(int32(3*len(images))), "the images should be inspected again")
+}
+
+// pinningCache is a countingCache pinning the tags of the images to their digest.
+type pinningCache struct {
+	countingCache
+}
+
+func (c *pinningCache) PinDigestInCache() bool {
+	return true
+}
+
+func TestPod_getArchitecturePredicate_CachePinDigest(t *testing.T) {
+	metrics.InitPodPlacementControllerMetrics()
+	g := NewGomegaWithT(t)
+	cache := &pinningCache{}
+	pod := &Pod{Pod: *NewPod().WithContainersImages("quay.io/example/pinned:latest").Build(), ctx: ctx,
+		imageInspector: cache}
+	_, cacheable := pod.architecturePredicateKey(pod.imagesNamesSet(), nil)
+	g.Expect(cacheable).To(BeFalse(), "the predicates keyed by tag should not be cached while the digests are pinned")
+	for i := 0; i < 2; i++ {
+		_, _, err := pod.getArchitecturePredicate(nil)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(cache.calls.Load()).To(Equal(int32(2)), "the tags should be resolved for each pod")
+}
+
+func TestPod_getArchitecturePredicate_CacheErrors(t *testing.T) {
+	metrics.InitPodPlacementControllerMetrics()
+	g := NewGomegaWithT(t)
+	pod := &Pod{Pod: *NewPod().WithContainersImages("quay.io/example/unknown:latest").Build(), ctx: ctx,
+		imageInspector: fake.NewFacade()}
+	key, _ := pod.architecturePredicateKey(pod.imagesNamesSet(), nil)
+	_, _, err := pod.getArchitecturePredicate(nil)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(architecturePredicateCache.Contains(key)).To(BeFalse(), "the failed inspections should not be cached")
+}
+
+func BenchmarkPod_getArchitecturePredicate(b *testing.B) {
+	metrics.InitPodPlacementControllerMetrics()
+	facade := fake.NewFacade()
+	pod := &Pod{Pod: *NewPod().WithContainersImages(fake.MultiArchImage, fake.MultiArchImage2,
+		fake.SingleArchAmd64Image).Build(), ctx: ctx, imageInspector: facade}
+	for _, bb := range []struct {
+		name   string
+		cached bool
+	}{
+		{name: "uncached predicate"},
+		{name: "cached predicate", cached: true},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !bb.cached {
+					// The images are still served by the image inspection cache
+					architecturePredicateCache.Purge()
+				}
+				if _, _, err := pod.getArchitecturePredicate(nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// ImageArchInfo reports the architectures supported by an image of a pod.
+type ImageArchInfo struct {
+	// Image is the name of the image, as set in the pod spec.
+	Image string
+	// Architectures are the architectures supported by the image, qualified by their variant (e.g., arm/v7).
+	Architectures []string
+	// Error is the error of the inspection of the image, if any.
+	Error string
+}
+
+// ArchitectureSummary is a human-readable report of the architecture-aware scheduling of a pod. It explains the node
+// affinity the pod placement operand computes for the pod, e.g., for a kubectl plugin or a debug endpoint.
+type ArchitectureSummary struct {
+	// InspectedImages are the images of the pod and the architectures they support, sorted by image.
+	InspectedImages []ImageArchInfo
+	// SupportedArchitectures are the architectures supported by all the images of the pod.
+	SupportedArchitectures []string
+	// SelectedPredicate is the requirement the operand sets in the node affinity of the pod.
+	SelectedPredicate corev1.NodeSelectorRequirement
+	// ConflictDetected is true if the images of the pod do not support any common architecture.
+	ConflictDetected bool
+	// IgnoredReason is the reason why the pod is ignored by the operand, if it is. The other fields are not set.
+	IgnoredReason string
+}
+
+// GetArchitectureSummary returns the ArchitectureSummary of the pod. The images are inspected with the global pull
+// secret only: the images requiring the pull secrets of the pod report their inspection error in the summary, and
+// the first of these errors is returned.
+func (pod *Pod) GetArchitectureSummary(ctx context.Context) (ArchitectureSummary, error) {
+	cppc := clusterpodplacementconfig.GetClusterPodPlacementConfig()
+	pod.universalImages = universalImagesOf(cppc)
+	summary := ArchitectureSummary{
+		IgnoredReason: pod.ignoreReason(cppc),
+	}
+	if summary.IgnoredReason != "" {
+		return summary, nil
+	}
+	architectures := pod.architecturesOverride()
+	if architectures == nil {
+		var err error
+		summary.InspectedImages, architectures, err = pod.inspectImagesForSummary(ctx)
+		if err != nil {
+			return summary, err
+		}
+	}
+	summary.SupportedArchitectures = architectures
+	summary.SelectedPredicate = architecturesPredicate(architectures)
+	summary.ConflictDetected = len(architectures) == 0
+	return summary, nil
+}
+
+// inspectImagesForSummary inspects each image of the pod and returns their ImageArchInfo and the architectures they
+// all support.
+func (pod *Pod) inspectImagesForSummary(ctx context.Context) ([]ImageArchInfo, []string, error) {
+	imageNamesSet := pod.imagesNamesSet()
+	if imageNamesSet.Len() == 0 {
+		// All the containers use images with imagePullPolicy Never: no architecture constraint applies.
+		return nil, sets.List(utils.AllSupportedArchitecturesSet()), nil
+	}
+	var (
+		infos                     []ImageArchInfo
+		supportedArchitecturesSet sets.Set[string]
+		firstErr                  error
+	)
+	for _, candidates := range imageCandidates(imageNamesSet) {
+		info := ImageArchInfo{Image: candidates[0].displayName()}
+		architectures, err := inspectCandidates(ctx, pod.imageInspector, candidates, nil)
+		switch {
+		case err != nil:
+			info.Error = err.Error()
+			if firstErr == nil {
+				firstErr = err
+			}
+		case supportedArchitecturesSet == nil:
+			supportedArchitecturesSet = architectures
+		default:
+			supportedArchitecturesSet = intersectArchitectures(supportedArchitecturesSet, architectures)
+		}
+		info.Architectures = sets.List(architectures)
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b ImageArchInfo) int {
+		return strings.Compare(a.Image, b.Image)
+	})
+	if firstErr != nil {
+		return infos, nil, firstErr
+	}
+	return infos, sets.List(supportedArchitecturesSet), nil
+}
@@ -0,0 +1,103 @@
+package podplacement
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+
+	. "github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+)
+
+func TestPod_GetArchitectureSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		want    ArchitectureSummary
+		wantErr bool
+	}{
+		{
+			name: "pod with a multi-arch image",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("test-namespace").Build(),
+			want: ArchitectureSummary{
+				InspectedImages: []ImageArchInfo{
+					{Image: fake.MultiArchImage, Architectures: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64}},
+				},
+				SupportedArchitectures: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+				SelectedPredicate: corev1.NodeSelectorRequirement{
+					Key:      utils.ArchLabel,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+				},
+			},
+		},
+		{
+			name: "pod with a single-arch and a multi-arch image",
+			pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.MultiArchImage).
+				WithNamespace("test-namespace").Build(),
+			want: ArchitectureSummary{
+				InspectedImages: []ImageArchInfo{
+					{Image: fake.MultiArchImage, Architectures: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64}},
+					{Image: fake.SingleArchAmd64Image, Architectures: []string{utils.ArchitectureAmd64}},
+				},
+				SupportedArchitectures: []string{utils.ArchitectureAmd64},
+				SelectedPredicate: corev1.NodeSelectorRequirement{
+					Key:      utils.ArchLabel,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{utils.ArchitectureAmd64},
+				},
+			},
+		},
+		{
+			name: "pod with conflicting images",
+			pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).
+				WithNamespace("test-namespace").Build(),
+			want: ArchitectureSummary{
+				InspectedImages: []ImageArchInfo{
+					{Image: fake.SingleArchAmd64Image, Architectures: []string{utils.ArchitectureAmd64}},
+					{Image: fake.SingleArchArm64Image, Architectures: []string{utils.ArchitectureArm64}},
+				},
+				SupportedArchitectures: []string{},
+				SelectedPredicate: corev1.NodeSelectorRequirement{
+					Key:      utils.NoSupportedArchLabel,
+					Operator: corev1.NodeSelectorOpExists,
+				},
+				ConflictDetected: true,
+			},
+		},
+		{
+			name: "ignored pod",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("kube-system").Build(),
+			want: ArchitectureSummary{
+				IgnoredReason: IgnoreReasonKubeNamespace,
+			},
+		},
+		{
+			name: "pod with an image that cannot be inspected",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage, "non-existing-image").
+				WithNamespace("test-namespace").Build(),
+			wantErr: true,
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *tt.pod, ctx: ctx, imageInspector: fake.FacadeSingleton()}
+			got, err := pod.GetArchitectureSummary(ctx)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(got.InspectedImages).To(ContainElement(HaveField("Error", Not(BeEmpty()))),
+					"the inspection error should be reported in the summary")
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// imageInspectionCacheGenerationKey is the key of the ConfigMap's data holding the generation of the caches.
+const imageInspectionCacheGenerationKey = "generation"
+
+// cacheInvalidationsStore is implemented by the image.Facade.
+type cacheInvalidationsStore interface {
+	CacheInvalidations() uint64
+	PurgeCache()
+}
+
+// CacheGenerationSyncer keeps the image inspection caches of the replicas of the pod placement controller consistent.
+// The replica invalidating cached entries, i.e., the leader, bumps a generation counter stored in a ConfigMap.
+// The other replicas watch the ConfigMap and purge their cache when they observe that the generation advanced, so
+// that they do not serve stale entries when they take over the leadership.
+type CacheGenerationSyncer struct {
+	configMaps corev1client.ConfigMapInterface
+	store      cacheInvalidationsStore
+	// interval is the period of the publication of the invalidations of the local cache in the ConfigMap
+	interval time.Duration
+	// mutex serializes the generations observed by the ConfigMap informer and the ones published by this replica
+	mutex sync.Mutex
+	// generation is the last generation observed or published by this replica
+	generation int64
+	// publishedInvalidations is the number of invalidations of the local cache already published in the generation
+	publishedInvalidations uint64
+	log                    logr.Logger
+}
+
+func NewCacheGenerationSyncer(clientSet kubernetes.Interface, interval time.Duration) *CacheGenerationSyncer {
+	return &CacheGenerationSyncer{
+		configMaps: clientSet.CoreV1().ConfigMaps(utils.Namespace()),
+		store:      image.FacadeSingleton(),
+		interval:   interval,
+	}
+}
+
+// NeedLeaderElection returns false: the standby replicas have to follow the generation of the leader.
+func (s *CacheGenerationSyncer) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *CacheGenerationSyncer) Start(ctx context.Context) error {
+	s.log = log.FromContext(ctx, "handler", "CacheGenerationSyncer", "kind", "ConfigMap [core/v1]",
+		"namespace", utils.Namespace(), "name", utils.ImageInspectionCacheGenerationConfigMapName)
+	s.log.Info("Starting the Cache Generation Syncer")
+	// The entries cached before the start, if any, were loaded from the persistent cache: they are not purged.
+	if _, generation, err := s.get(ctx); err != nil {
+		s.log.Error(err, "Unable to get the image inspection cache generation")
+	} else {
+		s.generation = generation
+	}
+	s.publishedInvalidations = s.store.CacheInvalidations()
+	informer := s.newConfigMapInformer(ctx)
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: s.observe,
+		UpdateFunc: func(_, newObj interface{}) {
+			s.observe(newObj)
+		},
+	}); err != nil {
+		s.log.Error(err, "Error registering the handler of the image inspection cache generation ConfigMap")
+		return err
+	}
+	go informer.Run(ctx.Done())
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("Stopping the Cache Generation Syncer")
+			return nil
+		case <-ticker.C:
+			if err := s.publish(ctx); err != nil {
+				s.log.Error(err, "Unable to publish the image inspection cache generation")
+			}
+		}
+	}
+}
+
+// newConfigMapInformer returns an informer on the ConfigMap holding the generation only.
+func (s *CacheGenerationSyncer) newConfigMapInformer(ctx context.Context) cache.SharedIndexInformer {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name",
+		utils.ImageInspectionCacheGenerationConfigMapName).String()
+	return cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return s.configMaps.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return s.configMaps.Watch(ctx, options)
+		},
+	}, &corev1.ConfigMap{}, time.Hour, cache.Indexers{})
+}
+
+// observe purges the cache if the generation of the ConfigMap advanced.
+func (s *CacheGenerationSyncer) observe(obj interface{}) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	generation, err := parseGeneration(cm)
+	if err != nil {
+		s.log.Error(err, "Unable to observe the image inspection cache generation")
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.advance(generation)
+}
+
+// advance purges the cache if the given generation is newer than the one of this replica. The
+// architecturePredicateCache is purged along with the cache, as its predicates were computed from the stale entries.
+// The caller must hold the mutex.
+func (s *CacheGenerationSyncer) advance(generation int64) {
+	if generation <= s.generation {
+		return
+	}
+	s.log.Info("Purging the image inspection cache", "generation", generation, "previousGeneration", s.generation)
+	s.store.PurgeCache()
+	architecturePredicateCache.Purge()
+	s.generation = generation
+}
+
+// publish bumps the generation if the local cache was invalidated since the last publication. A failed bump, e.g.,
+// because of a conflicting update by another replica, is retried at the next publication. The
+// architecturePredicateCache is purged, as its predicates were computed from the invalidated entries.
+func (s *CacheGenerationSyncer) publish(ctx context.Context) error {
+	invalidations := s.store.CacheInvalidations()
+	if invalidations == s.publishedInvalidations {
+		return nil
+	}
+	architecturePredicateCache.Purge()
+	cm, generation, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+	// The mutex is held until the bump is recorded, so that the informer does not purge the cache on the
+	// generation published by this replica.
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// A generation published by another replica and not yet observed by the informer purges the cache first.
+	s.advance(generation)
+	generation = s.generation + 1
+	data := map[string]string{
+		imageInspectionCacheGenerationKey: strconv.FormatInt(generation, 10),
+	}
+	if cm == nil {
+		_, err = s.configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      utils.ImageInspectionCacheGenerationConfigMapName,
+				Namespace: utils.Namespace(),
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+	} else {
+		cm.Data = data
+		_, err = s.configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	s.log.V(1).Info("Bumped the image inspection cache generation", "generation", generation,
+		"invalidations", invalidations-s.publishedInvalidations)
+	s.generation = generation
+	s.publishedInvalidations = invalidations
+	return nil
+}
+
+// get returns the ConfigMap and the generation it holds. The ConfigMap is nil, and the generation is 0, if the
+// ConfigMap does not exist.
+func (s *CacheGenerationSyncer) get(ctx context.Context) (*corev1.ConfigMap, int64, error) {
+	cm, err := s.configMaps.Get(ctx, utils.ImageInspectionCacheGenerationConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	generation, err := parseGeneration(cm)
+	if err != nil {
+		return nil, 0, err
+	}
+	return cm, generation, nil
+}
+
+// parseGeneration returns the generation held by the ConfigMap, or 0 if it holds none.
+func parseGeneration(cm *corev1.ConfigMap) (int64, error) {
+	value, ok := cm.Data[imageInspectionCacheGenerationKey]
+	if !ok {
+		return 0, nil
+	}
+	generation, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid image inspection cache generation %q: %w", value, err)
+	}
+	return generation, nil
+}
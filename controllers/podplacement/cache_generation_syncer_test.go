@@ -0,0 +1,167 @@
+package podplacement
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// fakeCacheInvalidationsStore stands for the cache of a replica: it counts the invalidations and the purges.
+type fakeCacheInvalidationsStore struct {
+	mutex         sync.Mutex
+	invalidations uint64
+	purges        int
+}
+
+func (f *fakeCacheInvalidationsStore) CacheInvalidations() uint64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.invalidations
+}
+
+func (f *fakeCacheInvalidationsStore) PurgeCache() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.purges++
+}
+
+func (f *fakeCacheInvalidationsStore) invalidate() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.invalidations++
+}
+
+func (f *fakeCacheInvalidationsStore) purgesCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.purges
+}
+
+func TestCacheGenerationSyncer_Replicas(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx, cancel := context.WithCancel(log.IntoContext(context.Background(), log.Log))
+	configMaps := &fakeConfigMaps{configMaps: map[string]*corev1.ConfigMap{}}
+	leader := &fakeCacheInvalidationsStore{}
+	standby := &fakeCacheInvalidationsStore{}
+	var wg sync.WaitGroup
+	for _, store := range []*fakeCacheInvalidationsStore{leader, standby} {
+		syncer := &CacheGenerationSyncer{configMaps: configMaps, store: store, interval: 10 * time.Millisecond}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.Expect(syncer.Start(ctx)).To(Succeed())
+		}()
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	generation := func() string {
+		cm, err := configMaps.Get(ctx, utils.ImageInspectionCacheGenerationConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		return cm.Data[imageInspectionCacheGenerationKey]
+	}
+
+	// No invalidations: the replicas do not publish any generation, nor purge their cache
+	g.Consistently(generation, 100*time.Millisecond).Should(BeEmpty())
+
+	leader.invalidate()
+	g.Eventually(standby.purgesCount).Should(Equal(1), "the standby replica should purge its cache")
+	g.Expect(generation()).To(Equal("1"))
+	g.Consistently(leader.purgesCount, 100*time.Millisecond).Should(BeZero(),
+		"the replica bumping the generation should not purge its cache")
+
+	// The standby replica takes over the leadership and invalidates two entries
+	standby.invalidate()
+	standby.invalidate()
+	g.Eventually(leader.purgesCount).Should(Equal(1), "the former leader should purge its cache")
+	g.Expect(generation()).To(Equal("2"))
+	g.Consistently(standby.purgesCount, 100*time.Millisecond).Should(Equal(1))
+}
+
+func TestCacheGenerationSyncer_publish_PurgesArchitecturePredicates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	configMaps := &fakeConfigMaps{configMaps: map[string]*corev1.ConfigMap{}}
+	store := &fakeCacheInvalidationsStore{}
+	syncer := &CacheGenerationSyncer{configMaps: configMaps, store: store, log: logr.Discard()}
+	const key = "quay.io/example/app:latest"
+	predicate := corev1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: corev1.NodeSelectorOpIn,
+		Values: []string{utils.ArchitectureAmd64}}
+
+	// The local cache is invalidated
+	cacheArchitecturePredicate(key, predicate, []string{utils.ArchitectureAmd64})
+	store.invalidate()
+	g.Expect(syncer.publish(ctx)).To(Succeed())
+	g.Expect(architecturePredicateCache.Contains(key)).To(BeFalse(),
+		"the predicates should be purged when the local cache is invalidated")
+	g.Expect(store.purgesCount()).To(BeZero(), "the replica bumping the generation should not purge its cache")
+}
+
+func TestCacheGenerationSyncer_Watch(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx, cancel := context.WithCancel(log.IntoContext(context.Background(), log.Log))
+	configMaps := &fakeConfigMaps{configMaps: map[string]*corev1.ConfigMap{}}
+	store := &fakeCacheInvalidationsStore{}
+	// The interval is long enough for the generation to be only observed through the watch
+	syncer := &CacheGenerationSyncer{configMaps: configMaps, store: store, interval: time.Hour}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		g.Expect(syncer.Start(ctx)).To(Succeed())
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	const key = "quay.io/example/app:latest"
+	predicate := corev1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: corev1.NodeSelectorOpIn,
+		Values: []string{utils.ArchitectureAmd64}}
+	cacheArchitecturePredicate(key, predicate, []string{utils.ArchitectureAmd64})
+
+	// Another ConfigMap of the namespace is not watched
+	_, err := configMaps.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: utils.Namespace()},
+		Data:       map[string]string{imageInspectionCacheGenerationKey: "1"},
+	}, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Consistently(store.purgesCount, 100*time.Millisecond).Should(BeZero())
+	g.Expect(architecturePredicateCache.Contains(key)).To(BeTrue())
+
+	// Another replica bumps the generation
+	_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.ImageInspectionCacheGenerationConfigMapName,
+			Namespace: utils.Namespace(),
+		},
+		Data: map[string]string{imageInspectionCacheGenerationKey: "1"},
+	}, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(store.purgesCount).Should(Equal(1), "the image inspection cache should be purged")
+	g.Expect(architecturePredicateCache.Contains(key)).To(BeFalse(),
+		"the predicates should be purged along with the cache")
+
+	// The generation is bumped again
+	cacheArchitecturePredicate(key, predicate, []string{utils.ArchitectureAmd64})
+	cm, err := configMaps.Get(ctx, utils.ImageInspectionCacheGenerationConfigMapName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	cm.Data[imageInspectionCacheGenerationKey] = "2"
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(store.purgesCount).Should(Equal(2), "the image inspection cache should be purged")
+	g.Expect(architecturePredicateCache.Contains(key)).To(BeFalse(),
+		"the predicates should be purged along with the cache")
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientv1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// ClusterArchInventory watches the nodes of the cluster and keeps the set of their architectures, as set in their
+// kubernetes.io/arch label, so that the architectures of the pods can be trimmed to the ones of the cluster.
+type ClusterArchInventory struct {
+	clientSet kubernetes.Interface
+	mu        sync.RWMutex
+	// nodeArchitectures maps the names of the nodes to their architecture
+	nodeArchitectures map[string]string
+	// synced is true once the initial list of the nodes is processed
+	synced bool
+	log    logr.Logger
+}
+
+func NewClusterArchInventory(clientSet kubernetes.Interface) *ClusterArchInventory {
+	return &ClusterArchInventory{
+		clientSet:         clientSet,
+		nodeArchitectures: map[string]string{},
+	}
+}
+
+// NeedLeaderElection returns false: the standby replicas keep the inventory up to date to take over the leadership.
+func (i *ClusterArchInventory) NeedLeaderElection() bool {
+	return false
+}
+
+func (i *ClusterArchInventory) Start(ctx context.Context) error {
+	i.log = log.FromContext(ctx, "handler", "ClusterArchInventory", "kind", "Node [core/v1]")
+	i.log.Info("Starting the Cluster Architecture Inventory")
+	nodeInformer := clientv1.NewNodeInformer(i.clientSet, time.Hour, cache.Indexers{})
+	// Only the names and the labels of the nodes are needed: the rest of the objects is not kept in the informer cache.
+	if err := nodeInformer.SetTransform(trimNode); err != nil {
+		i.log.Error(err, "Error setting the transform of the nodes informer")
+		return err
+	}
+	if _, err := nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: i.onAddOrUpdate,
+		UpdateFunc: func(_, newObj interface{}) {
+			i.onAddOrUpdate(newObj)
+		},
+		DeleteFunc: i.onDelete,
+	}); err != nil {
+		i.log.Error(err, "Error registering the handler for the nodes")
+		return err
+	}
+	go func() {
+		if cache.WaitForCacheSync(ctx.Done(), nodeInformer.HasSynced) {
+			i.markSynced()
+		}
+	}()
+	nodeInformer.Run(ctx.Done())
+	i.log.Info("Stopping the Cluster Architecture Inventory")
+	return nil
+}
+
+// Architectures returns the set of the architectures of the nodes of the cluster. It returns nil if the inventory is
+// nil or the nodes are not listed yet.
+func (i *ClusterArchInventory) Architectures() sets.Set[string] {
+	if i == nil {
+		return nil
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if !i.synced {
+		return nil
+	}
+	architectures := sets.New[string]()
+	for _, architecture := range i.nodeArchitectures {
+		architectures.Insert(architecture)
+	}
+	return architectures
+}
+
+func (i *ClusterArchInventory) markSynced() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.synced = true
+	i.log.Info("The nodes are listed", "architectures", len(i.nodeArchitectures))
+}
+
+func (i *ClusterArchInventory) onAddOrUpdate(obj interface{}) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		i.log.Error(errors.New("unexpected type, expected v1.Node"), "unexpected type", "type", fmt.Sprintf("%T", obj))
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	architecture, ok := node.Labels[utils.ArchLabel]
+	if !ok {
+		// The node is not labeled yet, e.g., because it is joining the cluster
+		delete(i.nodeArchitectures, node.Name)
+		return
+	}
+	i.nodeArchitectures[node.Name] = architecture
+}
+
+func (i *ClusterArchInventory) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		i.log.Error(errors.New("unexpected type, expected v1.Node"), "unexpected type", "type", fmt.Sprintf("%T", obj))
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.nodeArchitectures, node.Name)
+}
+
+// trimNode is the transform of the nodes informer: it keeps only the name, the resource version and the labels of
+// the nodes.
+func trimNode(obj interface{}) (interface{}, error) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		// e.g., the cache.DeletedFinalStateUnknown tombstones
+		return obj, nil
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            node.Name,
+			ResourceVersion: node.ResourceVersion,
+			Labels:          node.Labels,
+		},
+	}, nil
+}
+
+// clusterArchitecturesOf returns the architectures of the nodes of the cluster the architectures of the pods are
+// trimmed to. It returns nil, i.e., the architectures are not trimmed, if the cppc does not enable the trimming or
+// the architectures of the nodes are not known.
+func clusterArchitecturesOf(cppc *v1beta1.ClusterPodPlacementConfig, inventory *ClusterArchInventory) sets.Set[string] {
+	if cppc == nil || !cppc.Spec.TrimToClusterArchitectures {
+		return nil
+	}
+	architectures := inventory.Architectures()
+	if architectures.Len() == 0 {
+		return nil
+	}
+	return architectures
+}
+
+// trimToClusterArchitectures returns the given architectures whose variant-less architecture is one of the
+// clusterArchitectures. The WebAssembly architecture is kept, as its images run on the nodes of any architecture.
+// The architectures are returned unchanged if clusterArchitectures is nil.
+func trimToClusterArchitectures(architectures []string, clusterArchitectures sets.Set[string]) []string {
+	if clusterArchitectures == nil {
+		return architectures
+	}
+	trimmed := make([]string, 0, len(architectures))
+	for _, architecture := range architectures {
+		if architecture == utils.ArchitectureWasm32 ||
+			clusterArchitectures.Has(utils.ArchitectureWithoutVariant(architecture)) {
+			trimmed = append(trimmed, architecture)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,247 @@
+package podplacement
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	. "github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func newArchNode(name, architecture string) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{},
+		},
+	}
+	if architecture != "" {
+		node.Labels[utils.ArchLabel] = architecture
+	}
+	return node
+}
+
+func TestClusterArchInventory_Build(t *testing.T) {
+	g := NewGomegaWithT(t)
+	inventory := NewClusterArchInventory(nil)
+	inventory.onAddOrUpdate(newArchNode("worker-0", utils.ArchitectureAmd64))
+	inventory.onAddOrUpdate(newArchNode("worker-1", utils.ArchitectureArm64))
+	inventory.onAddOrUpdate(newArchNode("worker-2", utils.ArchitectureArm64))
+	inventory.onAddOrUpdate(newArchNode("joining", ""))
+	g.Expect(inventory.Architectures()).To(BeNil(), "the architectures are unknown until the nodes are listed")
+	inventory.markSynced()
+	g.Expect(inventory.Architectures()).To(Equal(sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64)))
+}
+
+func TestClusterArchInventory_Update(t *testing.T) {
+	g := NewGomegaWithT(t)
+	inventory := NewClusterArchInventory(nil)
+	inventory.onAddOrUpdate(newArchNode("worker-0", utils.ArchitectureAmd64))
+	inventory.onAddOrUpdate(newArchNode("worker-1", utils.ArchitectureArm64))
+	inventory.markSynced()
+
+	inventory.onAddOrUpdate(newArchNode("worker-2", utils.ArchitectureS390x))
+	g.Expect(inventory.Architectures()).To(Equal(sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64,
+		utils.ArchitectureS390x)))
+
+	inventory.onDelete(newArchNode("worker-1", utils.ArchitectureArm64))
+	g.Expect(inventory.Architectures()).To(Equal(sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureS390x)))
+
+	inventory.onDelete(cache.DeletedFinalStateUnknown{
+		Key: "worker-2",
+		Obj: newArchNode("worker-2", utils.ArchitectureS390x),
+	})
+	g.Expect(inventory.Architectures()).To(Equal(sets.New[string](utils.ArchitectureAmd64)))
+
+	inventory.onAddOrUpdate(newArchNode("worker-0", ""))
+	g.Expect(inventory.Architectures()).To(BeEmpty())
+
+	inventory.onAddOrUpdate(newArchNode("worker-0", utils.ArchitecturePpc64le))
+	g.Expect(inventory.Architectures()).To(Equal(sets.New[string](utils.ArchitecturePpc64le)))
+
+	inventory.onAddOrUpdate(&corev1.Pod{})
+	inventory.onDelete(&corev1.Pod{})
+	g.Expect(inventory.Architectures()).To(Equal(sets.New[string](utils.ArchitecturePpc64le)))
+}
+
+func TestClusterArchInventory_NilArchitectures(t *testing.T) {
+	g := NewGomegaWithT(t)
+	var inventory *ClusterArchInventory
+	g.Expect(inventory.Architectures()).To(BeNil())
+}
+
+func TestTrimNode(t *testing.T) {
+	g := NewGomegaWithT(t)
+	node := newArchNode("worker-0", utils.ArchitectureArm64)
+	node.ResourceVersion = "42"
+	node.Status.Images = []corev1.ContainerImage{{Names: []string{fake.MultiArchImage}}}
+	trimmed, err := trimNode(node)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(trimmed).To(Equal(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "worker-0",
+			ResourceVersion: "42",
+			Labels:          map[string]string{utils.ArchLabel: utils.ArchitectureArm64},
+		},
+	}))
+	tombstone := cache.DeletedFinalStateUnknown{Key: "worker-0"}
+	trimmed, err = trimNode(tombstone)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(trimmed).To(Equal(tombstone))
+}
+
+func TestClusterArchitecturesOf(t *testing.T) {
+	synced := NewClusterArchInventory(nil)
+	synced.onAddOrUpdate(newArchNode("worker-0", utils.ArchitectureAmd64))
+	synced.markSynced()
+	empty := NewClusterArchInventory(nil)
+	empty.markSynced()
+	tests := []struct {
+		name      string
+		trim      bool
+		nilCPPC   bool
+		inventory *ClusterArchInventory
+		want      sets.Set[string]
+	}{
+		{
+			name:      "no ClusterPodPlacementConfig",
+			nilCPPC:   true,
+			inventory: synced,
+		},
+		{
+			name:      "trimming disabled",
+			inventory: synced,
+		},
+		{
+			name:      "trimming enabled",
+			trim:      true,
+			inventory: synced,
+			want:      sets.New[string](utils.ArchitectureAmd64),
+		},
+		{
+			name:      "trimming enabled, nodes not listed yet",
+			trim:      true,
+			inventory: NewClusterArchInventory(nil),
+		},
+		{
+			name:      "trimming enabled, no labeled nodes",
+			trim:      true,
+			inventory: empty,
+		},
+		{
+			name: "trimming enabled, no inventory",
+			trim: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cppc := NewClusterPodPlacementConfig().WithTrimToClusterArchitectures(tt.trim).Build()
+			if tt.nilCPPC {
+				cppc = nil
+			}
+			g.Expect(clusterArchitecturesOf(cppc, tt.inventory)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestTrimToClusterArchitectures(t *testing.T) {
+	tests := []struct {
+		name                 string
+		architectures        []string
+		clusterArchitectures sets.Set[string]
+		want                 []string
+	}{
+		{
+			name:          "no cluster architectures",
+			architectures: []string{utils.ArchitectureAmd64, utils.ArchitectureS390x},
+			want:          []string{utils.ArchitectureAmd64, utils.ArchitectureS390x},
+		},
+		{
+			name:                 "architectures of the cluster",
+			architectures:        []string{utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitectureS390x},
+			clusterArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
+			want:                 []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+		},
+		{
+			name:                 "no architectures of the cluster",
+			architectures:        []string{utils.ArchitectureS390x},
+			clusterArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
+			want:                 []string{},
+		},
+		{
+			name:                 "variants",
+			architectures:        []string{utils.ArchitectureArmV7, utils.ArchitecturePpc64le},
+			clusterArchitectures: sets.New[string](utils.ArchitectureWithoutVariant(utils.ArchitectureArmV7)),
+			want:                 []string{utils.ArchitectureArmV7},
+		},
+		{
+			name:                 "wasm",
+			architectures:        []string{utils.ArchitectureWasm32},
+			clusterArchitectures: sets.New[string](utils.ArchitectureAmd64),
+			want:                 []string{utils.ArchitectureWasm32},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(trimToClusterArchitectures(tt.architectures, tt.clusterArchitectures)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPod_SetNodeAffinityArchRequirement_TrimToClusterArchitectures(t *testing.T) {
+	tests := []struct {
+		name                 string
+		pod                  *corev1.Pod
+		clusterArchitectures sets.Set[string]
+		wantValues           []string
+	}{
+		{
+			name:                 "image supporting more architectures than the cluster",
+			pod:                  NewPod().WithContainersImages(fake.MultiArchImage2).Build(),
+			clusterArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureS390x),
+			wantValues:           []string{utils.ArchitectureAmd64, utils.ArchitectureS390x},
+		},
+		{
+			name:                 "image supporting none of the architectures of the cluster",
+			pod:                  NewPod().WithContainersImages(fake.SingleArchArm64Image).Build(),
+			clusterArchitectures: sets.New[string](utils.ArchitectureAmd64),
+		},
+		{
+			name:       "architectures of the cluster unknown",
+			pod:        NewPod().WithContainersImages(fake.MultiArchImage2).Build(),
+			wantValues: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitecturePpc64le, utils.ArchitectureS390x},
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{
+				Pod:                  *tt.pod,
+				ctx:                  ctx,
+				imageInspector:       fake.FacadeSingleton(),
+				clusterArchitectures: tt.clusterArchitectures,
+			}
+			_, err := pod.SetNodeAffinityArchRequirement(nil)
+			g.Expect(err).NotTo(HaveOccurred())
+			terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			g.Expect(terms).To(HaveLen(1))
+			g.Expect(terms[0].MatchExpressions).To(HaveLen(1))
+			if tt.wantValues == nil {
+				g.Expect(terms[0].MatchExpressions[0].Key).To(Equal(utils.NoSupportedArchLabel))
+				return
+			}
+			g.Expect(terms[0].MatchExpressions[0].Values).To(Equal(tt.wantValues))
+		})
+	}
+}
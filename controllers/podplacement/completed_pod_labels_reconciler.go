@@ -0,0 +1,142 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+const (
+	// completedPodLabelsCleanupInterval is the period of the checks for the completed pods with architecture labels.
+	completedPodLabelsCleanupInterval = 10 * time.Minute
+	// completedPodLabelsCleanupQPS and completedPodLabelsCleanupBurst rate-limit the updates of the pods to clean up.
+	completedPodLabelsCleanupQPS   = 5
+	completedPodLabelsCleanupBurst = 10
+)
+
+// CompletedPodLabelsReconciler removes the labels set by the operator from the pods in the Succeeded or Failed
+// phase. They are not scheduled anymore, and their labels would otherwise pollute the label queries indefinitely.
+type CompletedPodLabelsReconciler struct {
+	pods corev1client.PodsGetter
+	// limiter rate-limits the updates of the pods, so that the cleanup of many pods does not overwhelm the API server
+	limiter  flowcontrol.RateLimiter
+	interval time.Duration
+	log      logr.Logger
+}
+
+func NewCompletedPodLabelsReconciler(clientSet kubernetes.Interface) *CompletedPodLabelsReconciler {
+	return &CompletedPodLabelsReconciler{
+		pods:     clientSet.CoreV1(),
+		limiter:  flowcontrol.NewTokenBucketRateLimiter(completedPodLabelsCleanupQPS, completedPodLabelsCleanupBurst),
+		interval: completedPodLabelsCleanupInterval,
+	}
+}
+
+func (r *CompletedPodLabelsReconciler) Start(ctx context.Context) error {
+	r.log = log.FromContext(ctx, "handler", "CompletedPodLabelsReconciler", "kind", "Pod [core/v1]")
+	r.log.Info("Starting the Completed Pod Labels Reconciler")
+	defer r.limiter.Stop()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.reconcile(ctx); err != nil {
+			r.log.Error(err, "Unable to clean up the architecture labels of the completed pods")
+		}
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping the Completed Pod Labels Reconciler")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile cleans up the architecture labels of the completed pods with the utils.NodeAffinityLabel set. As the
+// label is removed too, the pods already cleaned up are not listed again.
+func (r *CompletedPodLabelsReconciler) reconcile(ctx context.Context) error {
+	requirement, err := labels.NewRequirement(utils.NodeAffinityLabel, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	podList, err := r.pods.Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.NewSelector().Add(*requirement).String(),
+		FieldSelector: fields.AndSelectors(
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodPending)),
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodRunning)),
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodUnknown)),
+		).String(),
+	})
+	if err != nil {
+		return err
+	}
+	for i := range podList.Items {
+		pod := &Pod{
+			Pod: podList.Items[i],
+			ctx: ctx,
+		}
+		if !pod.isCompleted() {
+			continue
+		}
+		if err := r.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		log := r.log.WithValues("namespace", pod.Namespace, "name", pod.Name)
+		if err := r.CleanupArchitectureLabels(pod); err != nil {
+			log.Error(err, "Unable to clean up the architecture labels of the completed pod")
+			continue
+		}
+		log.V(1).Info("Cleaned up the architecture labels of the completed pod")
+	}
+	return nil
+}
+
+// CleanupArchitectureLabels removes the labels with the utils.ArchLabelPrefix prefix from the pod, if it is in the
+// Succeeded or Failed phase. The other pods, and the completed pods without such labels, are left untouched.
+func (r *CompletedPodLabelsReconciler) CleanupArchitectureLabels(pod *Pod) error {
+	if !pod.isCompleted() {
+		return nil
+	}
+	if !pod.RemoveAllOperatorManagedLabels() {
+		return nil
+	}
+	_, err := r.pods.Pods(pod.Namespace).Update(pod.ctx, &pod.Pod, metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// isCompleted returns true if the pod is in the Succeeded or Failed phase.
+func (pod *Pod) isCompleted() bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
@@ -0,0 +1,89 @@
+package podplacement
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func TestCompletedPodLabelsReconciler_Reconcile(t *testing.T) {
+	newPod := func(name string, phase v1.PodPhase) *v1.Pod {
+		pod := builder.NewPod().WithLabels(
+			utils.NodeAffinityLabel, utils.NodeAffinityLabelValueSet,
+			utils.SchedulingGateLabel, utils.SchedulingGateLabelValueRemoved,
+			utils.MultiArchLabel, "",
+			utils.ArchLabelValue(utils.ArchitectureAmd64), "",
+			utils.ArchLabelValue(utils.ArchitectureArm64), "",
+			"app", "test").Build()
+		pod.Name = name
+		pod.Status.Phase = phase
+		return pod
+	}
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		wantLabels map[string]string
+	}{
+		{
+			name:       "succeeded pod",
+			pod:        newPod("succeeded", v1.PodSucceeded),
+			wantLabels: map[string]string{"app": "test"},
+		},
+		{
+			name:       "failed pod",
+			pod:        newPod("failed", v1.PodFailed),
+			wantLabels: map[string]string{"app": "test"},
+		},
+		{
+			name:       "running pod",
+			pod:        newPod("running", v1.PodRunning),
+			wantLabels: newPod("running", v1.PodRunning).Labels,
+		},
+		{
+			name:       "pending pod",
+			pod:        newPod("pending", v1.PodPending),
+			wantLabels: newPod("pending", v1.PodPending).Labels,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pods := &fakePods{pods: map[string]*v1.Pod{tt.pod.Name: tt.pod}}
+			r := &CompletedPodLabelsReconciler{
+				pods:    pods,
+				limiter: flowcontrol.NewFakeAlwaysRateLimiter(),
+				log:     logr.Discard(),
+			}
+			// The reconciler is idempotent
+			g.Expect(r.reconcile(ctx)).To(Succeed())
+			g.Expect(r.reconcile(ctx)).To(Succeed())
+			g.Expect(pods.pods[tt.pod.Name].Labels).To(Equal(tt.wantLabels))
+		})
+	}
+}
+
+func TestCompletedPodLabelsReconciler_CleanupArchitectureLabels(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pod := builder.NewPod().WithLabels(utils.NoSupportedArchLabel, "", "app", "test").Build()
+	pod.Name = "succeeded"
+	pod.Status.Phase = v1.PodSucceeded
+	pods := &fakePods{pods: map[string]*v1.Pod{}}
+	r := &CompletedPodLabelsReconciler{pods: pods, log: logr.Discard()}
+	g.Expect(r.CleanupArchitectureLabels(&Pod{Pod: *pod.DeepCopy(), ctx: ctx})).To(Succeed())
+	g.Expect(pods.pods).To(HaveKey(pod.Name))
+	g.Expect(pods.pods[pod.Name].Labels).To(Equal(map[string]string{"app": "test"}))
+
+	// A completed pod without architecture labels is not updated
+	pods.pods = map[string]*v1.Pod{}
+	pod.Labels = map[string]string{"app": "test"}
+	g.Expect(r.CleanupArchitectureLabels(&Pod{Pod: *pod.DeepCopy(), ctx: ctx})).To(Succeed())
+	g.Expect(pods.pods).To(BeEmpty())
+}
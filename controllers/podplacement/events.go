@@ -2,6 +2,8 @@ package podplacement
 
 import "github.com/openshift/multiarch-tuning-operator/pkg/utils"
 
+// The reasons and messages of the events published on the pods, shown by kubectl describe pod.
+// The reasons must be unique CamelCase strings, see TestEventReasons.
 const (
 	ArchitecturePredicatesConflict                = "ArchAwarePredicatesConflict"
 	ImageArchitectureInspectionError              = "ArchAwareInspectionError"
@@ -10,11 +12,20 @@ const (
 	ArchitectureAwareSchedulingGateAdded          = "ArchAwareSchedGateAdded"
 	ArchitectureAwareSchedulingGateRemovalFailure = "ArchAwareSchedGateRemovalFailed"
 	ArchitectureAwareSchedulingGateRemovalSuccess = "ArchAwareSchedGateRemovalSuccess"
+	ArchitectureAwareSchedulingGateForcedRemoval  = "ArchAwareSchedGateForcedRemoval"
 	NoSupportedArchitecturesFound                 = "NoSupportedArchitecturesFound"
+	ArchitectureOverrideInvalid                   = "ArchAwareOverrideInvalid"
+	ImageInspectionTimeout                        = "ArchAwareInspectionTimeout"
+	ArchitectureAwareStaleAffinityRepaired        = "ArchAwareStaleAffinityRepaired"
+	ArchitectureAwareStaleAffinityDetected        = "ArchAwareStaleAffinityDetected"
+	ArchitectureMatchFieldConflict                = "ArchAwareMatchFieldConflict"
+	ArchitectureAwareGateInjectionDisabled        = "ArchAwareGateInjectionDisabled"
+	ArchitecturePreferenceInvalid                 = "ArchAwarePreferenceInvalid"
 
-	SchedulingGateAddedMsg                   = "Successfully gated with the " + utils.SchedulingGateName + " scheduling gate"
-	SchedulingGateRemovalSuccessMsg          = "Successfully removed the " + utils.SchedulingGateName + " scheduling gate"
-	SchedulingGateRemovalFailureMsg          = "Failed to remove the scheduling gate \"" + utils.SchedulingGateName + "\""
+	// The scheduling gate messages are formatted with the name of the scheduling gate, see utils.GetSchedulingGateName.
+	SchedulingGateAddedMsg                   = "Successfully gated with the %s scheduling gate"
+	SchedulingGateRemovalSuccessMsg          = "Successfully removed the %s scheduling gate"
+	SchedulingGateRemovalFailureMsg          = "Failed to remove the scheduling gate %q"
 	ArchitecturePredicatesConflictMsg        = "All the scheduling predicates already include architecture-specific constraints"
 	ArchitecturePredicateSetupMsg            = "Set the supported architectures to "
 	ArchitecturePreferredPredicateSetupMsg   = "Set the architecture preferences in the nodeAffinity"
@@ -23,4 +34,14 @@ const (
 	NoSupportedArchitecturesFoundMsg         = "Pod cannot be scheduled due to incompatible image architectures; container images have no supported architectures in common"
 	ArchitectureAwareGatedPodIgnoredMsg      = "The gated pod has been modified and is no longer eligible for architecture-aware scheduling"
 	ImageInspectionErrorMaxRetriesMsg        = "Failed to retrieve the supported architectures after multiple retries"
+	ArchitectureOverrideInvalidMsg           = "Ignoring the " + utils.ArchitectureOverrideAnnotation + " annotation as it includes unsupported architectures: "
+	SchedulingGateForcedRemovalMsg           = "Forcibly removed the %s scheduling gate as the pod was gated for longer than %s"
+	ImageInspectionTimeoutBlockMsg           = "The inspection of the images timed out; the pod stays gated and the inspection will be retried"
+	ImageInspectionTimeoutAllowAllMsg        = "The inspection of the images timed out; falling back to all the supported architectures: "
+	ImageInspectionTimeoutAllowConfiguredMsg = "The inspection of the images timed out; falling back to the configured architectures: "
+	StaleAffinityRepairedMsg                 = "Added the architecture requirement computed by the current version of the operator to the one set by a previous version"
+	StaleAffinityDetectedMsg                 = "The architecture requirement was set by a previous version of the operator and cannot be updated; recreate the pod to update it"
+	ArchitectureMatchFieldConflictMsg        = "Not setting the architecture requirement as it conflicts with the matchFields of the node affinity: "
+	GateInjectionDisabledMsg                 = "Removed the %s scheduling gate as the gate injection is disabled in the ClusterPodPlacementConfig"
+	ArchitecturePreferenceInvalidMsg         = "Ignoring the " + utils.PreferredArchitectureAnnotation + " annotation: "
 )
@@ -0,0 +1,130 @@
+package podplacement
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+
+	. "github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+)
+
+// eventReasonRegexp matches the CamelCase reasons shown by kubectl describe; the API server limits them to 128
+// characters.
+var eventReasonRegexp = regexp.MustCompile(`^[A-Z][A-Za-z]{0,127}$`)
+
+func TestEventReasons(t *testing.T) {
+	reasons := map[string]string{
+		"ArchitecturePredicatesConflict":                ArchitecturePredicatesConflict,
+		"ImageArchitectureInspectionError":              ImageArchitectureInspectionError,
+		"ArchitectureAwareNodeAffinitySet":              ArchitectureAwareNodeAffinitySet,
+		"ArchitectureAwareGatedPodIgnored":              ArchitectureAwareGatedPodIgnored,
+		"ArchitectureAwareSchedulingGateAdded":          ArchitectureAwareSchedulingGateAdded,
+		"ArchitectureAwareSchedulingGateRemovalFailure": ArchitectureAwareSchedulingGateRemovalFailure,
+		"ArchitectureAwareSchedulingGateRemovalSuccess": ArchitectureAwareSchedulingGateRemovalSuccess,
+		"ArchitectureAwareSchedulingGateForcedRemoval":  ArchitectureAwareSchedulingGateForcedRemoval,
+		"NoSupportedArchitecturesFound":                 NoSupportedArchitecturesFound,
+		"ArchitectureOverrideInvalid":                   ArchitectureOverrideInvalid,
+		"ImageInspectionTimeout":                        ImageInspectionTimeout,
+		"ArchitectureAwareStaleAffinityRepaired":        ArchitectureAwareStaleAffinityRepaired,
+		"ArchitectureMatchFieldConflict":                ArchitectureMatchFieldConflict,
+		"ArchitectureAwareGateInjectionDisabled":        ArchitectureAwareGateInjectionDisabled,
+		"ArchitecturePreferenceInvalid":                 ArchitecturePreferenceInvalid,
+	}
+	seen := map[string]string{}
+	for name, reason := range reasons {
+		t.Run(name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(reason).To(MatchRegexp(eventReasonRegexp.String()), "the reason is not a valid CamelCase reason")
+			g.Expect(seen).NotTo(HaveKey(reason), "the reason is shared with %s", seen[reason])
+			seen[reason] = name
+		})
+	}
+}
+
+func TestPod_publishEvent(t *testing.T) {
+	gate := utils.GetSchedulingGateName()
+	tests := []struct {
+		name      string
+		eventType string
+		reason    string
+		message   string
+		want      string
+	}{
+		{
+			name:      "scheduling gate added",
+			eventType: corev1.EventTypeNormal,
+			reason:    ArchitectureAwareSchedulingGateAdded,
+			message:   fmt.Sprintf(SchedulingGateAddedMsg, gate),
+			want:      "Normal ArchAwareSchedGateAdded Successfully gated with the " + gate + " scheduling gate",
+		},
+		{
+			name:      "scheduling gate removed",
+			eventType: corev1.EventTypeNormal,
+			reason:    ArchitectureAwareSchedulingGateRemovalSuccess,
+			message:   fmt.Sprintf(SchedulingGateRemovalSuccessMsg, gate),
+			want:      "Normal ArchAwareSchedGateRemovalSuccess Successfully removed the " + gate + " scheduling gate",
+		},
+		{
+			name:      "scheduling gate removal failed",
+			eventType: corev1.EventTypeWarning,
+			reason:    ArchitectureAwareSchedulingGateRemovalFailure,
+			message:   fmt.Sprintf(SchedulingGateRemovalFailureMsg, gate),
+			want:      "Warning ArchAwareSchedGateRemovalFailed Failed to remove the scheduling gate \"" + gate + "\"",
+		},
+		{
+			name:      "scheduling gate forcibly removed",
+			eventType: corev1.EventTypeWarning,
+			reason:    ArchitectureAwareSchedulingGateForcedRemoval,
+			message:   fmt.Sprintf(SchedulingGateForcedRemovalMsg, gate, time.Hour),
+			want: "Warning ArchAwareSchedGateForcedRemoval Forcibly removed the " + gate +
+				" scheduling gate as the pod was gated for longer than 1h0m0s",
+		},
+		{
+			name:      "node affinity set",
+			eventType: corev1.EventTypeNormal,
+			reason:    ArchitectureAwareNodeAffinitySet,
+			message:   ArchitecturePredicateSetupMsg + utils.ArchitectureAmd64,
+			want:      "Normal ArchAwarePredicateSet Set the supported architectures to amd64",
+		},
+		{
+			name:      "image inspection error",
+			eventType: corev1.EventTypeWarning,
+			reason:    ImageArchitectureInspectionError,
+			message:   ImageArchitectureInspectionErrorMsg + "unauthorized",
+			want:      "Warning ArchAwareInspectionError Failed to retrieve the supported architectures: unauthorized",
+		},
+		{
+			name:      "no supported architectures",
+			eventType: corev1.EventTypeNormal,
+			reason:    NoSupportedArchitecturesFound,
+			message:   NoSupportedArchitecturesFoundMsg,
+			want: "Normal NoSupportedArchitecturesFound Pod cannot be scheduled due to incompatible image " +
+				"architectures; container images have no supported architectures in common",
+		},
+		{
+			name:      "invalid architecture override",
+			eventType: corev1.EventTypeWarning,
+			reason:    ArchitectureOverrideInvalid,
+			message:   ArchitectureOverrideInvalidMsg + "foo",
+			want: "Warning ArchAwareOverrideInvalid Ignoring the " + utils.ArchitectureOverrideAnnotation +
+				" annotation as it includes unsupported architectures: foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			recorder := record.NewFakeRecorder(1)
+			pod := &Pod{Pod: *NewPod().Build(), recorder: recorder}
+			pod.publishEvent(tt.eventType, tt.reason, tt.message)
+			g.Expect(recorder.Events).To(Receive(Equal(tt.want)))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// gateInjectionKillSwitchInterval is the period of the checks of the DisableGateInjection switch.
+const gateInjectionKillSwitchInterval = 10 * time.Second
+
+// GateInjectionKillSwitchReconciler removes the scheduling gate from all the gated pods when the DisableGateInjection
+// switch of the ClusterPodPlacementConfig is turned on, so that they are scheduled without waiting for the inspection
+// of their images. The webhook stops gating the new pods at the same time.
+type GateInjectionKillSwitchReconciler struct {
+	pods     corev1client.PodsGetter
+	recorder record.EventRecorder
+	// config returns the ClusterPodPlacementConfig, or nil if it does not exist
+	config   func() *v1beta1.ClusterPodPlacementConfig
+	interval time.Duration
+	// disabled is true once the gates are removed from the pods gated when the switch was turned on
+	disabled bool
+	log      logr.Logger
+}
+
+func NewGateInjectionKillSwitchReconciler(clientSet kubernetes.Interface,
+	recorder record.EventRecorder) *GateInjectionKillSwitchReconciler {
+	return &GateInjectionKillSwitchReconciler{
+		pods:     clientSet.CoreV1(),
+		recorder: recorder,
+		config:   clusterpodplacementconfig.GetClusterPodPlacementConfig,
+		interval: gateInjectionKillSwitchInterval,
+	}
+}
+
+func (r *GateInjectionKillSwitchReconciler) Start(ctx context.Context) error {
+	r.log = log.FromContext(ctx, "handler", "GateInjectionKillSwitchReconciler", "kind", "Pod [core/v1]")
+	r.log.Info("Starting the Gate Injection Kill Switch Reconciler")
+	metrics.InitPodPlacementControllerMetrics()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping the Gate Injection Kill Switch Reconciler")
+			return nil
+		case <-ticker.C:
+			if err := r.reconcile(ctx); err != nil {
+				r.log.Error(err, "Unable to remove the scheduling gate from the gated pods")
+			}
+		}
+	}
+}
+
+// reconcile removes the scheduling gate from the gated pods when the switch transitions from off to on. The
+// transition is retried at the next period if any of the pods fails to be updated.
+func (r *GateInjectionKillSwitchReconciler) reconcile(ctx context.Context) error {
+	disabled := gateInjectionDisabled(r.config())
+	if !disabled || r.disabled {
+		r.disabled = disabled
+		return nil
+	}
+	r.log.Info("The gate injection is disabled: removing the scheduling gate from the gated pods")
+	podList, err := r.pods.Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			utils.SchedulingGateLabel: utils.SchedulingGateLabelValueGated,
+		}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	failures := 0
+	for i := range podList.Items {
+		pod := &Pod{
+			Pod:      podList.Items[i],
+			ctx:      ctx,
+			recorder: r.recorder,
+		}
+		if !pod.HasSchedulingGate() {
+			continue
+		}
+		log := r.log.WithValues("namespace", pod.Namespace, "name", pod.Name)
+		pod.RemoveSchedulingGate()
+		if _, err := r.pods.Pods(pod.Namespace).Update(ctx, &pod.Pod, metav1.UpdateOptions{}); err != nil {
+			log.Error(err, "Unable to remove the scheduling gate from the pod")
+			failures++
+			continue
+		}
+		log.V(1).Info("Removed the scheduling gate from the pod as the gate injection is disabled")
+		pod.publishEvent(corev1.EventTypeWarning, ArchitectureAwareGateInjectionDisabled,
+			fmt.Sprintf(GateInjectionDisabledMsg, utils.GetSchedulingGateName()))
+		metrics.GatedPodsGauge.Dec()
+	}
+	if failures > 0 {
+		return fmt.Errorf("unable to remove the scheduling gate from %d pods", failures)
+	}
+	r.disabled = true
+	return nil
+}
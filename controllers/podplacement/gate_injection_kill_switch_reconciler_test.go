@@ -0,0 +1,95 @@
+package podplacement
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// failingPods fails the updates of the pods while err is set.
+type failingPods struct {
+	*fakePods
+	err error
+}
+
+func (f *failingPods) Pods(_ string) corev1client.PodInterface {
+	return f
+}
+
+func (f *failingPods) Update(ctx context.Context, pod *v1.Pod, opts metav1.UpdateOptions) (*v1.Pod, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.fakePods.Update(ctx, pod, opts)
+}
+
+func TestGateInjectionKillSwitchReconciler_Reconcile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitPodPlacementControllerMetrics()
+	newGatedPod := func(name string) *v1.Pod {
+		pod := builder.NewPod().WithSchedulingGates(utils.SchedulingGateName).
+			WithLabels(utils.SchedulingGateLabel, utils.SchedulingGateLabelValueGated).Build()
+		pod.Name = name
+		return pod
+	}
+	pods := &failingPods{fakePods: &fakePods{pods: map[string]*v1.Pod{
+		"gated": newGatedPod("gated"),
+	}}}
+	cppc := builder.NewClusterPodPlacementConfig().Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &GateInjectionKillSwitchReconciler{
+		pods:     pods,
+		recorder: recorder,
+		config:   func() *v1beta1.ClusterPodPlacementConfig { return cppc },
+		log:      logr.Discard(),
+	}
+
+	// The gates are not removed while the gate injection is enabled
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+	g.Expect(pods.pods["gated"].Spec.SchedulingGates).To(HaveLen(1))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// The transition is retried if a pod fails to be updated
+	cppc.Spec.DisableGateInjection = true
+	pods.err = errors.New("conflict")
+	g.Expect(reconciler.reconcile(context.TODO())).NotTo(Succeed())
+	g.Expect(pods.pods["gated"].Spec.SchedulingGates).To(HaveLen(1))
+	pods.err = nil
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+	g.Expect(pods.pods["gated"].Spec.SchedulingGates).To(BeEmpty())
+	g.Expect(pods.pods["gated"].Labels).To(HaveKeyWithValue(utils.SchedulingGateLabel,
+		utils.SchedulingGateLabelValueRemoved))
+	g.Expect(recorder.Events).To(Receive(And(HavePrefix(v1.EventTypeWarning),
+		ContainSubstring(ArchitectureAwareGateInjectionDisabled))))
+
+	// The pods gated after the transition, e.g., by a webhook not aware of it yet, are left to the pod reconciler
+	pods.pods["late"] = newGatedPod("late")
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+	g.Expect(pods.pods["late"].Spec.SchedulingGates).To(HaveLen(1))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// Turning the switch off and on again triggers a new transition
+	cppc.Spec.DisableGateInjection = false
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+	cppc.Spec.DisableGateInjection = true
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+	g.Expect(pods.pods["late"].Spec.SchedulingGates).To(BeEmpty())
+
+	// No ClusterPodPlacementConfig
+	cppc = nil
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+}
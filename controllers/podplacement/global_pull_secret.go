@@ -25,8 +25,11 @@ import (
 	"github.com/go-logr/logr"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientv1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -54,6 +57,10 @@ func (s *GlobalPullSecretSyncer) Start(ctx context.Context) (err error) {
 		"namespace", s.namespace, "name", s.name)
 	s.log.Info("Starting System Config Syncer")
 	clientSet := s.clientSet
+	if err := checkGlobalPullSecret(ctx, clientSet.CoreV1().Secrets(s.namespace), s.namespace, s.name); err != nil {
+		// The syncer keeps watching the Secret: it is used as soon as it is created or fixed
+		s.log.Error(err, "The global pull secret is not usable")
+	}
 	// Watch the Secret that contains the global pull secret and Sync the inspector
 	globalPullSecretInformer := clientv1.NewSecretInformer(clientSet, s.namespace, time.Hour, cache.Indexers{})
 
@@ -74,6 +81,24 @@ func (s *GlobalPullSecretSyncer) Start(ctx context.Context) (err error) {
 	return nil
 }
 
+// checkGlobalPullSecret returns an error if the global pull secret does not exist or does not hold registry
+// credentials. The error messages tell that the images are inspected with the pods' pull secrets only.
+func checkGlobalPullSecret(ctx context.Context, secrets corev1client.SecretInterface, namespace, name string) error {
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("the global pull secret %s/%s does not exist, "+
+			"the images are inspected with the pods' pull secrets only", namespace, name)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get the global pull secret %s/%s: %w", namespace, name, err)
+	}
+	if _, err := utils.ExtractAuthFromSecret(secret); err != nil {
+		return fmt.Errorf("the global pull secret %s/%s does not hold registry credentials (%w), "+
+			"the images are inspected with the pods' pull secrets only", namespace, name, err)
+	}
+	return nil
+}
+
 func (s *GlobalPullSecretSyncer) onAddOrUpdate(obj interface{}) {
 	secret, ok := obj.(*corev1.Secret)
 	if !ok {
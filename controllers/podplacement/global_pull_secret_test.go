@@ -0,0 +1,74 @@
+package podplacement
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// fakeSecrets stores the Secrets in memory. Only the Get method is implemented.
+type fakeSecrets struct {
+	corev1client.SecretInterface
+	secrets map[string]*corev1.Secret
+}
+
+func (f *fakeSecrets) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.Secret, error) {
+	secret, ok := f.secrets[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	return secret.DeepCopy(), nil
+}
+
+func TestCheckGlobalPullSecret(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    *corev1.Secret
+		wantError string
+	}{
+		{
+			name: "valid global pull secret",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`),
+				},
+			},
+		},
+		{
+			name:      "missing global pull secret",
+			wantError: "the global pull secret central/registry-credentials does not exist, the images are inspected with the pods' pull secrets only",
+		},
+		{
+			name: "global pull secret of an unexpected type",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials"},
+				Type:       corev1.SecretTypeOpaque,
+			},
+			wantError: "the global pull secret central/registry-credentials does not hold registry credentials (unknown secret type), the images are inspected with the pods' pull secrets only",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			secrets := &fakeSecrets{secrets: map[string]*corev1.Secret{}}
+			if tt.secret != nil {
+				secrets.secrets[tt.secret.Name] = tt.secret
+			}
+			err := checkGlobalPullSecret(context.TODO(), secrets, "central", "registry-credentials")
+			if tt.wantError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tt.wantError))
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+const (
+	imageInspectionHealthReportInterval = time.Minute
+	imageInspectionHealthWindow         = 10 * time.Minute
+)
+
+// inspectionCountsSample is a sample of the cumulative counts of the image inspections.
+type inspectionCountsSample struct {
+	time        time.Time
+	inspections uint64
+	failures    uint64
+}
+
+// ImageInspectionHealthReporter periodically reports the number of image inspections, and of the failed ones, of the
+// last rolling window in a ConfigMap. The operator derives the CacheHealthy condition of the ClusterPodPlacementConfig
+// from it. It only runs in the leader, as the standby replicas do not inspect images.
+type ImageInspectionHealthReporter struct {
+	configMaps corev1client.ConfigMapInterface
+	// counts returns the cumulative counts of the image inspections and of the failed ones
+	counts   func() (inspections, failures uint64)
+	clock    clock.PassiveClock
+	interval time.Duration
+	window   time.Duration
+	// samples are the samples of the counts in the window, from the oldest to the newest
+	samples []inspectionCountsSample
+	log     logr.Logger
+}
+
+func NewImageInspectionHealthReporter(clientSet kubernetes.Interface) *ImageInspectionHealthReporter {
+	return &ImageInspectionHealthReporter{
+		configMaps: clientSet.CoreV1().ConfigMaps(utils.Namespace()),
+		counts:     metrics.ImageInspectionCounts,
+		clock:      clock.RealClock{},
+		interval:   imageInspectionHealthReportInterval,
+		window:     imageInspectionHealthWindow,
+	}
+}
+
+func (r *ImageInspectionHealthReporter) Start(ctx context.Context) error {
+	r.log = log.FromContext(ctx, "handler", "ImageInspectionHealthReporter", "kind", "ConfigMap [core/v1]",
+		"namespace", utils.Namespace(), "name", utils.ImageInspectionHealthConfigMapName)
+	r.log.Info("Starting the Image Inspection Health Reporter")
+	metrics.InitPodPlacementControllerMetrics()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.report(ctx); err != nil {
+			r.log.Error(err, "Unable to report the image inspection health")
+		}
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping the Image Inspection Health Reporter")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// report samples the counts of the image inspections and stores their increase over the window in the ConfigMap,
+// creating it if it does not exist.
+func (r *ImageInspectionHealthReporter) report(ctx context.Context) error {
+	now := r.clock.Now()
+	inspections, failures := r.counts()
+	r.samples = append(r.samples, inspectionCountsSample{time: now, inspections: inspections, failures: failures})
+	// Drop the samples older than the window, keeping the newest of them as the baseline
+	for len(r.samples) > 1 && !r.samples[1].time.After(now.Add(-r.window)) {
+		r.samples = r.samples[1:]
+	}
+	baseline := r.samples[0]
+	data := map[string]string{
+		utils.ImageInspectionHealthInspectionsKey: strconv.FormatUint(inspections-baseline.inspections, 10),
+		utils.ImageInspectionHealthFailuresKey:    strconv.FormatUint(failures-baseline.failures, 10),
+		utils.ImageInspectionHealthWindowKey:      now.Sub(baseline.time).Round(time.Second).String(),
+		utils.ImageInspectionHealthUpdateTimeKey:  now.UTC().Format(time.RFC3339),
+	}
+	cm, err := r.configMaps.Get(ctx, utils.ImageInspectionHealthConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = r.configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      utils.ImageInspectionHealthConfigMapName,
+				Namespace: utils.Namespace(),
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+	} else if err == nil {
+		cm.Data = data
+		_, err = r.configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	r.log.V(3).Info("Reported the image inspection health", "data", data)
+	return nil
+}
@@ -0,0 +1,70 @@
+package podplacement
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func TestImageInspectionHealthReporter_RollingWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(start)
+	configMaps := &fakeConfigMaps{configMaps: map[string]*corev1.ConfigMap{}}
+	var inspections, failures uint64
+	r := &ImageInspectionHealthReporter{
+		configMaps: configMaps,
+		counts: func() (uint64, uint64) {
+			return inspections, failures
+		},
+		clock:    fakeClock,
+		interval: time.Minute,
+		window:   3 * time.Minute,
+		log:      logr.Discard(),
+	}
+	report := func() map[string]string {
+		g.Expect(r.report(context.TODO())).To(Succeed())
+		cm, err := configMaps.Get(context.TODO(), utils.ImageInspectionHealthConfigMapName, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return cm.Data
+	}
+	expectedReport := func(inspections, failures, window string) map[string]string {
+		return map[string]string{
+			utils.ImageInspectionHealthInspectionsKey: inspections,
+			utils.ImageInspectionHealthFailuresKey:    failures,
+			utils.ImageInspectionHealthWindowKey:      window,
+			utils.ImageInspectionHealthUpdateTimeKey:  fakeClock.Now().UTC().Format(time.RFC3339),
+		}
+	}
+
+	// The inspections before the start of the reporter are not reported
+	inspections, failures = 10, 10
+	g.Expect(report()).To(Equal(expectedReport("0", "0", "0s")))
+
+	// Each minute, 10 images are inspected and 5 of the inspections fail
+	for minute := 1; minute <= 3; minute++ {
+		fakeClock.SetTime(start.Add(time.Duration(minute) * time.Minute))
+		inspections, failures = inspections+10, failures+5
+		g.Expect(report()).To(Equal(expectedReport(strconv.Itoa(minute*10), strconv.Itoa(minute*5),
+			(time.Duration(minute) * time.Minute).String())))
+	}
+
+	// The failures stop: the ones older than the window are not reported anymore
+	for minute := 4; minute <= 6; minute++ {
+		fakeClock.SetTime(start.Add(time.Duration(minute) * time.Minute))
+		inspections += 10
+		g.Expect(report()[utils.ImageInspectionHealthWindowKey]).To(Equal("3m0s"))
+	}
+	g.Expect(report()).To(Equal(expectedReport("30", "0", "3m0s")))
+}
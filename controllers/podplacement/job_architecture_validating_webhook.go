@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// [disabled:operator]kubebuilder:webhook:path=/validate-job-architecture,mutating=false,sideEffects=None,admissionReviewVersions=v1,failurePolicy=ignore,groups="batch",resources=jobs;cronjobs,verbs=create,versions=v1,name=job-architecture-validation.multiarch.openshift.io
+
+// JobArchitectureValidatingWebHook warns the users creating Jobs and CronJobs whose pod templates use images that
+// do not support any common architecture. Their pods are only created later: the warning gives an early feedback,
+// while the Jobs and CronJobs are always admitted, for backward compatibility.
+// It is registered in the same ValidatingWebhookConfiguration as the PodArchitectureValidatingWebHook.
+type JobArchitectureValidatingWebHook struct {
+	imageInspector image.ICache
+	// maxConcurrentInspections is the maximum number of distinct images of a pod inspected concurrently.
+	maxConcurrentInspections int
+	decoder                  admission.Decoder
+	once                     sync.Once
+	scheme                   *runtime.Scheme
+}
+
+func (a *JobArchitectureValidatingWebHook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	a.once.Do(func() {
+		a.decoder = admission.NewDecoder(a.scheme)
+	})
+	// The image inspection metrics are the ones of the pod placement controller
+	metrics.InitPodPlacementControllerMetrics()
+	template, err := a.podTemplate(req)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	return podTemplateArchitectureResponse(ctx, a.imageInspector, a.maxConcurrentInspections, req, template)
+}
+
+// podTemplateArchitectureResponse admits the workload in the request, warning if the images of its pod template do
+// not support any common architecture.
+func podTemplateArchitectureResponse(ctx context.Context, imageInspector image.ICache, maxConcurrentInspections int,
+	req admission.Request, template *corev1.PodTemplateSpec) admission.Response {
+	pod := &Pod{
+		Pod: corev1.Pod{
+			ObjectMeta: template.ObjectMeta,
+			Spec:       template.Spec,
+		},
+		ctx:                      ctx,
+		imageInspector:           imageInspector,
+		searchRegistries:         image.UnqualifiedSearchRegistries(ctx),
+		maxConcurrentInspections: maxConcurrentInspections,
+	}
+	pod.Namespace = req.Namespace
+	log := ctrllog.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name, "kind", req.Kind.Kind)
+
+	cppc := clusterpodplacementconfig.GetClusterPodPlacementConfig()
+	pod.universalImages = universalImagesOf(cppc)
+	if reason := pod.ignoreReason(cppc); reason != "" {
+		log.V(3).Info("Ignoring the pod template", "reason", reason)
+		return admission.Allowed(fmt.Sprintf("the pod template is ignored: %s", reason))
+	}
+
+	// The pull secrets of the pod template are not read: see PodArchitectureValidatingWebHook.
+	requirement, _, err := pod.getArchitecturePredicate(nil)
+	if err != nil {
+		log.V(1).Info("Unable to inspect the images of the pod template", "error", err)
+		return admission.Allowed("unable to inspect the images of the pod template")
+	}
+	if requirement.Key != utils.NoSupportedArchLabel {
+		return admission.Allowed("")
+	}
+	log.V(2).Info("Warning about the pod template as its images do not support any common architecture")
+	return admission.Allowed("").WithWarnings(fmt.Sprintf("the pods of the %s will not be schedulable: %s",
+		req.Kind.Kind, conflictingImagesMessage(ctx, pod, nil)))
+}
+
+// podTemplate decodes the Job or CronJob in the request and returns its pod template.
+func (a *JobArchitectureValidatingWebHook) podTemplate(req admission.Request) (*corev1.PodTemplateSpec, error) {
+	switch req.Kind.Kind {
+	case "Job":
+		job := &batchv1.Job{}
+		if err := a.decoder.Decode(req, job); err != nil {
+			return nil, err
+		}
+		return &job.Spec.Template, nil
+	case "CronJob":
+		cronJob := &batchv1.CronJob{}
+		if err := a.decoder.Decode(req, cronJob); err != nil {
+			return nil, err
+		}
+		return &cronJob.Spec.JobTemplate.Spec.Template, nil
+	default:
+		return nil, fmt.Errorf("unexpected kind %q", req.Kind.Kind)
+	}
+}
+
+func NewJobArchitectureValidatingWebHook(imageInspector image.ICache, maxConcurrentInspections int,
+	scheme *runtime.Scheme) *JobArchitectureValidatingWebHook {
+	return &JobArchitectureValidatingWebHook{
+		imageInspector:           imageInspector,
+		maxConcurrentInspections: maxConcurrentInspections,
+		scheme:                   scheme,
+	}
+}
@@ -0,0 +1,107 @@
+package podplacement
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
+)
+
+func TestJobArchitectureValidatingWebHook_Handle(t *testing.T) {
+	podTemplate := func(images ...string) corev1.PodTemplateSpec {
+		pod := builder.NewPod().WithContainersImages(images...).Build()
+		return corev1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta, Spec: pod.Spec}
+	}
+	conflictingImagesWarning := func(kind string) string {
+		return "the pods of the " + kind + " will not be schedulable: " +
+			"the images of the pod do not support any common architecture: " +
+			fake.SingleArchAmd64Image + " (amd64); " + fake.SingleArchArm64Image + " (arm64)"
+	}
+	tests := []struct {
+		name         string
+		kind         string
+		namespace    string
+		object       runtime.Object
+		wantWarnings []string
+	}{
+		{
+			name:      "job with compatible images",
+			kind:      "Job",
+			namespace: "test-namespace",
+			object: &batchv1.Job{Spec: batchv1.JobSpec{
+				Template: podTemplate(fake.MultiArchImage, fake.SingleArchAmd64Image),
+			}},
+		},
+		{
+			name:      "job with conflicting images",
+			kind:      "Job",
+			namespace: "test-namespace",
+			object: &batchv1.Job{Spec: batchv1.JobSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+			wantWarnings: []string{conflictingImagesWarning("Job")},
+		},
+		{
+			name:      "cronjob with compatible images",
+			kind:      "CronJob",
+			namespace: "test-namespace",
+			object: &batchv1.CronJob{Spec: batchv1.CronJobSpec{
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+					Template: podTemplate(fake.MultiArchImage, fake.MultiArchImage2),
+				}},
+			}},
+		},
+		{
+			name:      "cronjob with conflicting images",
+			kind:      "CronJob",
+			namespace: "test-namespace",
+			object: &batchv1.CronJob{Spec: batchv1.CronJobSpec{
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+					Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+				}},
+			}},
+			wantWarnings: []string{conflictingImagesWarning("CronJob")},
+		},
+		{
+			name:      "job with conflicting images in an excluded namespace",
+			kind:      "Job",
+			namespace: "kube-system",
+			object: &batchv1.Job{Spec: batchv1.JobSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			a := NewJobArchitectureValidatingWebHook(fake.FacadeSingleton(), 0, scheme.Scheme)
+
+			raw, err := json.Marshal(tt.object)
+			g.Expect(err).NotTo(HaveOccurred())
+			resp := a.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: "batch", Version: "v1", Kind: tt.kind},
+					Namespace: tt.namespace,
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			g.Expect(resp.Allowed).To(BeTrue(), "the jobs and cronjobs are never denied")
+			g.Expect(resp.Warnings).To(Equal(tt.wantWarnings))
+		})
+	}
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+const (
+	// legacyAffinityRepairInterval is the period of the checks for the pods with a stale architecture requirement.
+	legacyAffinityRepairInterval = 10 * time.Minute
+	// legacyAffinityRepairPageSize is the number of pods listed per request, so that the pods of the large clusters
+	// are not all loaded in memory at once.
+	legacyAffinityRepairPageSize = 500
+	// legacyAffinityRepairQPS and legacyAffinityRepairBurst rate-limit the updates of the pods to repair.
+	legacyAffinityRepairQPS   = 1
+	legacyAffinityRepairBurst = 5
+)
+
+// LegacyAffinityRepairReconciler repairs the pods whose architecture requirement, set by a previous version of the
+// operator, differs from the one the current version computes, e.g., because it misses new architectures or
+// prevents the pods from being scheduled with the NoSupportedArchLabel. It only runs when the
+// RepairStaleArchitectureAffinity of the ClusterPodPlacementConfig is enabled.
+// The pods are never deleted. The node affinity of the pods can only be narrowed while they are gated, e.g., by the
+// scheduling gates of other operators, and not at all once their scheduling gates are removed: the requirement
+// computed by SetNodeAffinityArchRequirement is added to the node selector terms of the gated pods, and the pods
+// that cannot be fully repaired that way are labeled with utils.StaleArchitectureAffinityLabel and get a warning
+// event, so that their owners can recreate them.
+type LegacyAffinityRepairReconciler struct {
+	clientSet      kubernetes.Interface
+	pods           corev1client.PodsGetter
+	recorder       record.EventRecorder
+	imageInspector image.ICache
+	// archInventory keeps the architectures of the nodes of the cluster, the architecture requirements of the pods
+	// are trimmed to, as done by the PodReconciler.
+	archInventory *ClusterArchInventory
+	// config returns the ClusterPodPlacementConfig, or nil if it does not exist
+	config func() *v1beta1.ClusterPodPlacementConfig
+	// limiter rate-limits the updates of the pods, so that the repair of many pods does not overwhelm the API server
+	limiter  flowcontrol.RateLimiter
+	interval time.Duration
+	pageSize int64
+	// checked are the UIDs of the pods already checked, whose images are not inspected again
+	checked sets.Set[types.UID]
+	log     logr.Logger
+}
+
+func NewLegacyAffinityRepairReconciler(clientSet kubernetes.Interface, recorder record.EventRecorder,
+	imageInspector image.ICache, archInventory *ClusterArchInventory) *LegacyAffinityRepairReconciler {
+	return &LegacyAffinityRepairReconciler{
+		clientSet:      clientSet,
+		pods:           clientSet.CoreV1(),
+		recorder:       recorder,
+		imageInspector: imageInspector,
+		archInventory:  archInventory,
+		config:         clusterpodplacementconfig.GetClusterPodPlacementConfig,
+		limiter:        flowcontrol.NewTokenBucketRateLimiter(legacyAffinityRepairQPS, legacyAffinityRepairBurst),
+		interval:       legacyAffinityRepairInterval,
+		pageSize:       legacyAffinityRepairPageSize,
+		checked:        sets.New[types.UID](),
+	}
+}
+
+func (r *LegacyAffinityRepairReconciler) Start(ctx context.Context) error {
+	r.log = log.FromContext(ctx, "handler", "LegacyAffinityRepairReconciler", "kind", "Pod [core/v1]")
+	r.log.Info("Starting the Legacy Affinity Repair Reconciler")
+	defer r.limiter.Stop()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		// The pods processed by a previous version are repaired as soon as the operator is upgraded
+		if err := r.reconcile(ctx); err != nil {
+			r.log.Error(err, "Unable to repair the pods with a stale architecture requirement")
+		}
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping the Legacy Affinity Repair Reconciler")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile repairs the pods whose architecture requirement is stale, if the RepairStaleArchitectureAffinity of the
+// ClusterPodPlacementConfig is enabled. The pods that are not completed, whose node affinity was set by the operator
+// and that are not labeled with utils.StaleArchitectureAffinityLabel yet, are listed in pages. It is idempotent: the
+// pods with an up-to-date requirement are skipped. Each pod is checked once: only the pods whose images could not be
+// inspected, or whose update failed, are checked again in the next runs.
+func (r *LegacyAffinityRepairReconciler) reconcile(ctx context.Context) error {
+	cppc := r.config()
+	if cppc == nil || !cppc.Spec.RepairStaleArchitectureAffinity {
+		r.checked.Clear()
+		return nil
+	}
+	nodeAffinitySet, err := labels.NewRequirement(utils.NodeAffinityLabel, selection.Equals,
+		[]string{utils.NodeAffinityLabelValueSet})
+	if err != nil {
+		return err
+	}
+	notLabeledStale, err := labels.NewRequirement(utils.StaleArchitectureAffinityLabel, selection.DoesNotExist, nil)
+	if err != nil {
+		return err
+	}
+	listOptions := metav1.ListOptions{
+		LabelSelector: labels.NewSelector().Add(*nodeAffinitySet, *notLabeledStale).String(),
+		FieldSelector: fields.AndSelectors(
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+		).String(),
+		Limit: r.pageSize,
+	}
+	searchRegistries := image.UnqualifiedSearchRegistries(ctx)
+	universalImages := universalImagesOf(cppc)
+	// The architecture requirements are computed as the PodReconciler does, not to judge stale the ones it trimmed
+	clusterArchitectures := clusterArchitecturesOf(cppc, r.archInventory)
+	requireArchLabelOnNodes := archLabelRequiredOnNodes(cppc)
+	// listed are the UIDs of the pods listed in this run: the other pods are removed from the checked ones
+	listed := sets.New[types.UID]()
+	for {
+		podList, err := r.pods.Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return err
+		}
+		for i := range podList.Items {
+			pod := &Pod{
+				Pod:                     podList.Items[i],
+				ctx:                     ctx,
+				recorder:                r.recorder,
+				imageInspector:          r.imageInspector,
+				searchRegistries:        searchRegistries,
+				universalImages:         universalImages,
+				clusterArchitectures:    clusterArchitectures,
+				requireArchLabelOnNodes: requireArchLabelOnNodes,
+			}
+			if !pod.isRepairCandidate() {
+				continue
+			}
+			listed.Insert(pod.UID)
+			if r.checked.Has(pod.UID) {
+				continue
+			}
+			if err := r.repair(ctx, pod); err != nil {
+				return err
+			}
+		}
+		if podList.Continue == "" {
+			break
+		}
+		listOptions.Continue = podList.Continue
+	}
+	r.checked = r.checked.Intersection(listed)
+	return nil
+}
+
+// repair updates the pod if its architecture requirement is stale. The requirement computed by the current version
+// is added to the node selector terms of the gated pods, if it only narrows theirs. The other pods, e.g., the
+// scheduled ones, are labeled with utils.StaleArchitectureAffinityLabel. The pod is
+// added to the checked ones unless its images cannot be inspected or its update fails. An error is only returned if
+// the context is cancelled.
+func (r *LegacyAffinityRepairReconciler) repair(ctx context.Context, pod *Pod) error {
+	log := r.log.WithValues("namespace", pod.Namespace, "name", pod.Name)
+	want, stale, err := r.isStale(pod)
+	if err != nil {
+		log.V(1).Info("Unable to compute the architecture requirement of the pod", "error", err)
+		return nil
+	}
+	if !stale {
+		r.checked.Insert(pod.UID)
+		return nil
+	}
+	// The node affinity of the pods can only be changed while they have scheduling gates, e.g., the ones of other
+	// operators
+	repaired := len(pod.Spec.SchedulingGates) > 0 && narrowsTo(pod.archRequirements(), want)
+	if repaired {
+		pod.addArchRequirement(want)
+	} else {
+		pod.ensureLabel(utils.StaleArchitectureAffinityLabel, "")
+	}
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	// The update is rejected if the pod changed in the meantime, e.g., because its scheduling gates were removed:
+	// it is checked again in the next run.
+	_, err = r.pods.Pods(pod.Namespace).Update(ctx, &pod.Pod, metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	if err != nil {
+		log.Error(err, "Unable to update the pod with a stale architecture requirement")
+		return nil
+	}
+	r.checked.Insert(pod.UID)
+	if repaired {
+		log.Info("Repaired the stale architecture requirement of the pod")
+		pod.publishEvent(corev1.EventTypeNormal, ArchitectureAwareStaleAffinityRepaired, StaleAffinityRepairedMsg)
+		return nil
+	}
+	log.Info("Labeled the pod with a stale architecture requirement that cannot be repaired")
+	pod.publishEvent(corev1.EventTypeWarning, ArchitectureAwareStaleAffinityDetected, StaleAffinityDetectedMsg)
+	return nil
+}
+
+// isRepairCandidate returns true if the architecture requirement of the pod may be repaired, i.e., if the pod is not
+// deleted yet. The architecture requirement of the pods given the InspectionTimeoutFallback is not the one of their
+// images, and is not stale.
+func (pod *Pod) isRepairCandidate() bool {
+	_, fallback := pod.Labels[utils.InspectionFallbackLabel]
+	return pod.DeletionTimestamp == nil && !fallback
+}
+
+// isStale returns the architecture requirement computed by the current version of the operator, and true if the
+// architecture requirements of the pod differ from it. An error is returned if the images of the pod cannot be
+// inspected.
+func (r *LegacyAffinityRepairReconciler) isStale(pod *Pod) (corev1.NodeSelectorRequirement, bool, error) {
+	current := pod.archRequirements()
+	if len(current) == 0 {
+		return corev1.NodeSelectorRequirement{}, false, nil
+	}
+	want, _, err := pod.getArchitecturePredicate(getPullSecretDataList(pod.ctx, r.clientSet, pod))
+	if err != nil {
+		return corev1.NodeSelectorRequirement{}, false, err
+	}
+	for _, requirement := range current {
+		if equalNodeSelectorRequirements(requirement, want) {
+			return want, false, nil
+		}
+	}
+	return want, true, nil
+}
+
+// narrowsTo returns true if adding the wanted requirement to each of the current ones, ANDing them, is equivalent
+// to the wanted requirement alone, i.e., if it only removes architectures from the current requirements.
+func narrowsTo(current []corev1.NodeSelectorRequirement, want corev1.NodeSelectorRequirement) bool {
+	if want.Key == utils.NoSupportedArchLabel {
+		// No node has the NoSupportedArchLabel: the pod stays unschedulable, whatever the current requirements
+		return true
+	}
+	for _, requirement := range current {
+		if requirement.Key != utils.ArchLabel || requirement.Operator != corev1.NodeSelectorOpIn ||
+			!sets.New(requirement.Values...).IsSuperset(sets.New(want.Values...)) {
+			return false
+		}
+	}
+	return true
+}
+
+// addArchRequirement adds the requirement to the matchExpressions of each node selector term of the required node
+// affinity of the pod that does not have it yet. The expressions of the terms are ANDed: the API server allows
+// such additions while the pod is gated.
+func (pod *Pod) addArchRequirement(requirement corev1.NodeSelectorRequirement) {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return
+	}
+	terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for i := range terms {
+		if !slices.ContainsFunc(terms[i].MatchExpressions, func(expression corev1.NodeSelectorRequirement) bool {
+			return equalNodeSelectorRequirements(expression, requirement)
+		}) {
+			terms[i].MatchExpressions = append(terms[i].MatchExpressions, requirement)
+		}
+	}
+}
+
+// archRequirements returns the requirements of the required node affinity of the pod on the utils.ArchLabel or
+// the utils.NoSupportedArchLabel labels.
+func (pod *Pod) archRequirements() []corev1.NodeSelectorRequirement {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+	var requirements []corev1.NodeSelectorRequirement
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == utils.ArchLabel || expression.Key == utils.NoSupportedArchLabel {
+				requirements = append(requirements, expression)
+			}
+		}
+	}
+	return requirements
+}
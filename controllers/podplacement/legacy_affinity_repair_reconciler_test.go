@@ -0,0 +1,419 @@
+package podplacement
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	mmoimage "github.com/openshift/multiarch-tuning-operator/pkg/image"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake/registry"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+
+	. "github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+)
+
+func TestLegacyAffinityRepairReconciler_isStale(t *testing.T) {
+	archRequirement := func(architectures ...string) []corev1.NodeSelectorRequirement {
+		return []corev1.NodeSelectorRequirement{
+			{Key: utils.ArchLabel, Operator: corev1.NodeSelectorOpIn, Values: architectures},
+		}
+	}
+	noSupportedArchRequirement := []corev1.NodeSelectorRequirement{
+		{Key: utils.NoSupportedArchLabel, Operator: corev1.NodeSelectorOpExists},
+	}
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "up-to-date architecture requirement",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				archRequirement(utils.ArchitectureArm64, utils.ArchitectureAmd64)).Build(),
+		},
+		{
+			name: "architecture requirement missing an architecture",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				archRequirement(utils.ArchitectureAmd64)).Build(),
+			want: true,
+		},
+		{
+			name: "stale no supported architecture requirement",
+			pod: NewPod().WithContainersImages(fake.MultiArchArmImage, fake.SingleArchArmV7Image).
+				WithNodeSelectorTermsMatchExpressions(noSupportedArchRequirement).Build(),
+			want: true,
+		},
+		{
+			name: "up-to-date no supported architecture requirement",
+			pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).
+				WithNodeSelectorTermsMatchExpressions(noSupportedArchRequirement).Build(),
+		},
+		{
+			name: "up-to-date architecture requirement in a term, user-defined one in another",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				archRequirement(utils.ArchitectureS390x),
+				archRequirement(utils.ArchitectureAmd64, utils.ArchitectureArm64)).Build(),
+		},
+		{
+			name: "no architecture requirement",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+		},
+		{
+			name: "image that cannot be inspected",
+			pod: NewPod().WithContainersImages("non-existing-image").WithNodeSelectorTermsMatchExpressions(
+				archRequirement(utils.ArchitectureAmd64)).Build(),
+			wantErr: true,
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	r := &LegacyAffinityRepairReconciler{log: logr.Discard()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			_, stale, err := r.isStale(&Pod{Pod: *tt.pod, ctx: ctx, imageInspector: fake.FacadeSingleton()})
+			g.Expect(err != nil).To(Equal(tt.wantErr))
+			g.Expect(stale).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestNarrowsTo(t *testing.T) {
+	archRequirement := func(architectures ...string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: corev1.NodeSelectorOpIn, Values: architectures}
+	}
+	noSupportedArchRequirement := corev1.NodeSelectorRequirement{
+		Key: utils.NoSupportedArchLabel, Operator: corev1.NodeSelectorOpExists,
+	}
+	tests := []struct {
+		name    string
+		current []corev1.NodeSelectorRequirement
+		want    corev1.NodeSelectorRequirement
+		result  bool
+	}{
+		{
+			name:    "architectures removed",
+			current: []corev1.NodeSelectorRequirement{archRequirement(utils.ArchitectureAmd64, utils.ArchitectureArm64)},
+			want:    archRequirement(utils.ArchitectureArm64),
+			result:  true,
+		},
+		{
+			name:    "architectures added",
+			current: []corev1.NodeSelectorRequirement{archRequirement(utils.ArchitectureAmd64)},
+			want:    archRequirement(utils.ArchitectureAmd64, utils.ArchitectureArm64),
+		},
+		{
+			name: "architectures removed in a term, added in another",
+			current: []corev1.NodeSelectorRequirement{
+				archRequirement(utils.ArchitectureAmd64, utils.ArchitectureArm64),
+				archRequirement(utils.ArchitectureS390x),
+			},
+			want: archRequirement(utils.ArchitectureArm64),
+		},
+		{
+			name:    "no supported architecture requirement wanted",
+			current: []corev1.NodeSelectorRequirement{archRequirement(utils.ArchitectureAmd64)},
+			want:    noSupportedArchRequirement,
+			result:  true,
+		},
+		{
+			name:    "stale no supported architecture requirement",
+			current: []corev1.NodeSelectorRequirement{noSupportedArchRequirement},
+			want:    archRequirement(utils.ArchitectureAmd64),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(narrowsTo(tt.current, tt.want)).To(Equal(tt.result))
+		})
+	}
+}
+
+func TestLegacyAffinityRepairReconciler_reconcile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitPodPlacementControllerMetrics()
+	newLegacyPod := func(name string, gated bool, architectures ...string) *corev1.Pod {
+		builder := NewPod().WithContainersImages(fake.MultiArchImage).
+			WithLabels(utils.NodeAffinityLabel, utils.NodeAffinityLabelValueSet).
+			WithNodeSelectorTermsMatchExpressions([]corev1.NodeSelectorRequirement{
+				{Key: utils.ArchLabel, Operator: corev1.NodeSelectorOpIn, Values: architectures},
+			})
+		if gated {
+			builder = builder.WithSchedulingGates("example.com/other-gate")
+		}
+		pod := builder.Build()
+		pod.Name = name
+		pod.UID = types.UID(name)
+		return pod
+	}
+	pods := &pagedFakePods{fakePods: &fakePods{pods: map[string]*corev1.Pod{
+		"gated-stale": newLegacyPod("gated-stale", true,
+			utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitecturePpc64le),
+		"gated-unrepairable": newLegacyPod("gated-unrepairable", true, utils.ArchitectureAmd64),
+		"ungated-stale":      newLegacyPod("ungated-stale", false, utils.ArchitectureAmd64),
+		"up-to-date":         newLegacyPod("up-to-date", false, utils.ArchitectureAmd64, utils.ArchitectureArm64),
+	}}}
+	recorder := record.NewFakeRecorder(10)
+	cppc := NewClusterPodPlacementConfig().Build()
+	r := &LegacyAffinityRepairReconciler{
+		pods:           pods,
+		recorder:       recorder,
+		imageInspector: fake.NewFacade(),
+		config:         func() *v1beta1.ClusterPodPlacementConfig { return cppc },
+		limiter:        flowcontrol.NewFakeAlwaysRateLimiter(),
+		pageSize:       1,
+		checked:        sets.New[types.UID](),
+		log:            logr.Discard(),
+	}
+
+	// The pods are not listed, nor updated, unless the repair is enabled
+	g.Expect(r.reconcile(ctx)).To(Succeed())
+	g.Expect(pods.lists).To(BeZero())
+
+	// The update of a pod that changed in the meantime is retried in the next run
+	cppc.Spec.RepairStaleArchitectureAffinity = true
+	pods.updateErr = apierrors.NewConflict(corev1.Resource("pods"), "", errors.New("conflict"))
+	g.Expect(r.reconcile(ctx)).To(Succeed())
+	g.Expect(r.checked).To(Equal(sets.New[types.UID]("up-to-date")))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	pods.updateErr = nil
+	g.Expect(r.reconcile(ctx)).To(Succeed())
+	g.Expect(pods.lists).To(BeNumerically(">", 2), "the pods should be listed in pages")
+	g.Expect(r.checked).To(HaveLen(4))
+	g.Expect(pods.pods["gated-stale"].Labels).NotTo(HaveKey(utils.StaleArchitectureAffinityLabel))
+	g.Expect(pods.pods["gated-stale"].Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+		NodeSelectorTerms[0].MatchExpressions).To(Equal([]corev1.NodeSelectorRequirement{
+		{
+			Key:      utils.ArchLabel,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitecturePpc64le},
+		},
+		{
+			Key:      utils.ArchLabel,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+		},
+	}), "the requirement of the images should be added to the one of the gated pod")
+	for _, name := range []string{"gated-unrepairable", "ungated-stale"} {
+		g.Expect(pods.pods[name].Labels).To(HaveKey(utils.StaleArchitectureAffinityLabel))
+		g.Expect(pods.pods[name].Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+			NodeSelectorTerms[0].MatchExpressions).To(HaveLen(1), "the pod that cannot be narrowed should not be updated")
+	}
+	g.Expect(pods.pods["up-to-date"].Labels).NotTo(HaveKey(utils.StaleArchitectureAffinityLabel))
+	g.Expect(recorder.Events).To(HaveLen(3))
+	repairedEvent := corev1.EventTypeNormal + " " + ArchitectureAwareStaleAffinityRepaired
+	detectedEvent := corev1.EventTypeWarning + " " + ArchitectureAwareStaleAffinityDetected
+	g.Expect(recorder.Events).To(Receive(HavePrefix(repairedEvent)))
+	g.Expect(recorder.Events).To(Receive(HavePrefix(detectedEvent)))
+	g.Expect(recorder.Events).To(Receive(HavePrefix(detectedEvent)))
+
+	// The pods are updated once: the labeled pods are no longer listed, and the repaired one is up-to-date
+	r.checked.Clear()
+	g.Expect(r.reconcile(ctx)).To(Succeed())
+	g.Expect(r.checked).To(Equal(sets.New[types.UID]("gated-stale", "up-to-date")))
+	g.Expect(recorder.Events).To(BeEmpty())
+}
+
+func TestLegacyAffinityRepairReconciler_reconcile_TrimmedPods(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitPodPlacementControllerMetrics()
+	inventory := NewClusterArchInventory(nil)
+	inventory.onAddOrUpdate(newArchNode("worker-0", utils.ArchitectureAmd64))
+	inventory.markSynced()
+	// The architecture requirement set by the PodReconciler to a multi-arch image, trimmed to the amd64 nodes and
+	// requiring the architecture label on the nodes
+	pod := NewPod().WithContainersImages(fake.MultiArchImage).
+		WithLabels(utils.NodeAffinityLabel, utils.NodeAffinityLabelValueSet).
+		WithNodeSelectorTermsMatchExpressions([]corev1.NodeSelectorRequirement{
+			{Key: utils.ArchLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{utils.ArchitectureAmd64}},
+		}).Build()
+	pod.Name = "trimmed"
+	pod.UID = types.UID(pod.Name)
+	pods := &pagedFakePods{fakePods: &fakePods{pods: map[string]*corev1.Pod{
+		pod.Name: pod,
+	}}}
+	cppc := NewClusterPodPlacementConfig().WithRepairStaleArchitectureAffinity(true).
+		WithTrimToClusterArchitectures(true).WithRequireArchLabelOnNodes(true).Build()
+	r := &LegacyAffinityRepairReconciler{
+		pods:           pods,
+		recorder:       record.NewFakeRecorder(10),
+		imageInspector: fake.NewFacade(),
+		archInventory:  inventory,
+		config:         func() *v1beta1.ClusterPodPlacementConfig { return cppc },
+		limiter:        flowcontrol.NewFakeAlwaysRateLimiter(),
+		pageSize:       1,
+		checked:        sets.New[types.UID](),
+		log:            logr.Discard(),
+	}
+	g.Expect(r.reconcile(ctx)).To(Succeed())
+	g.Expect(pods.pods[pod.Name].Labels).NotTo(HaveKey(utils.StaleArchitectureAffinityLabel),
+		"the pod trimmed to the cluster architectures should not be judged stale")
+	g.Expect(r.checked).To(Equal(sets.New[types.UID](pod.UID)))
+}
+
+func TestPod_isRepairCandidate(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{
+			name: "ungated pod",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+			want: true,
+		},
+		{
+			name: "gated pod",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithSchedulingGates("example.com/other-gate").Build(),
+			want: true,
+		},
+		{
+			name: "scheduled pod",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithNodeName("worker-0").Build(),
+			want: true,
+		},
+		{
+			name: "deleted pod",
+			pod: func() *corev1.Pod {
+				pod := NewPod().WithContainersImages(fake.MultiArchImage).Build()
+				pod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				return pod
+			}(),
+		},
+		{
+			name: "pod given the inspection timeout fallback",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithLabels(utils.InspectionFallbackLabel, string(v1beta1.InspectionTimeoutFallbackAllowAll)).Build(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *tt.pod, ctx: ctx}
+			g.Expect(pod.isRepairCandidate()).To(Equal(tt.want))
+		})
+	}
+}
+
+var _ = Describe("Controllers/Podplacement/LegacyAffinityRepairReconciler", func() {
+	When("The operator is upgraded", func() {
+		It("repairs the pods with an architecture requirement set by the previous version without deleting them", func() {
+			// The image supports amd64 and arm64
+			image := registry.ComputeNameByMediaType(imgspecv1.MediaTypeImageIndex)
+			imageName := registryAddress + "/" + registry.PublicRepo + "/" + image + ":latest"
+			// newLegacyPod creates a pod as left by the previous version of the operator: the pods with an
+			// architecture requirement are ignored by the webhook, so that the labels set by the previous version
+			// are added afterward.
+			newLegacyPod := func(name string, schedulingGates []string, architectures ...string) *corev1.Pod {
+				pod := NewPod().
+					WithContainersImages(imageName).
+					WithGenerateName(name).
+					WithNamespace("test-namespace").
+					WithSchedulingGates(schedulingGates...).
+					WithNodeSelectorTermsMatchExpressions([]corev1.NodeSelectorRequirement{
+						{
+							Key:      utils.ArchLabel,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   architectures,
+						},
+					}).
+					Build()
+				Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(pod), pod)).To(Succeed())
+					pod.Labels[utils.NodeAffinityLabel] = utils.NodeAffinityLabelValueSet
+					pod.Labels[utils.SchedulingGateLabel] = utils.SchedulingGateLabelValueRemoved
+					g.Expect(k8sClient.Update(ctx, pod)).To(Succeed())
+				}).Should(Succeed(), "the labels of the previous version were not set")
+				return pod
+			}
+			By("Creating the pods processed by the previous version")
+			// The previous version allowed all the architectures to the pod still gated by another operator, and
+			// only amd64 to the ungated one
+			gatedLegacyPod := newLegacyPod("test-gated-legacy-pod-", []string{"example.com/other-gate"},
+				utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitecturePpc64le, utils.ArchitectureS390x)
+			ungatedLegacyPod := newLegacyPod("test-ungated-legacy-pod-", nil, utils.ArchitectureAmd64)
+			By("Creating a pod processed by the current version")
+			pod := NewPod().
+				WithContainersImages(imageName).
+				WithGenerateName("test-pod-").
+				WithNamespace("test-namespace").
+				Build()
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(pod), pod)).To(Succeed())
+				g.Expect(pod.Spec.SchedulingGates).To(BeEmpty(), "scheduling gate not removed")
+				g.Expect(pod.Labels).To(HaveKeyWithValue(utils.NodeAffinityLabel, utils.NodeAffinityLabelValueSet))
+			}).Should(Succeed(), "the pod was not processed")
+
+			By("Repairing the pods, twice to verify the reconciler is idempotent")
+			recorder := record.NewFakeRecorder(100)
+			r := NewLegacyAffinityRepairReconciler(kubernetes.NewForConfigOrDie(cfg), recorder,
+				mmoimage.FacadeSingleton(), nil)
+			r.config = func() *v1beta1.ClusterPodPlacementConfig {
+				return NewClusterPodPlacementConfig().WithRepairStaleArchitectureAffinity(true).Build()
+			}
+			r.interval = time.Hour
+			r.log = suiteLog
+			Expect(r.reconcile(ctx)).To(Succeed())
+			Expect(r.reconcile(ctx)).To(Succeed())
+
+			By("Verifying the requirement of the images is added to the one of the gated pod")
+			Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(gatedLegacyPod), gatedLegacyPod)).To(Succeed())
+			Expect(gatedLegacyPod.Spec.SchedulingGates).To(HaveLen(1))
+			Expect(gatedLegacyPod.Labels).NotTo(HaveKey(utils.StaleArchitectureAffinityLabel))
+			Expect(gatedLegacyPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+				NodeSelectorTerms[0].MatchExpressions).To(ConsistOf(
+				corev1.NodeSelectorRequirement{
+					Key:      utils.ArchLabel,
+					Operator: corev1.NodeSelectorOpIn,
+					Values: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitecturePpc64le,
+						utils.ArchitectureS390x},
+				},
+				corev1.NodeSelectorRequirement{
+					Key:      utils.ArchLabel,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+				},
+			))
+
+			By("Verifying the ungated pod is labeled and not deleted")
+			Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(ungatedLegacyPod), ungatedLegacyPod)).To(Succeed())
+			Expect(ungatedLegacyPod.DeletionTimestamp).To(BeNil())
+			Expect(ungatedLegacyPod.Labels).To(HaveKey(utils.StaleArchitectureAffinityLabel))
+			Expect(ungatedLegacyPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+				NodeSelectorTerms[0].MatchExpressions).To(HaveLen(1))
+
+			By("Verifying the pod processed by the current version is untouched")
+			updatedPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(pod), updatedPod)).To(Succeed())
+			Expect(updatedPod.ResourceVersion).To(Equal(pod.ResourceVersion))
+
+			By("Verifying one event is published per updated pod")
+			Expect(recorder.Events).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ManagedSchedulingGateReconciler removes one of the managed scheduling gates, see utils.GetManagedSchedulingGates,
+// from the pods whose processing for that gate is completed, i.e., the pods listing the gate in their
+// utils.CompletedSchedulingGatesAnnotation. Each managed gate has its own reconciler, so that the gates are removed
+// independently of each other and of the architecture-aware scheduling gate.
+type ManagedSchedulingGateReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	gateName string
+	// index is the position of the gate in the managed scheduling gates, used to name the controller uniquely
+	index int
+}
+
+func NewManagedSchedulingGateReconciler(client client.Client, recorder record.EventRecorder, gateName string,
+	index int) *ManagedSchedulingGateReconciler {
+	return &ManagedSchedulingGateReconciler{
+		Client:   client,
+		Recorder: recorder,
+		gateName: gateName,
+		index:    index,
+	}
+}
+
+// Reconcile removes the managed scheduling gate from the pod if its processing is completed.
+func (r *ManagedSchedulingGateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx).WithValues("schedulingGate", r.gateName)
+	pod := &Pod{
+		ctx:      ctx,
+		recorder: r.Recorder,
+	}
+	if err := r.Get(ctx, req.NamespacedName, &pod.Pod); err != nil {
+		log.V(2).Info("Unable to fetch pod", "error", err)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !pod.hasSchedulingGate(r.gateName) || !pod.isSchedulingGateCompleted(r.gateName) {
+		return ctrl.Result{}, nil
+	}
+	log.V(1).Info("Removing the managed scheduling gate from pod.")
+	pod.removeSchedulingGate(r.gateName)
+	if err := r.Update(ctx, &pod.Pod); err != nil {
+		log.Error(err, "Unable to update the pod")
+		pod.publishEvent(corev1.EventTypeWarning, ArchitectureAwareSchedulingGateRemovalFailure,
+			fmt.Sprintf(SchedulingGateRemovalFailureMsg, r.gateName))
+		return ctrl.Result{}, err
+	}
+	pod.publishEvent(corev1.EventTypeNormal, ArchitectureAwareSchedulingGateRemovalSuccess,
+		fmt.Sprintf(SchedulingGateRemovalSuccessMsg, r.gateName))
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Only the pods with the managed scheduling gate are
+// reconciled.
+func (r *ManagedSchedulingGateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(fmt.Sprintf("managed_scheduling_gate_%d", r.index)).
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			pod, ok := object.(*corev1.Pod)
+			return ok && (&Pod{Pod: *pod}).hasSchedulingGate(r.gateName)
+		}))).
+		Complete(r)
+}
@@ -0,0 +1,87 @@
+package podplacement
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+
+	. "github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+)
+
+// fakePodClient stores the pods in memory. Only the methods used by the ManagedSchedulingGateReconciler are
+// implemented.
+type fakePodClient struct {
+	client.Client
+	pods map[types.NamespacedName]*v1.Pod
+}
+
+func (f *fakePodClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	pod, ok := f.pods[key]
+	if !ok {
+		return apierrors.NewNotFound(v1.Resource("pods"), key.Name)
+	}
+	pod.DeepCopyInto(obj.(*v1.Pod))
+	return nil
+}
+
+func (f *fakePodClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	f.pods[client.ObjectKeyFromObject(obj)] = obj.(*v1.Pod).DeepCopy()
+	return nil
+}
+
+func TestManagedSchedulingGateReconciler_Reconcile(t *testing.T) {
+	const (
+		scanGate  = "example.com/security-scan"
+		quotaGate = "example.com/quota"
+	)
+	g := NewGomegaWithT(t)
+	pod := NewPod().WithSchedulingGates(utils.SchedulingGateName, scanGate, quotaGate).Build()
+	pod.Name = "test-pod"
+	key := client.ObjectKeyFromObject(pod)
+	podClient := &fakePodClient{pods: map[types.NamespacedName]*v1.Pod{key: pod}}
+	recorder := record.NewFakeRecorder(10)
+	scanReconciler := NewManagedSchedulingGateReconciler(podClient, recorder, scanGate, 0)
+	quotaReconciler := NewManagedSchedulingGateReconciler(podClient, recorder, quotaGate, 1)
+	reconcile := func() {
+		for _, r := range []*ManagedSchedulingGateReconciler{scanReconciler, quotaReconciler} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+	}
+	gateNames := func() []string {
+		var names []string
+		for _, gate := range podClient.pods[key].Spec.SchedulingGates {
+			names = append(names, gate.Name)
+		}
+		return names
+	}
+
+	reconcile()
+	g.Expect(gateNames()).To(Equal([]string{utils.SchedulingGateName, scanGate, quotaGate}),
+		"no gate is removed before its processing is completed")
+
+	podClient.pods[key].Annotations = map[string]string{utils.CompletedSchedulingGatesAnnotation: scanGate}
+	reconcile()
+	g.Expect(gateNames()).To(Equal([]string{utils.SchedulingGateName, quotaGate}),
+		"only the completed gate is removed")
+	g.Expect(recorder.Events).To(Receive(ContainSubstring(scanGate)))
+
+	podClient.pods[key].Annotations[utils.CompletedSchedulingGatesAnnotation] = scanGate + "," + quotaGate
+	reconcile()
+	g.Expect(gateNames()).To(Equal([]string{utils.SchedulingGateName}),
+		"the architecture-aware scheduling gate is not removed by the managed scheduling gate reconcilers")
+	g.Expect(recorder.Events).To(Receive(ContainSubstring(quotaGate)))
+
+	_, err := scanReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "missing"}})
+	g.Expect(err).NotTo(HaveOccurred(), "the deleted pods are ignored")
+}
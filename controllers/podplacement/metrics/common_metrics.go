@@ -2,15 +2,35 @@ package metrics
 
 import (
 	"sync"
+	"sync/atomic"
 
 	metrics2 "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+const namespaceLabel = "namespace"
+
 var GatedPodsGauge prometheus.Gauge
 var onceCommon sync.Once
 
+// perNamespaceMetrics enables the namespace label of the per-namespace metrics.
+var perNamespaceMetrics atomic.Bool
+
+// SetPerNamespaceMetrics enables or disables the namespace label of the per-namespace metrics.
+// When disabled, the namespace label is empty, and all the namespaces are aggregated in a single series.
+func SetPerNamespaceMetrics(enabled bool) {
+	perNamespaceMetrics.Store(enabled)
+}
+
+// NamespaceLabelValue returns the value of the namespace label of the per-namespace metrics for the given namespace.
+func NamespaceLabelValue(namespace string) string {
+	if !perNamespaceMetrics.Load() {
+		return ""
+	}
+	return namespace
+}
+
 func initCommonMetrics() {
 	onceCommon.Do(func() {
 		GatedPodsGauge = prometheus.NewGauge(
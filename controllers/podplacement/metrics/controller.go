@@ -2,12 +2,14 @@ package metrics
 
 import (
 	"sync"
+	"time"
 
 	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 
 	metrics2 "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 var (
@@ -17,6 +19,15 @@ var (
 	TimeToInspectPodImages  prometheus.Histogram
 	ProcessedPodsCtrl       prometheus.Counter
 	FailedInspectionCounter prometheus.Counter
+	ArchitectureOverrides   *prometheus.CounterVec
+	GateRemovalDuration     *prometheus.HistogramVec
+	// InspectionTimeoutFallbacks counts the pods whose images inspection timed out, by the applied fallback
+	InspectionTimeoutFallbacks *prometheus.CounterVec
+)
+
+const (
+	GateRemovalResultSuccess = "success"
+	GateRemovalResultError   = "error"
 )
 
 var onceController sync.Once
@@ -67,6 +78,50 @@ func initPodPlacementControllerMetrics() {
 			Help: "The total number of image inspections that failed",
 		},
 	)
+	ArchitectureOverrides = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mto_ppo_ctrl_architecture_overrides_total",
+			Help: "The total number of pods whose architectures are set by the override-arch annotation, by result (valid or invalid)",
+		}, []string{"result"},
+	)
+	GateRemovalDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mto_ppo_ctrl_gate_removal_duration_seconds",
+			Help:    "The time between the creation of a pod and the removal of its scheduling gate, by result (success or error)",
+			Buckets: []float64{0.1, 0.5, 1, 5, 30, 120},
+		}, []string{namespaceLabel, "result"},
+	)
+	InspectionTimeoutFallbacks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mto_ppo_ctrl_inspection_timeout_fallbacks_total",
+			Help: "The total number of pods whose images inspection timed out, by fallback (block, allow-all or allow-configured)",
+		}, []string{"fallback"},
+	)
 	metrics2.Registry.MustRegister(TimeToProcessPod, TimeToProcessGatedPod, TimeToInspectImage,
-		TimeToInspectPodImages, ProcessedPodsCtrl, FailedInspectionCounter)
+		TimeToInspectPodImages, ProcessedPodsCtrl, FailedInspectionCounter, ArchitectureOverrides, GateRemovalDuration,
+		InspectionTimeoutFallbacks)
+}
+
+// ObserveGateRemovalDuration records the time elapsed since the creation of a pod in the namespace when the
+// removal of its scheduling gate succeeds or fails with err.
+func ObserveGateRemovalDuration(namespace string, sinceCreation time.Duration, err error) {
+	result := GateRemovalResultSuccess
+	if err != nil {
+		result = GateRemovalResultError
+	}
+	GateRemovalDuration.WithLabelValues(NamespaceLabelValue(namespace), result).Observe(sinceCreation.Seconds())
+}
+
+// ImageInspectionCounts returns the number of inspections of the images of the pods and the number of failed ones
+// since the start of the process.
+func ImageInspectionCounts() (inspections, failures uint64) {
+	metric := &dto.Metric{}
+	if err := TimeToInspectPodImages.Write(metric); err == nil {
+		inspections = metric.GetHistogram().GetSampleCount()
+	}
+	metric = &dto.Metric{}
+	if err := FailedInspectionCounter.Write(metric); err == nil {
+		failures = uint64(metric.GetCounter().GetValue())
+	}
+	return inspections, failures
 }
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	metrics2 "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// gatherAndCompare gathers the metrics with the given names from the gatherer and compares
+// them, in the text exposition format, with the expected ones, as the prometheus testutil.GatherAndCompare does.
+func gatherAndCompare(t *testing.T, gatherer prometheus.Gatherer, expected string, metricNames ...string) {
+	t.Helper()
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather the metrics: %v", err)
+	}
+	names := map[string]bool{}
+	for _, name := range metricNames {
+		names[name] = true
+	}
+	got := &bytes.Buffer{}
+	encoder := expfmt.NewEncoder(got, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if !names[family.GetName()] {
+			continue
+		}
+		if err := encoder.Encode(family); err != nil {
+			t.Fatalf("failed to encode the metric family %s: %v", family.GetName(), err)
+		}
+	}
+	if strings.TrimSpace(got.String()) != strings.TrimSpace(expected) {
+		t.Errorf("unexpected metrics:\ngot:\n%s\nwant:\n%s", got.String(), expected)
+	}
+}
+
+func TestPerNamespaceMetrics(t *testing.T) {
+	tests := []struct {
+		name                string
+		perNamespaceMetrics bool
+		expected            string
+	}{
+		{
+			name:                "namespace label enabled",
+			perNamespaceMetrics: true,
+			expected: `
+# HELP mto_ppo_wh_pods_gated_by_namespace_total The total number of pods gated by the webhook by namespace
+# TYPE mto_ppo_wh_pods_gated_by_namespace_total counter
+mto_ppo_wh_pods_gated_by_namespace_total{namespace="bar"} 1
+mto_ppo_wh_pods_gated_by_namespace_total{namespace="foo"} 2
+# HELP mto_ppo_wh_pods_processed_by_namespace_total The total number of pods processed by the webhook by namespace
+# TYPE mto_ppo_wh_pods_processed_by_namespace_total counter
+mto_ppo_wh_pods_processed_by_namespace_total{namespace="bar"} 1
+mto_ppo_wh_pods_processed_by_namespace_total{namespace="foo"} 3
+`,
+		},
+		{
+			name:                "namespace label omitted",
+			perNamespaceMetrics: false,
+			expected: `
+# HELP mto_ppo_wh_pods_gated_by_namespace_total The total number of pods gated by the webhook by namespace
+# TYPE mto_ppo_wh_pods_gated_by_namespace_total counter
+mto_ppo_wh_pods_gated_by_namespace_total{namespace=""} 3
+# HELP mto_ppo_wh_pods_processed_by_namespace_total The total number of pods processed by the webhook by namespace
+# TYPE mto_ppo_wh_pods_processed_by_namespace_total counter
+mto_ppo_wh_pods_processed_by_namespace_total{namespace=""} 4
+`,
+		},
+	}
+	InitWebhookMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ProcessedPodsWHPerNamespace.Reset()
+			GatedPodsPerNamespace.Reset()
+			SetPerNamespaceMetrics(tt.perNamespaceMetrics)
+			defer SetPerNamespaceMetrics(false)
+			// foo: 3 pods processed, 2 gated; bar: 1 pod processed and gated.
+			for _, namespace := range []string{"foo", "foo", "foo", "bar"} {
+				ProcessedPodsWHPerNamespace.WithLabelValues(NamespaceLabelValue(namespace)).Inc()
+			}
+			for _, namespace := range []string{"foo", "foo", "bar"} {
+				GatedPodsPerNamespace.WithLabelValues(NamespaceLabelValue(namespace)).Inc()
+			}
+			gatherAndCompare(t, metrics2.Registry, tt.expected, "mto_ppo_wh_pods_gated_by_namespace_total",
+				"mto_ppo_wh_pods_processed_by_namespace_total")
+		})
+	}
+}
+
+func TestObserveGateRemovalDuration(t *testing.T) {
+	InitPodPlacementControllerMetrics()
+	GateRemovalDuration.Reset()
+	SetPerNamespaceMetrics(true)
+	defer SetPerNamespaceMetrics(false)
+	ObserveGateRemovalDuration("foo", 2*time.Second, nil)
+	ObserveGateRemovalDuration("foo", 40*time.Second, errors.New("conflict"))
+	gatherAndCompare(t, metrics2.Registry, `
+# HELP mto_ppo_ctrl_gate_removal_duration_seconds The time between the creation of a pod and the removal of its scheduling gate, by result (success or error)
+# TYPE mto_ppo_ctrl_gate_removal_duration_seconds histogram
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="error",le="0.1"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="error",le="0.5"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="error",le="1"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="error",le="5"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="error",le="30"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="error",le="120"} 1
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="error",le="+Inf"} 1
+mto_ppo_ctrl_gate_removal_duration_seconds_sum{namespace="foo",result="error"} 40
+mto_ppo_ctrl_gate_removal_duration_seconds_count{namespace="foo",result="error"} 1
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="success",le="0.1"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="success",le="0.5"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="success",le="1"} 0
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="success",le="5"} 1
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="success",le="30"} 1
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="success",le="120"} 1
+mto_ppo_ctrl_gate_removal_duration_seconds_bucket{namespace="foo",result="success",le="+Inf"} 1
+mto_ppo_ctrl_gate_removal_duration_seconds_sum{namespace="foo",result="success"} 2
+mto_ppo_ctrl_gate_removal_duration_seconds_count{namespace="foo",result="success"} 1
+`, "mto_ppo_ctrl_gate_removal_duration_seconds")
+}
@@ -11,9 +11,14 @@ import (
 )
 
 var (
-	ProcessedPodsWH prometheus.Counter
-	GatedPods       prometheus.Counter
-	ResponseTime    prometheus.Histogram
+	ProcessedPodsWH             prometheus.Counter
+	GatedPods                   prometheus.Counter
+	ResponseTime                prometheus.Histogram
+	ProcessedPodsWHPerNamespace *prometheus.CounterVec
+	GatedPodsPerNamespace       *prometheus.CounterVec
+	ExcludedPods                prometheus.Counter
+	IgnoredPods                 *prometheus.CounterVec
+	RegistriesReady             prometheus.Gauge
 )
 
 var onceWebhook sync.Once
@@ -36,6 +41,36 @@ func initWebhookMetrics() {
 			Help: "The total number of pods gated by the webhook",
 		},
 	)
+	ProcessedPodsWHPerNamespace = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mto_ppo_wh_pods_processed_by_namespace_total",
+			Help: "The total number of pods processed by the webhook by namespace",
+		}, []string{namespaceLabel},
+	)
+	GatedPodsPerNamespace = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mto_ppo_wh_pods_gated_by_namespace_total",
+			Help: "The total number of pods gated by the webhook by namespace",
+		}, []string{namespaceLabel},
+	)
+	ExcludedPods = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mto_ppo_wh_excluded_pods_total",
+			Help: "The total number of pods not gated by the webhook as they match the pod exclusion label selector",
+		},
+	)
+	IgnoredPods = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mto_ppo_wh_ignored_pods_total",
+			Help: "The total number of pods ignored by the webhook, by reason",
+		}, []string{"reason"},
+	)
+	RegistriesReady = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mto_ppo_wh_registries_ready",
+			Help: "Whether at least one of the unqualified search registries is reachable (1) or none of them is (0)",
+		},
+	)
 
 	ResponseTime = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
@@ -44,5 +79,6 @@ func initWebhookMetrics() {
 			Buckets: utils.Buckets(),
 		},
 	)
-	metrics2.Registry.MustRegister(ProcessedPodsWH, GatedPods, ResponseTime)
+	metrics2.Registry.MustRegister(ProcessedPodsWH, GatedPods, ResponseTime, ProcessedPodsWHPerNamespace,
+		GatedPodsPerNamespace, ExcludedPods, IgnoredPods, RegistriesReady)
 }
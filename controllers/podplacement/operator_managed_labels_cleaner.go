@@ -0,0 +1,101 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// operatorManagedLabelsCleanupPageSize is the number of pods listed per request by the OperatorManagedLabelsCleaner,
+// so that the pods of the large clusters are not all loaded in memory at once.
+const operatorManagedLabelsCleanupPageSize = 500
+
+// OperatorManagedLabelsCleaner removes the labels set by the operator from all the pods when the pod placement
+// operand is uninstalled, as they would otherwise be left on the pods indefinitely.
+type OperatorManagedLabelsCleaner struct {
+	pods     corev1client.PodsGetter
+	pageSize int64
+}
+
+func NewOperatorManagedLabelsCleaner(clientSet kubernetes.Interface) *OperatorManagedLabelsCleaner {
+	return &OperatorManagedLabelsCleaner{
+		pods:     clientSet.CoreV1(),
+		pageSize: operatorManagedLabelsCleanupPageSize,
+	}
+}
+
+// Cleanup removes the labels set by the operator from the pods with the utils.NodeAffinityLabel, which the webhook
+// sets on all the pods it admits. The pods are listed in pages. As the label is removed too, the pods already
+// cleaned up are not listed again if the cleanup is retried after an error.
+func (c *OperatorManagedLabelsCleaner) Cleanup(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithValues("function", "OperatorManagedLabelsCleaner.Cleanup")
+	requirement, err := labels.NewRequirement(utils.NodeAffinityLabel, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	listOptions := metav1.ListOptions{
+		LabelSelector: labels.NewSelector().Add(*requirement).String(),
+		Limit:         c.pageSize,
+	}
+	cleaned, failures := 0, 0
+	for {
+		podList, err := c.pods.Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return err
+		}
+		for i := range podList.Items {
+			pod := &Pod{
+				Pod: podList.Items[i],
+				ctx: ctx,
+			}
+			if !pod.RemoveAllOperatorManagedLabels() {
+				continue
+			}
+			_, err := c.pods.Pods(pod.Namespace).Update(ctx, &pod.Pod, metav1.UpdateOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				logger.Error(err, "Unable to remove the operator-managed labels from the pod",
+					"namespace", pod.Namespace, "name", pod.Name)
+				failures++
+				continue
+			}
+			cleaned++
+		}
+		if podList.Continue == "" {
+			break
+		}
+		listOptions.Continue = podList.Continue
+	}
+	logger.Info("Removed the operator-managed labels from the pods", "pods", cleaned)
+	if failures > 0 {
+		return fmt.Errorf("unable to remove the operator-managed labels from %d pods", failures)
+	}
+	return nil
+}
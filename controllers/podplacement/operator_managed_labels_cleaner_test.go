@@ -0,0 +1,112 @@
+package podplacement
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// pagedFakePods lists the pods of the fakePods in pages of opts.Limit pods, sorted by name. The continue token is
+// the name of the last pod of the previous page.
+type pagedFakePods struct {
+	*fakePods
+	lists     int
+	updateErr error
+}
+
+func (f *pagedFakePods) Pods(_ string) corev1client.PodInterface {
+	return f
+}
+
+func (f *pagedFakePods) List(ctx context.Context, opts metav1.ListOptions) (*v1.PodList, error) {
+	f.lists++
+	podList, err := f.fakePods.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(podList.Items, func(i, j int) bool {
+		return podList.Items[i].Name < podList.Items[j].Name
+	})
+	page := &v1.PodList{}
+	for _, pod := range podList.Items {
+		if pod.Name <= opts.Continue {
+			continue
+		}
+		if opts.Limit > 0 && int64(len(page.Items)) == opts.Limit {
+			page.Continue = page.Items[len(page.Items)-1].Name
+			break
+		}
+		page.Items = append(page.Items, pod)
+	}
+	return page, nil
+}
+
+func (f *pagedFakePods) Update(ctx context.Context, pod *v1.Pod, opts metav1.UpdateOptions) (*v1.Pod, error) {
+	if f.updateErr != nil {
+		return nil, f.updateErr
+	}
+	return f.fakePods.Update(ctx, pod, opts)
+}
+
+func newOperatorManagedLabelsPod(name string) *v1.Pod {
+	pod := builder.NewPod().WithLabels(
+		utils.NodeAffinityLabel, utils.NodeAffinityLabelValueSet,
+		utils.SchedulingGateLabel, utils.SchedulingGateLabelValueRemoved,
+		utils.MultiArchLabel, "",
+		utils.ArchLabelValue(utils.ArchitectureAmd64), "",
+		"app", "test").Build()
+	pod.Name = name
+	return pod
+}
+
+func TestOperatorManagedLabelsCleaner_Cleanup(t *testing.T) {
+	g := NewGomegaWithT(t)
+	unmanaged := builder.NewPod().WithLabels("app", "other").Build()
+	unmanaged.Name = "unmanaged"
+	pods := &pagedFakePods{fakePods: &fakePods{pods: map[string]*v1.Pod{
+		"pod-0":     newOperatorManagedLabelsPod("pod-0"),
+		"pod-1":     newOperatorManagedLabelsPod("pod-1"),
+		"pod-2":     newOperatorManagedLabelsPod("pod-2"),
+		"pod-3":     newOperatorManagedLabelsPod("pod-3"),
+		"pod-4":     newOperatorManagedLabelsPod("pod-4"),
+		"unmanaged": unmanaged.DeepCopy(),
+	}}}
+	c := &OperatorManagedLabelsCleaner{pods: pods, pageSize: 2}
+	g.Expect(c.Cleanup(ctx)).To(Succeed())
+	g.Expect(pods.lists).To(Equal(3), "the pods should be listed in pages")
+	for name, pod := range pods.pods {
+		if name == "unmanaged" {
+			g.Expect(pod).To(Equal(unmanaged), "the pods without operator-managed labels should not change")
+			continue
+		}
+		g.Expect(pod.Labels).To(Equal(map[string]string{"app": "test"}), "unexpected labels of the pod %s", name)
+	}
+
+	// The cleaned up pods are not listed again
+	pods.lists = 0
+	g.Expect(c.Cleanup(ctx)).To(Succeed())
+	g.Expect(pods.lists).To(Equal(1))
+}
+
+func TestOperatorManagedLabelsCleaner_Cleanup_UpdateError(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pods := &pagedFakePods{
+		fakePods: &fakePods{pods: map[string]*v1.Pod{
+			"pod-0": newOperatorManagedLabelsPod("pod-0"),
+		}},
+		updateErr: errors.New("update error"),
+	}
+	c := &OperatorManagedLabelsCleaner{pods: pods, pageSize: 2}
+	g.Expect(c.Cleanup(ctx)).NotTo(Succeed())
+	g.Expect(pods.pods["pod-0"].Labels).To(Equal(newOperatorManagedLabelsPod("pod-0").Labels))
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+const (
+	// imageInspectionCacheDataKey is the key of the ConfigMap's binaryData holding the gzipped JSON of the entries.
+	imageInspectionCacheDataKey = "cache.json.gz"
+	// maxImageInspectionCacheSize is the maximum size of the compressed entries stored in the ConfigMap.
+	maxImageInspectionCacheSize = 1 << 20 // 1 MiB
+)
+
+// cacheEntriesStore is implemented by the image.Facade.
+type cacheEntriesStore interface {
+	ExportCacheEntries() []image.CacheEntry
+	ImportCacheEntries(entries []image.CacheEntry) int
+}
+
+// PersistentCacheSyncer periodically stores the entries of the image inspection cache in a ConfigMap, and loads
+// them back in the cache when it starts, so that the operator restarts do not cause a burst of image inspections.
+type PersistentCacheSyncer struct {
+	configMaps corev1client.ConfigMapInterface
+	store      cacheEntriesStore
+	// interval is the period of the synchronization of the ConfigMap with the cache
+	interval time.Duration
+	// horizon is the maximum age of the entries loaded from the ConfigMap
+	horizon time.Duration
+	maxSize int
+	log     logr.Logger
+}
+
+func NewPersistentCacheSyncer(clientSet kubernetes.Interface, interval, horizon time.Duration) *PersistentCacheSyncer {
+	return &PersistentCacheSyncer{
+		configMaps: clientSet.CoreV1().ConfigMaps(utils.Namespace()),
+		store:      image.FacadeSingleton(),
+		interval:   interval,
+		horizon:    horizon,
+		maxSize:    maxImageInspectionCacheSize,
+	}
+}
+
+func (s *PersistentCacheSyncer) Start(ctx context.Context) error {
+	s.log = log.FromContext(ctx, "handler", "PersistentCacheSyncer", "kind", "ConfigMap [core/v1]",
+		"namespace", utils.Namespace(), "name", utils.ImageInspectionCacheConfigMapName)
+	s.log.Info("Starting the Persistent Cache Syncer")
+	if err := s.load(ctx); err != nil {
+		// The cache will be populated by the inspections
+		s.log.Error(err, "Unable to load the image inspection cache entries")
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Store the latest entries before terminating
+			saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.save(saveCtx); err != nil {
+				s.log.Error(err, "Unable to store the image inspection cache entries")
+			}
+			cancel()
+			s.log.Info("Stopping the Persistent Cache Syncer")
+			return nil
+		case <-ticker.C:
+			if err := s.save(ctx); err != nil {
+				s.log.Error(err, "Unable to store the image inspection cache entries")
+			}
+		}
+	}
+}
+
+// load imports in the cache the entries stored in the ConfigMap that are not older than the horizon.
+func (s *PersistentCacheSyncer) load(ctx context.Context) error {
+	cm, err := s.configMaps.Get(ctx, utils.ImageInspectionCacheConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		s.log.V(1).Info("No image inspection cache entries to load")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	entries, err := decodeCacheEntries(cm.BinaryData[imageInspectionCacheDataKey])
+	if err != nil {
+		return err
+	}
+	oldest := time.Now().Add(-s.horizon)
+	entries = slices.DeleteFunc(entries, func(entry image.CacheEntry) bool {
+		return entry.AddedAt.Before(oldest)
+	})
+	imported := s.store.ImportCacheEntries(entries)
+	s.log.Info("Loaded the image inspection cache entries", "imported", imported)
+	return nil
+}
+
+// save stores the entries of the cache in the ConfigMap, creating it if it does not exist.
+func (s *PersistentCacheSyncer) save(ctx context.Context) error {
+	data, stored, err := encodeCacheEntries(s.store.ExportCacheEntries(), s.maxSize)
+	if err != nil {
+		return err
+	}
+	cm, err := s.configMaps.Get(ctx, utils.ImageInspectionCacheConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = s.configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      utils.ImageInspectionCacheConfigMapName,
+				Namespace: utils.Namespace(),
+			},
+			BinaryData: map[string][]byte{
+				imageInspectionCacheDataKey: data,
+			},
+		}, metav1.CreateOptions{})
+	} else if err == nil {
+		cm.BinaryData = map[string][]byte{
+			imageInspectionCacheDataKey: data,
+		}
+		_, err = s.configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	s.log.V(2).Info("Stored the image inspection cache entries", "stored", stored, "size", len(data))
+	return nil
+}
+
+// encodeCacheEntries returns the gzipped JSON of the entries and the number of entries it contains.
+// The oldest entries are dropped until the result is not larger than maxSize.
+func encodeCacheEntries(entries []image.CacheEntry, maxSize int) ([]byte, int, error) {
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b image.CacheEntry) int {
+		return a.AddedAt.Compare(b.AddedAt)
+	})
+	for {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if err := json.NewEncoder(gz).Encode(entries); err != nil {
+			return nil, 0, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, 0, err
+		}
+		if buf.Len() <= maxSize || len(entries) == 0 {
+			return buf.Bytes(), len(entries), nil
+		}
+		// drop the oldest quarter of the entries
+		entries = entries[max(1, len(entries)/4):]
+	}
+}
+
+func decodeCacheEntries(data []byte) ([]image.CacheEntry, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	var entries []image.CacheEntry
+	if err := json.NewDecoder(io.LimitReader(gz, 16*maxImageInspectionCacheSize)).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
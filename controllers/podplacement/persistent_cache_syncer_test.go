@@ -0,0 +1,212 @@
+package podplacement
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+)
+
+// fakeConfigMaps stores the ConfigMaps in memory. Only the methods used by the PersistentCacheSyncer and the
+// CacheGenerationSyncer are implemented. As the API server does, it rejects the updates of stale ConfigMaps, and
+// filters the listed and watched ConfigMaps by their name field selector.
+type fakeConfigMaps struct {
+	corev1client.ConfigMapInterface
+	mutex      sync.Mutex
+	configMaps map[string]*corev1.ConfigMap
+	watchers   []fakeConfigMapWatcher
+}
+
+// fakeConfigMapWatcher is a watch opened on the fakeConfigMaps with the given field selector.
+type fakeConfigMapWatcher struct {
+	watcher       *watch.RaceFreeFakeWatcher
+	fieldSelector fields.Selector
+}
+
+func (f *fakeConfigMaps) List(_ context.Context, opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	list := &corev1.ConfigMapList{}
+	for _, cm := range f.configMaps {
+		if fieldSelector.Matches(fields.Set{"metadata.name": cm.Name}) {
+			list.Items = append(list.Items, *cm.DeepCopy())
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeConfigMaps) Watch(_ context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	watcher := watch.NewRaceFreeFake()
+	f.watchers = append(f.watchers, fakeConfigMapWatcher{watcher: watcher, fieldSelector: fieldSelector})
+	return watcher, nil
+}
+
+// notify sends the event of the given ConfigMap to the watchers whose field selector matches it.
+// The caller must hold the mutex.
+func (f *fakeConfigMaps) notify(eventType watch.EventType, cm *corev1.ConfigMap) {
+	for _, w := range f.watchers {
+		if !w.fieldSelector.Matches(fields.Set{"metadata.name": cm.Name}) {
+			continue
+		}
+		if eventType == watch.Added {
+			w.watcher.Add(cm.DeepCopy())
+		} else {
+			w.watcher.Modify(cm.DeepCopy())
+		}
+	}
+}
+
+func (f *fakeConfigMaps) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.ConfigMap, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	cm, ok := f.configMaps[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+	}
+	return cm.DeepCopy(), nil
+}
+
+func (f *fakeConfigMaps) Create(_ context.Context, cm *corev1.ConfigMap, _ metav1.CreateOptions) (*corev1.ConfigMap, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.configMaps[cm.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, cm.Name)
+	}
+	cm = cm.DeepCopy()
+	cm.ResourceVersion = "1"
+	f.configMaps[cm.Name] = cm
+	f.notify(watch.Added, cm)
+	return cm.DeepCopy(), nil
+}
+
+func (f *fakeConfigMaps) Update(_ context.Context, cm *corev1.ConfigMap, _ metav1.UpdateOptions) (*corev1.ConfigMap, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	current, ok := f.configMaps[cm.Name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, cm.Name)
+	}
+	if current.ResourceVersion != cm.ResourceVersion {
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, cm.Name,
+			fmt.Errorf("the object has been modified"))
+	}
+	resourceVersion, _ := strconv.Atoi(current.ResourceVersion)
+	cm = cm.DeepCopy()
+	cm.ResourceVersion = strconv.Itoa(resourceVersion + 1)
+	f.configMaps[cm.Name] = cm
+	f.notify(watch.Modified, cm)
+	return cm.DeepCopy(), nil
+}
+
+// fakeCacheEntriesStore is an in-memory cacheEntriesStore, standing for the cache of an operator instance.
+type fakeCacheEntriesStore struct {
+	entries map[string]image.CacheEntry
+}
+
+func (f *fakeCacheEntriesStore) ExportCacheEntries() []image.CacheEntry {
+	entries := make([]image.CacheEntry, 0, len(f.entries))
+	for _, entry := range f.entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (f *fakeCacheEntriesStore) ImportCacheEntries(entries []image.CacheEntry) int {
+	for _, entry := range entries {
+		f.entries[entry.Key] = entry
+	}
+	return len(entries)
+}
+
+func newTestPersistentCacheSyncer(configMaps *fakeConfigMaps, store *fakeCacheEntriesStore, maxSize int) *PersistentCacheSyncer {
+	return &PersistentCacheSyncer{
+		configMaps: configMaps,
+		store:      store,
+		interval:   time.Minute,
+		horizon:    time.Hour,
+		maxSize:    maxSize,
+		log:        logr.Discard(),
+	}
+}
+
+func TestPersistentCacheSyncer_Restart(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	configMaps := &fakeConfigMaps{configMaps: map[string]*corev1.ConfigMap{}}
+	before := &fakeCacheEntriesStore{entries: map[string]image.CacheEntry{
+		"fresh": {Key: "fresh", Architectures: []string{"amd64", "arm64"}, AddedAt: now.Add(-time.Minute)},
+		"old":   {Key: "old", Architectures: []string{"s390x"}, AddedAt: now.Add(-2 * time.Hour)},
+	}}
+	g.Expect(newTestPersistentCacheSyncer(configMaps, before, maxImageInspectionCacheSize).save(context.TODO())).To(Succeed())
+	// A second save updates the existing ConfigMap
+	before.entries["newer"] = image.CacheEntry{Key: "newer", Architectures: []string{"ppc64le"}, AddedAt: now}
+	g.Expect(newTestPersistentCacheSyncer(configMaps, before, maxImageInspectionCacheSize).save(context.TODO())).To(Succeed())
+
+	// The restarted operator loads the entries that are not older than the horizon
+	after := &fakeCacheEntriesStore{entries: map[string]image.CacheEntry{}}
+	g.Expect(newTestPersistentCacheSyncer(configMaps, after, maxImageInspectionCacheSize).load(context.TODO())).To(Succeed())
+	g.Expect(after.entries).To(Equal(map[string]image.CacheEntry{
+		"fresh": before.entries["fresh"],
+		"newer": before.entries["newer"],
+	}))
+}
+
+func TestPersistentCacheSyncer_LoadMissingConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+	store := &fakeCacheEntriesStore{entries: map[string]image.CacheEntry{}}
+	syncer := newTestPersistentCacheSyncer(&fakeConfigMaps{configMaps: map[string]*corev1.ConfigMap{}}, store,
+		maxImageInspectionCacheSize)
+	g.Expect(syncer.load(context.TODO())).To(Succeed())
+	g.Expect(store.entries).To(BeEmpty())
+}
+
+func TestEncodeCacheEntries_SizeBound(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+	entries := make([]image.CacheEntry, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		entries = append(entries, image.CacheEntry{
+			Key:           fmt.Sprintf("%032x", i*7919),
+			Architectures: []string{"amd64"},
+			AddedAt:       now.Add(time.Duration(i) * time.Second),
+		})
+	}
+	const maxSize = 4 * 1024
+	data, stored, err := encodeCacheEntries(entries, maxSize)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(len(data)).To(BeNumerically("<=", maxSize))
+	g.Expect(stored).To(BeNumerically(">", 0))
+	g.Expect(stored).To(BeNumerically("<", len(entries)))
+
+	decoded, err := decodeCacheEntries(data)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(decoded).To(HaveLen(stored))
+	// The newest entries are kept
+	g.Expect(decoded[len(decoded)-1].Key).To(Equal(entries[len(entries)-1].Key))
+}
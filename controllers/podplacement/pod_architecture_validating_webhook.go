@@ -0,0 +1,136 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// [disabled:operator]kubebuilder:webhook:path=/validate-pod-architecture,mutating=false,sideEffects=None,admissionReviewVersions=v1,failurePolicy=ignore,groups="",resources=pods,verbs=create,versions=v1,name=pod-architecture-validation.multiarch.openshift.io
+
+// PodArchitectureValidatingWebHook rejects the pods whose images do not support any common architecture.
+// It is registered through a ValidatingWebhookConfiguration, so it runs after the PodSchedulingGateMutatingWebHook
+// and receives the already-mutated pod. It is enabled by the EnforceArchitectureCompatibility field of the
+// ClusterPodPlacementConfig.
+// The pods whose user-defined constraints on the kubernetes.io/arch label conflict with the architectures supported
+// by their images are admitted with a warning: the operator does not manage their architecture constraints.
+// The webhook has no access to the secrets: the images are inspected without the pull secrets of the pod, and the
+// pods whose images cannot be inspected that way are admitted, leaving them to the pod placement controller.
+type PodArchitectureValidatingWebHook struct {
+	imageInspector image.ICache
+	// maxConcurrentInspections is the maximum number of distinct images of a pod inspected concurrently.
+	maxConcurrentInspections int
+	decoder                  admission.Decoder
+	once                     sync.Once
+	scheme                   *runtime.Scheme
+}
+
+func (a *PodArchitectureValidatingWebHook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	a.once.Do(func() {
+		a.decoder = admission.NewDecoder(a.scheme)
+	})
+	// The image inspection metrics are the ones of the pod placement controller
+	metrics.InitPodPlacementControllerMetrics()
+	pod := &Pod{
+		ctx:                      ctx,
+		imageInspector:           a.imageInspector,
+		searchRegistries:         image.UnqualifiedSearchRegistries(ctx),
+		maxConcurrentInspections: a.maxConcurrentInspections,
+	}
+	err := a.decoder.Decode(req, &pod.Pod)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	log := ctrllog.FromContext(ctx).WithValues("namespace", pod.Namespace, "name", pod.Name)
+
+	cppc := clusterpodplacementconfig.GetClusterPodPlacementConfig()
+	pod.universalImages = universalImagesOf(cppc)
+	// The pods setting their own architecture constraints are validated against the architectures of their images.
+	reason := pod.ignoreReason(cppc)
+	if reason != "" && reason != IgnoreReasonArchitectureConstraintsSet {
+		log.V(3).Info("Ignoring the pod", "reason", reason)
+		return admission.Allowed(fmt.Sprintf("the pod is ignored: %s", reason))
+	}
+
+	requirement, _, err := pod.getArchitecturePredicate(nil)
+	if err != nil {
+		// The pod placement controller will retry the inspection, and eventually remove the scheduling gate:
+		// we cannot reject the pod on errors that may be transient.
+		log.V(1).Info("Unable to inspect the images of the pod, allowing it", "error", err)
+		return admission.Allowed("unable to inspect the images of the pod")
+	}
+	if requirement.Key != utils.NoSupportedArchLabel {
+		warnings := pod.architectureConstraintsConflicts(requirement.Values)
+		if len(warnings) > 0 {
+			log.V(2).Info("Warning about the pod as its architecture constraints conflict with its images",
+				"warnings", warnings)
+		}
+		return admission.Allowed("").WithWarnings(warnings...)
+	}
+	if reason != "" {
+		log.V(3).Info("Ignoring the pod", "reason", reason)
+		return admission.Allowed(fmt.Sprintf("the pod is ignored: %s", reason))
+	}
+	log.V(2).Info("Denying the pod as its images do not support any common architecture")
+	return admission.Denied(conflictingImagesMessage(ctx, pod, nil))
+}
+
+// conflictingImagesMessage returns a human-readable message listing the images of the pod and the architectures
+// they support.
+func conflictingImagesMessage(ctx context.Context, pod *Pod, pullSecretDataList [][]byte) string {
+	descriptions := map[string]string{}
+	for _, candidates := range imageCandidates(pod.imagesNamesSet()) {
+		// The images were already inspected by getArchitecturePredicate, so this is expected to hit the cache.
+		architectures, err := inspectCandidates(ctx, pod.imageInspector, candidates, pullSecretDataList)
+		description := "unknown"
+		if err == nil {
+			description = strings.Join(sets.List(architectures), ", ")
+		}
+		descriptions[candidates[0].displayName()] = description
+	}
+	imageArchitectures := make([]string, 0, len(descriptions))
+	for _, imageName := range slices.Sorted(maps.Keys(descriptions)) {
+		imageArchitectures = append(imageArchitectures, fmt.Sprintf("%s (%s)", imageName, descriptions[imageName]))
+	}
+	return fmt.Sprintf("the images of the pod do not support any common architecture: %s",
+		strings.Join(imageArchitectures, "; "))
+}
+
+func NewPodArchitectureValidatingWebHook(imageInspector image.ICache, maxConcurrentInspections int,
+	scheme *runtime.Scheme) *PodArchitectureValidatingWebHook {
+	return &PodArchitectureValidatingWebHook{
+		imageInspector:           imageInspector,
+		maxConcurrentInspections: maxConcurrentInspections,
+		scheme:                   scheme,
+	}
+}
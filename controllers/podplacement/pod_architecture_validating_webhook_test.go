@@ -0,0 +1,146 @@
+package podplacement
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func TestPodArchitectureValidatingWebHook_Handle(t *testing.T) {
+	tests := []struct {
+		name         string
+		pod          *builder.PodBuilder
+		wantAllowed  bool
+		wantMessage  string
+		wantWarnings []string
+	}{
+		{
+			name: "pod with compatible images is allowed",
+			pod: builder.NewPod().WithContainersImages(fake.MultiArchImage, fake.SingleArchAmd64Image).
+				WithNamespace("test-namespace"),
+			wantAllowed: true,
+		},
+		{
+			name: "pod with conflicting images is denied",
+			pod: builder.NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).
+				WithNamespace("test-namespace"),
+			wantAllowed: false,
+			wantMessage: "the images of the pod do not support any common architecture: " +
+				fake.SingleArchAmd64Image + " (amd64); " + fake.SingleArchArm64Image + " (arm64)",
+		},
+		{
+			name: "pod with conflicting images in an excluded namespace is allowed",
+			pod: builder.NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).
+				WithNamespace("kube-system"),
+			wantAllowed: true,
+			wantMessage: "the pod is ignored: " + IgnoreReasonKubeNamespace,
+		},
+		{
+			name: "pod with a nodeSelector conflicting with its images is allowed with a warning",
+			pod: builder.NewPod().WithContainersImages(fake.SingleArchAmd64Image).
+				WithNodeSelectors(utils.ArchLabel, utils.ArchitectureArm64).WithNamespace("test-namespace"),
+			wantAllowed: true,
+			wantWarnings: []string{"the nodeSelector kubernetes.io/arch=arm64 conflicts with the architectures " +
+				"supported by the images of the pod (amd64): the pod will not be schedulable"},
+		},
+		{
+			name: "pod with a node affinity conflicting with its images is allowed with a warning",
+			pod: builder.NewPod().WithContainersImages(fake.SingleArchAmd64Image).
+				WithNodeSelectorTermsMatchExpressions([]corev1.NodeSelectorRequirement{
+					*builder.NewNodeSelectorRequirement().WithKeyAndValues(utils.ArchLabel, corev1.NodeSelectorOpIn,
+						utils.ArchitectureArm64, utils.ArchitecturePpc64le).Build(),
+				}).WithNamespace("test-namespace"),
+			wantAllowed: true,
+			wantWarnings: []string{"the required node affinity on the kubernetes.io/arch label conflicts with the " +
+				"architectures supported by the images of the pod (amd64): the pod will not be schedulable"},
+		},
+		{
+			name: "pod with architecture constraints matching its images is allowed without warnings",
+			pod: builder.NewPod().WithContainersImages(fake.MultiArchImage).
+				WithNodeSelectors(utils.ArchLabel, utils.ArchitectureArm64).
+				WithNodeSelectorTermsMatchExpressions([]corev1.NodeSelectorRequirement{
+					*builder.NewNodeSelectorRequirement().WithKeyAndValues(utils.ArchLabel, corev1.NodeSelectorOpIn,
+						utils.ArchitectureArm64).Build(),
+				}).WithNamespace("test-namespace"),
+			wantAllowed: true,
+		},
+		{
+			name: "pod with architecture constraints and conflicting images is allowed",
+			pod: builder.NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).
+				WithNodeSelectors(utils.ArchLabel, utils.ArchitectureArm64).WithNamespace("test-namespace"),
+			wantAllowed: true,
+			wantMessage: "the pod is ignored: " + IgnoreReasonArchitectureConstraintsSet,
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			a := NewPodArchitectureValidatingWebHook(fake.FacadeSingleton(), 0, scheme.Scheme)
+
+			raw, err := json.Marshal(tt.pod.Build())
+			g.Expect(err).NotTo(HaveOccurred())
+			resp := a.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			g.Expect(resp.Allowed).To(Equal(tt.wantAllowed))
+			if !tt.wantAllowed {
+				g.Expect(resp.Result.Code).To(BeEquivalentTo(http.StatusForbidden))
+			}
+			if tt.wantMessage != "" {
+				g.Expect(resp.Result.Message).To(Equal(tt.wantMessage))
+			}
+			g.Expect(resp.Warnings).To(Equal(tt.wantWarnings))
+		})
+	}
+}
+
+func BenchmarkWebhookHandle_CachedImage(b *testing.B) {
+	metrics.InitPodPlacementControllerMetrics()
+	a := NewPodArchitectureValidatingWebHook(fake.NewFacade(), 0, scheme.Scheme)
+	req := newAdmissionRequest(b, builder.NewPod().WithContainersImages(fake.MultiArchImage, fake.SingleArchAmd64Image).
+		WithNamespace("test-namespace"))
+	// Warm up the cache
+	if resp := a.Handle(context.TODO(), req); !resp.Allowed {
+		b.Fatalf("the pod was not allowed: %v", resp.Result)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if resp := a.Handle(context.TODO(), req); !resp.Allowed {
+			b.Fatalf("the pod was not allowed: %v", resp.Result)
+		}
+	}
+}
+
+func BenchmarkWebhookHandle_UncachedImage(b *testing.B) {
+	metrics.InitPodPlacementControllerMetrics()
+	a := NewPodArchitectureValidatingWebHook(fake.NewFacade(), 0, scheme.Scheme)
+	// The imagePullPolicy Always skips the cache: every request inspects the images.
+	req := newAdmissionRequest(b, builder.NewPod().WithContainerImagePullAlways(fake.MultiArchImage).
+		WithContainerImagePullAlways(fake.SingleArchAmd64Image).WithNamespace("test-namespace"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if resp := a.Handle(context.TODO(), req); !resp.Allowed {
+			b.Fatalf("the pod was not allowed: %v", resp.Result)
+		}
+	}
+}
@@ -18,16 +18,28 @@ package podplacement
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"golang.org/x/sync/singleflight"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common/plugins"
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
 	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
 	"github.com/openshift/multiarch-tuning-operator/pkg/image"
@@ -35,21 +47,76 @@ import (
 )
 
 var (
-	// imageInspectionCache is the facade singleton used to inspect images. It is defined here to facilitate testing.
-	imageInspectionCache image.ICache = image.FacadeSingleton()
+	// tracer is the tracer used to instrument the pod placement operand. It is defined here to facilitate testing.
+	tracer = otel.Tracer("github.com/openshift/multiarch-tuning-operator/controllers/podplacement")
+	// inspectionGroup deduplicates the concurrent inspections of the same image with the same pull secrets, e.g.,
+	// by the pods of a Deployment scaled up at once, or by the pods with different images sharing a sidecar image.
+	inspectionGroup singleflight.Group
+
+	// ErrArchMatchFieldConflict is returned when a node selector term of the pod has a matchFields entry on the
+	// keys of the architecture requirement, see validateNoArchMatchField.
+	ErrArchMatchFieldConflict = errors.New("a node selector term has a matchFields entry on the architecture label")
 )
 
 const MaxRetryCount = 5
 
+// sharedInspectionTimeout bounds the inspections shared by the concurrent callers of inspectImage, as they do not run
+// on the context of any of them.
+const sharedInspectionTimeout = 2 * time.Minute
+
+// The reasons why a pod is ignored by the operator. See Pod.ignoreReason.
+const (
+	IgnoreReasonOperatorNamespace          = "operator-namespace"
+	IgnoreReasonKubeNamespace              = "kube-namespace"
+	IgnoreReasonNodeNameSet                = "node-name-set"
+	IgnoreReasonControlPlaneNodeSelector   = "control-plane-node-selector"
+	IgnoreReasonDaemonSet                  = "daemonset"
+	IgnoreReasonArchitectureConstraintsSet = "architecture-constraints-set"
+	IgnoreReasonExcludedByLabelSelector    = "excluded-by-label-selector"
+	IgnoreReasonVirtualNode                = "virtual-node"
+	IgnoreReasonIgnoreAnnotation           = "ignore-annotation"
+	IgnoreReasonTrustedOwnerKind           = "trusted-owner-kind"
+	IgnoreReasonExcludedOwnerKind          = "excluded-owner-kind"
+	IgnoreReasonHostNetwork                = "host-network"
+	IgnoreReasonGateInjectionDisabled      = "gate-injection-disabled"
+)
+
 type containerImage struct {
 	imageName string
+	// os is the operating system of the platforms of the image to consider
+	os        string
 	skipCache bool
+	// shortName is the image of the container, if imageName is one of the fully qualified names it resolves to. The
+	// candidates of a short name are alternatives: the first one, by priority, to be inspected successfully is used.
+	shortName string
+	priority  int
 }
 
 type Pod struct {
 	corev1.Pod
 	ctx      context.Context
 	recorder record.EventRecorder
+	// imageInspector inspects the images of the pod. It is injected by the components processing the pod, e.g., to
+	// use a fake in the tests.
+	imageInspector image.ICache
+	// searchRegistries are the registries the short names of the images of the pod resolve to, in order. The short
+	// names are not resolved, i.e., they default to docker.io, if it is empty.
+	searchRegistries []string
+	// universalImages are the patterns of the images supporting all the architectures, which are not inspected. See
+	// the UniversalImages of the ClusterPodPlacementConfig.
+	universalImages []string
+	// disableArchitectureLabels prevents the pod from being labeled with the architectures it supports. See the
+	// EnableArchitectureLabels of the ClusterPodPlacementConfig.
+	disableArchitectureLabels bool
+	// clusterArchitectures are the architectures of the nodes the inspected architectures of the pod are trimmed to.
+	// They are not trimmed if it is nil. See the TrimToClusterArchitectures of the ClusterPodPlacementConfig.
+	clusterArchitectures sets.Set[string]
+	// requireArchLabelOnNodes lets every node selector term of the required node affinity of the pod require the
+	// utils.ArchLabel label. See the RequireArchLabelOnNodes of the ClusterPodPlacementConfig.
+	requireArchLabelOnNodes bool
+	// maxConcurrentInspections is the maximum number of distinct images of the pod inspected concurrently. The
+	// default of the MaxConcurrentInspections of the ClusterPodPlacementConfig applies if it is lower than 1.
+	maxConcurrentInspections int
 }
 
 func (pod *Pod) GetPodImagePullSecrets() []string {
@@ -65,12 +132,13 @@ func (pod *Pod) GetPodImagePullSecrets() []string {
 }
 
 func (pod *Pod) HasSchedulingGate() bool {
-	if pod.Spec.SchedulingGates == nil {
-		// If the schedulingGates array is nil, we return false
-		return false
-	}
-	for _, condition := range pod.Spec.SchedulingGates {
-		if condition.Name == utils.SchedulingGateName {
+	return pod.hasSchedulingGate(utils.GetSchedulingGateName())
+}
+
+// hasSchedulingGate returns true if the pod has the scheduling gate with the given name.
+func (pod *Pod) hasSchedulingGate(name string) bool {
+	for _, schedulingGate := range pod.Spec.SchedulingGates {
+		if schedulingGate.Name == name {
 			return true
 		}
 	}
@@ -83,37 +151,124 @@ func (pod *Pod) RemoveSchedulingGate() {
 		// If the schedulingGates array is nil, we return
 		return
 	}
+	if pod.HasSchedulingGate() {
+		pod.ensureProcessingDurationAnnotation(time.Now())
+	}
+	pod.removeSchedulingGate(utils.GetSchedulingGateName())
+	// The scheduling gate is removed. We also add a label to the pod to indicate that the scheduling gate was removed
+	// and this pod was processed by the operator. That's useful for testing and debugging, but also gives the user
+	// an indication that the pod was processed by the operator.
+	pod.ensureLabel(utils.SchedulingGateLabel, utils.SchedulingGateLabelValueRemoved)
+}
+
+// ensureProcessingDurationAnnotation sets the utils.ProcessingDurationAnnotation annotation to the seconds elapsed from
+// the gate injection to the given removal time. The gate is injected at the pod admission, so the creation timestamp of
+// the pod is used as the injection time. The annotation is sent in the same update as the gate removal.
+func (pod *Pod) ensureProcessingDurationAnnotation(removalTime time.Time) {
+	if pod.CreationTimestamp.IsZero() {
+		return
+	}
+	// The creation timestamp has a resolution of one second and the clocks of the API server and the operand may
+	// drift: the duration is clamped to zero.
+	duration := max(removalTime.Sub(pod.CreationTimestamp.Time), 0)
+	pod.ensureAnnotation(utils.ProcessingDurationAnnotation, strconv.FormatFloat(duration.Seconds(), 'f', 3, 64))
+}
+
+// removeSchedulingGate removes the scheduling gate with the given name from the pod, leaving the other ones untouched.
+func (pod *Pod) removeSchedulingGate(name string) {
 	filtered := make([]corev1.PodSchedulingGate, 0, len(pod.Spec.SchedulingGates))
 	for _, schedulingGate := range pod.Spec.SchedulingGates {
-		if schedulingGate.Name != utils.SchedulingGateName {
+		if schedulingGate.Name != name {
 			filtered = append(filtered, schedulingGate)
 		}
 	}
 	pod.Spec.SchedulingGates = filtered
-	// The scheduling gate is removed. We also add a label to the pod to indicate that the scheduling gate was removed
-	// and this pod was processed by the operator. That's useful for testing and debugging, but also gives the user
-	// an indication that the pod was processed by the operator.
-	pod.ensureLabel(utils.SchedulingGateLabel, utils.SchedulingGateLabelValueRemoved)
+}
+
+// isSchedulingGateCompleted returns true if the utils.CompletedSchedulingGatesAnnotation of the pod lists the
+// scheduling gate with the given name.
+func (pod *Pod) isSchedulingGateCompleted(name string) bool {
+	for _, completed := range strings.Split(pod.Annotations[utils.CompletedSchedulingGatesAnnotation], ",") {
+		if strings.TrimSpace(completed) == name {
+			return true
+		}
+	}
+	return false
 }
 
 // SetNodeAffinityArchRequirement wraps the logic to set the nodeAffinity for the pod.
 // It verifies first that no nodeSelector field is set for the kubernetes.io/arch label.
 // Then, it computes the intersection of the architectures supported by the images used by the pod via pod.getArchitecturePredicate.
 // Finally, it initializes the nodeAffinity for the pod and set it to the computed requirement via the pod.setRequiredArchNodeAffinity method.
+// The preferences of the utils.PreferredArchitectureAnnotation annotation, if any, are set alongside the requirement.
 func (pod *Pod) SetNodeAffinityArchRequirement(pullSecretDataList [][]byte) (bool, error) {
+	if pod.isArchNodeAffinitySetByOperator() {
+		// The pod was already processed, e.g., by a re-queued reconciliation: it is not changed again.
+		return false, nil
+	}
 	if pod.isNodeSelectorConfiguredForArchitecture() {
 		pod.publishIgnorePod()
 		return false, nil
 	}
-	requirement, err := pod.getArchitecturePredicate(pullSecretDataList)
+	requirement, architectures, err := pod.getArchitecturePredicate(pullSecretDataList)
 	if err != nil {
 		return false, err
 	}
 	pod.ensureNoLabel(utils.ImageInspectionErrorLabel)
+	pod.logNodeNameSelectorTerms()
+	if err := pod.setNodeAffinityArchRequirement(requirement, architectures); err != nil {
+		return false, err
+	}
+	if platforms := pod.preferredArchitectures(); platforms != nil {
+		pod.setPreferredArchNodeAffinity(platforms)
+	}
+	return true, nil
+}
+
+// DryRunSetNodeAffinityArchRequirement returns the requirement and the architectures SetNodeAffinityArchRequirement
+// would set, without mutating the pod or publishing events. The requirement is nil if the pod already configures
+// the architecture in its node selector or node affinity.
+func (pod *Pod) DryRunSetNodeAffinityArchRequirement(pullSecretDataList [][]byte) (*corev1.NodeSelectorRequirement, []string, error) {
+	// The checks label and annotate the pod: they run on a copy of the pod that does not publish events.
+	dryRun := *pod
+	dryRun.Pod = *pod.Pod.DeepCopy()
+	dryRun.recorder = nil
+	if dryRun.isNodeSelectorConfiguredForArchitecture() {
+		return nil, nil, nil
+	}
+	requirement, architectures, err := dryRun.getArchitecturePredicate(pullSecretDataList)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := dryRun.validateNoArchMatchFields(); err != nil {
+		return nil, nil, err
+	}
+	return &requirement, architectures, nil
+}
+
+// setNodeAffinityArchRequirement labels the pod for the given requirement and architectures, and adds the requirement
+// to its required node affinity. It returns an ErrArchMatchFieldConflict error, and leaves the pod unchanged, if a
+// node selector term of the required node affinity has a conflicting matchFields entry.
+func (pod *Pod) setNodeAffinityArchRequirement(requirement corev1.NodeSelectorRequirement, architectures []string) error {
+	if err := pod.validateNoArchMatchFields(); err != nil {
+		pod.publishEvent(corev1.EventTypeWarning, ArchitectureMatchFieldConflict,
+			ArchitectureMatchFieldConflictMsg+err.Error())
+		return err
+	}
+	if pod.hasRequiredArchNodeAffinity(requirement) {
+		// The pod was already processed, e.g., by a re-queued reconciliation: labels and events are not repeated.
+		return nil
+	}
 	if len(requirement.Values) == 0 {
 		pod.publishEvent(corev1.EventTypeNormal, NoSupportedArchitecturesFound, NoSupportedArchitecturesFoundMsg)
 	}
-	pod.ensureArchitectureLabels(requirement)
+	if !pod.disableArchitectureLabels {
+		pod.ensureArchitectureLabels(requirement)
+		pod.ensureArchitectureVariantLabels(architectures)
+		if slices.Contains(architectures, utils.ArchitectureWasm32) {
+			pod.ensureLabel(utils.WasmArchLabel, "")
+		}
+	}
 
 	if pod.Spec.Affinity == nil {
 		pod.Spec.Affinity = &corev1.Affinity{}
@@ -128,12 +283,89 @@ func (pod *Pod) SetNodeAffinityArchRequirement(pullSecretDataList [][]byte) (boo
 	}
 
 	pod.setRequiredArchNodeAffinity(requirement)
-	return true, nil
+	return nil
+}
+
+// validateNoArchMatchFields returns the error of validateNoArchMatchField for the first of the node selector terms of
+// the required node affinity of the pod that is not valid, if any.
+func (pod *Pod) validateNoArchMatchFields() error {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if err := validateNoArchMatchField(term); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNoArchMatchField returns an ErrArchMatchFieldConflict error if the term has a matchFields entry on the
+// keys of the architecture requirement. The architecture is a label of the nodes, not a field, so the operand only
+// sets the matchExpressions of the terms: such an entry would conflict with them.
+func validateNoArchMatchField(term corev1.NodeSelectorTerm) error {
+	for _, field := range term.MatchFields {
+		if field.Key == utils.ArchLabel || field.Key == utils.NoSupportedArchLabel {
+			return fmt.Errorf("%w: %s %s %v", ErrArchMatchFieldConflict, field.Key, field.Operator, field.Values)
+		}
+	}
+	return nil
+}
+
+// ApplyInspectionTimeoutFallback applies the InspectionTimeoutFallback of the given cppc to the pod whose images
+// inspection timed out with err. It returns nil if the node affinity of the pod is set to the fallback architectures,
+// or err if the fallback is v1beta1.InspectionTimeoutFallbackBlock, so that the inspection is retried. It returns an
+// ErrArchMatchFieldConflict error if the node affinity of the pod conflicts with the fallback architectures.
+func (pod *Pod) ApplyInspectionTimeoutFallback(cppc *v1beta1.ClusterPodPlacementConfig, err error) error {
+	fallback := v1beta1.InspectionTimeoutFallbackBlock
+	if cppc != nil {
+		fallback = cppc.Spec.GetInspectionTimeoutFallback()
+	}
+	var architectures []string
+	var message string
+	switch fallback {
+	case v1beta1.InspectionTimeoutFallbackAllowAll:
+		architectures = sets.List(utils.AllSupportedArchitecturesSet())
+		message = ImageInspectionTimeoutAllowAllMsg
+	case v1beta1.InspectionTimeoutFallbackAllowConfigured:
+		architectures = sets.List(sets.New(cppc.Spec.FallbackArchitectures...))
+		message = ImageInspectionTimeoutAllowConfiguredMsg
+	}
+	metrics.InspectionTimeoutFallbacks.WithLabelValues(string(fallback)).Inc()
+	if len(architectures) == 0 {
+		// The fallback is block, or no fallback architectures are configured
+		pod.publishEvent(corev1.EventTypeWarning, ImageInspectionTimeout, ImageInspectionTimeoutBlockMsg)
+		return err
+	}
+	pod.publishEvent(corev1.EventTypeWarning, ImageInspectionTimeout, message+strings.Join(architectures, ", "))
+	if err := pod.setNodeAffinityArchRequirement(architecturesPredicate(architectures), architectures); err != nil {
+		return err
+	}
+	pod.ensureLabel(utils.InspectionFallbackLabel, string(fallback))
+	return nil
+}
+
+// setArchRequirementOrFallback sets the architecture requirement of the pod, or the InspectionTimeoutFallback of the
+// cppc if the inspection of its images timed out. The error is only handled if no fallback applies, so that the
+// pods given the fallback are not reported as failed.
+func (pod *Pod) setArchRequirementOrFallback(cppc *v1beta1.ClusterPodPlacementConfig, pullSecretDataList [][]byte) error {
+	_, err := pod.SetNodeAffinityArchRequirement(pullSecretDataList)
+	if image.IsTimeoutError(err) {
+		err = pod.ApplyInspectionTimeoutFallback(cppc, err)
+	}
+	if !errors.Is(err, ErrArchMatchFieldConflict) {
+		pod.handleError(err, "Unable to set the node affinity for the pod.")
+	}
+	return err
 }
 
 // setRequiredArchNodeAffinity sets the node affinity for the pod to the given requirement based on the rules in
 // the sig-scheduling's KEP-3838: https://github.com/kubernetes/enhancements/tree/master/keps/sig-scheduling/3838-pod-mutable-scheduling-directives.
 func (pod *Pod) setRequiredArchNodeAffinity(requirement corev1.NodeSelectorRequirement) {
+	if pod.hasRequiredArchNodeAffinity(requirement) {
+		return
+	}
 	// the .requiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms are ORed
 	if len(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) == 0 {
 		// We create a new array of NodeSelectorTerm of length 1 so that we can always iterate it in the next.
@@ -163,6 +395,9 @@ func (pod *Pod) setRequiredArchNodeAffinity(requirement corev1.NodeSelectorRequi
 		if !skipMatchExpressionPatch {
 			nodeSelectorTerms[i].MatchExpressions = append(nodeSelectorTerms[i].MatchExpressions, requirement)
 		}
+		if pod.requireArchLabelOnNodes {
+			ensureArchLabelExists(&nodeSelectorTerms[i])
+		}
 	}
 	// if the nodeSelectorTerms were patched at least once, we set the nodeAffinity label to the set value, to keep
 	// track of the fact that the nodeAffinity was patched by the operator.
@@ -171,8 +406,91 @@ func (pod *Pod) setRequiredArchNodeAffinity(requirement corev1.NodeSelectorRequi
 		ArchitecturePredicateSetupMsg+fmt.Sprintf("{%s}", strings.Join(requirement.Values, ", ")))
 }
 
+// ensureArchLabelExists adds a matchExpression requiring the existence of the utils.ArchLabel label to the term,
+// unless one of its matchExpressions already excludes the nodes without the label, i.e., with the In or Exists
+// operator on it.
+func ensureArchLabelExists(term *corev1.NodeSelectorTerm) {
+	if slices.ContainsFunc(term.MatchExpressions, func(expression corev1.NodeSelectorRequirement) bool {
+		return expression.Key == utils.ArchLabel &&
+			(expression.Operator == corev1.NodeSelectorOpIn || expression.Operator == corev1.NodeSelectorOpExists)
+	}) {
+		return
+	}
+	term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+		Key:      utils.ArchLabel,
+		Operator: corev1.NodeSelectorOpExists,
+	})
+}
+
+// hasRequiredArchNodeAffinity returns true if every node selector term of the required node affinity of the pod
+// already has a matchExpression equal to the given requirement, i.e., with the same key, operator and values,
+// regardless of their order. Setting the requirement again would be a no-op.
+func (pod *Pod) hasRequiredArchNodeAffinity(requirement corev1.NodeSelectorRequirement) bool {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return false
+	}
+	nodeSelectorTerms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(nodeSelectorTerms) == 0 {
+		return false
+	}
+	for _, term := range nodeSelectorTerms {
+		if !slices.ContainsFunc(term.MatchExpressions, func(expression corev1.NodeSelectorRequirement) bool {
+			return equalNodeSelectorRequirements(expression, requirement)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// isArchNodeAffinitySetByOperator returns true if the operator labeled the pod for setting its required node affinity,
+// and every node selector term of the latter has a matchExpression for the utils.ArchLabel or the
+// utils.NoSupportedArchLabel label.
+func (pod *Pod) isArchNodeAffinitySetByOperator() bool {
+	if pod.Labels[utils.NodeAffinityLabel] != utils.NodeAffinityLabelValueSet {
+		return false
+	}
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return false
+	}
+	nodeSelectorTerms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(nodeSelectorTerms) == 0 {
+		return false
+	}
+	for _, term := range nodeSelectorTerms {
+		if !slices.ContainsFunc(term.MatchExpressions, func(expression corev1.NodeSelectorRequirement) bool {
+			return expression.Key == utils.ArchLabel || expression.Key == utils.NoSupportedArchLabel
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalNodeSelectorRequirements returns true if a and b have the same key, operator and values, regardless of the
+// order of the values.
+func equalNodeSelectorRequirements(a, b corev1.NodeSelectorRequirement) bool {
+	return a.Key == b.Key && a.Operator == b.Operator &&
+		slices.Equal(slices.Sorted(slices.Values(a.Values)), slices.Sorted(slices.Values(b.Values)))
+}
+
 // SetPreferredArchNodeAffinity sets the node affinity for the pod to the preferences given in the ClusterPodPlacementConfig.
+// The pods with a valid utils.PreferredArchitectureAnnotation annotation get their preferences from it instead, see
+// SetNodeAffinityArchRequirement.
 func (pod *Pod) SetPreferredArchNodeAffinity(cppc *v1beta1.ClusterPodPlacementConfig) {
+	if value, ok := pod.Annotations[utils.PreferredArchitectureAnnotation]; ok {
+		if _, err := parsePreferredArchitectures(value); err == nil {
+			return
+		}
+	}
+	pod.setPreferredArchNodeAffinity(cppc.Spec.Plugins.NodeAffinityScoring.Platforms)
+}
+
+// setPreferredArchNodeAffinity adds a preferred scheduling term to the node affinity of the pod for each of the
+// given architecture weights.
+func (pod *Pod) setPreferredArchNodeAffinity(platforms []plugins.NodeAffinityScoringPlatformTerm) {
 	// Prevent overriding of user-provided kubernetes.io/arch preferred affinities or overwriting previously set preferred affinity
 	if pod.isPreferredAffinityConfiguredForArchitecture() {
 		return
@@ -190,7 +508,7 @@ func (pod *Pod) SetPreferredArchNodeAffinity(cppc *v1beta1.ClusterPodPlacementCo
 		pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []corev1.PreferredSchedulingTerm{}
 	}
 
-	for _, nodeAffinityScoringPlatformTerm := range cppc.Spec.Plugins.NodeAffinityScoring.Platforms {
+	for _, nodeAffinityScoringPlatformTerm := range platforms {
 		preferredSchedulingTerm := corev1.PreferredSchedulingTerm{
 			Weight: nodeAffinityScoringPlatformTerm.Weight,
 			Preference: corev1.NodeSelectorTerm{
@@ -213,70 +531,428 @@ func (pod *Pod) SetPreferredArchNodeAffinity(cppc *v1beta1.ClusterPodPlacementCo
 	pod.publishEvent(corev1.EventTypeNormal, ArchitectureAwareNodeAffinitySet, ArchitecturePreferredPredicateSetupMsg)
 }
 
-func (pod *Pod) getArchitecturePredicate(pullSecretDataList [][]byte) (corev1.NodeSelectorRequirement, error) {
+// preferredArchitectures returns the architecture weights set by the utils.PreferredArchitectureAnnotation
+// annotation of the pod, or nil if the annotation is not set. If the annotation is not valid, a warning event is
+// published and nil is returned.
+func (pod *Pod) preferredArchitectures() []plugins.NodeAffinityScoringPlatformTerm {
+	value, ok := pod.Annotations[utils.PreferredArchitectureAnnotation]
+	if !ok {
+		return nil
+	}
+	platforms, err := parsePreferredArchitectures(value)
+	if err != nil {
+		pod.publishEvent(corev1.EventTypeWarning, ArchitecturePreferenceInvalid,
+			ArchitecturePreferenceInvalidMsg+err.Error())
+		return nil
+	}
+	return platforms
+}
+
+// parsePreferredArchitectures parses the comma-separated architecture:weight pairs of the
+// utils.PreferredArchitectureAnnotation annotation, e.g., arm64:100,amd64:50. The architectures must be supported and
+// not repeated, and the weights must range from 1 to 100.
+func parsePreferredArchitectures(value string) ([]plugins.NodeAffinityScoringPlatformTerm, error) {
+	seen := sets.New[string]()
+	platforms := make([]plugins.NodeAffinityScoringPlatformTerm, 0, strings.Count(value, ",")+1)
+	for _, pair := range strings.Split(value, ",") {
+		architecture, weight, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found {
+			return nil, fmt.Errorf("%q is not an architecture:weight pair", pair)
+		}
+		architecture = strings.TrimSpace(architecture)
+		if !utils.AllSupportedArchitecturesSet().Has(architecture) {
+			return nil, fmt.Errorf("unsupported architecture %q", architecture)
+		}
+		if seen.Has(architecture) {
+			return nil, fmt.Errorf("duplicate architecture %q", architecture)
+		}
+		seen.Insert(architecture)
+		w, err := strconv.ParseInt(strings.TrimSpace(weight), 10, 32)
+		if err != nil || w < 1 || w > 100 {
+			return nil, fmt.Errorf("the weight of the architecture %q must be an integer from 1 to 100, got %q",
+				architecture, weight)
+		}
+		platforms = append(platforms, plugins.NodeAffinityScoringPlatformTerm{
+			Architecture: architecture,
+			Weight:       int32(w),
+		})
+	}
+	return platforms, nil
+}
+
+// architecturesOverride returns the sorted architectures set by the utils.ArchitectureOverrideAnnotation annotation
+// of the pod, or nil if the annotation is not set. If the annotation includes values that are not in the
+// canonical architecture set, a warning event is published and nil is returned, so that the images are inspected.
+func (pod *Pod) architecturesOverride() []string {
+	value, ok := pod.Annotations[utils.ArchitectureOverrideAnnotation]
+	if !ok {
+		return nil
+	}
+	architectures := sets.New[string]()
+	for _, architecture := range strings.Split(value, ",") {
+		architectures.Insert(strings.TrimSpace(architecture))
+	}
+	if invalid := architectures.Difference(utils.AllSupportedArchitecturesSet()); invalid.Len() > 0 {
+		metrics.ArchitectureOverrides.WithLabelValues("invalid").Inc()
+		pod.publishEvent(corev1.EventTypeWarning, ArchitectureOverrideInvalid,
+			ArchitectureOverrideInvalidMsg+fmt.Sprintf("%q", sets.List(invalid)))
+		return nil
+	}
+	metrics.ArchitectureOverrides.WithLabelValues("valid").Inc()
+	return sets.List(architectures)
+}
+
+// getArchitecturePredicate returns the NodeSelectorRequirement for the architectures supported by the images
+// of the pod, along with these architectures qualified by their variant, if any (e.g., arm/v7).
+// If the pod sets the utils.ArchitectureOverrideAnnotation annotation, its architectures are used and the images
+// are not inspected.
+// The kubernetes.io/arch label of the nodes has no variant: the requirement values are the architectures
+// without variant.
+// The predicates of the inspected images are cached in the architecturePredicateCache, so that the pods with the same
+// images do not compute the intersection of their architectures again.
+func (pod *Pod) getArchitecturePredicate(pullSecretDataList [][]byte) (corev1.NodeSelectorRequirement, []string, error) {
+	architectures := pod.architecturesOverride()
+	if architectures != nil {
+		return architecturesPredicate(architectures), architectures, nil
+	}
+	key, cacheable := pod.architecturePredicateKey(pod.imagesNamesSet(), pullSecretDataList)
+	if cacheable {
+		if requirement, architectures, ok := cachedArchitecturePredicate(key); ok {
+			ctrllog.FromContext(pod.ctx).V(3).Info("Architecture predicate cache hit", "architectures", architectures)
+			pod.ensureArchitectureAnnotation(pod.inspectedImages(), time.Now())
+			return requirement, architectures, nil
+		}
+	}
 	architectures, err := pod.intersectImagesArchitecture(pullSecretDataList)
 	// if an error occurs, we return an empty NodeSelectorRequirement and the error.
 	if err != nil {
-		return corev1.NodeSelectorRequirement{}, err
+		return corev1.NodeSelectorRequirement{}, nil, err
 	}
+	architectures = trimToClusterArchitectures(architectures, pod.clusterArchitectures)
+	pod.ensureArchitectureAnnotation(pod.inspectedImages(), time.Now())
+	requirement := architecturesPredicate(architectures)
+	if cacheable {
+		cacheArchitecturePredicate(key, requirement, architectures)
+	}
+	return requirement, architectures, nil
+}
 
+// architecturesPredicate returns the NodeSelectorRequirement for the given architectures. It matches the
+// utils.NoSupportedArchLabel if the architectures are empty.
+func architecturesPredicate(architectures []string) corev1.NodeSelectorRequirement {
 	if len(architectures) == 0 {
 		return corev1.NodeSelectorRequirement{
 			Key:      utils.NoSupportedArchLabel,
 			Operator: corev1.NodeSelectorOpExists,
-		}, nil
+		}
+	}
+	values := sets.New[string]()
+	for _, architecture := range architectures {
+		values.Insert(utils.ArchitectureWithoutVariant(architecture))
 	}
 	return corev1.NodeSelectorRequirement{
 		Key:      utils.ArchLabel,
 		Operator: corev1.NodeSelectorOpIn,
-		Values:   architectures,
-	}, nil
+		Values:   sets.List(values),
+	}
 }
 
+// imagesNamesSet returns the set of the images to inspect. The universal images and the ones of the containers with
+// imagePullPolicy Never are not inspected. The cache is skipped for the images of the containers with imagePullPolicy
+// Always, or for all of them if the pod has the utils.ForceRefreshAnnotation annotation.
 func (pod *Pod) imagesNamesSet() sets.Set[containerImage] {
+	forceRefresh := pod.Annotations[utils.ForceRefreshAnnotation] == "true"
+	operatingSystem := pod.operatingSystem()
 	imageNamesSet := sets.New[containerImage]()
 	for _, container := range append(pod.Spec.Containers, pod.Spec.InitContainers...) {
-		imageNamesSet.Insert(containerImage{
-			imageName: fmt.Sprintf("//%s", container.Image),
-			skipCache: container.ImagePullPolicy == corev1.PullAlways,
-		})
+		if container.ImagePullPolicy == corev1.PullNever {
+			// The image is expected to be present on the node: it cannot be inspected from a registry
+			// and does not constrain the architectures of the pod.
+			continue
+		}
+		if isUniversalImage(container.Image, pod.universalImages) {
+			// The image supports all the architectures: it does not constrain the architectures of the pod.
+			continue
+		}
+		for priority, imageName := range resolveUnqualifiedImage(container.Image, pod.searchRegistries) {
+			imageContainer := containerImage{
+				imageName: fmt.Sprintf("//%s", imageName),
+				os:        operatingSystem,
+				skipCache: forceRefresh || container.ImagePullPolicy == corev1.PullAlways,
+			}
+			if imageName != container.Image {
+				imageContainer.shortName = container.Image
+				imageContainer.priority = priority
+			}
+			imageNamesSet.Insert(imageContainer)
+		}
 	}
 	return imageNamesSet
 }
 
+// isUniversalImage returns true if the image matches, exactly or as a glob pattern, one of the given universal
+// images.
+func isUniversalImage(image string, universalImages []string) bool {
+	for _, pattern := range universalImages {
+		// The patterns are validated by the ClusterPodPlacementConfig webhook: the malformed ones do not match.
+		if matched, _ := path.Match(pattern, image); matched || pattern == image {
+			return true
+		}
+	}
+	return false
+}
+
+// universalImagesOf returns the UniversalImages of the given cppc, or nil if it is nil.
+func universalImagesOf(cppc *v1beta1.ClusterPodPlacementConfig) []string {
+	if cppc == nil {
+		return nil
+	}
+	return cppc.Spec.UniversalImages
+}
+
+// resolveUnqualifiedImage returns the fully qualified names the image resolves to, in the order of the search
+// registries. The image is returned as is if it is fully qualified, i.e., its first path component is a registry
+// host, or if there are no search registries.
+func resolveUnqualifiedImage(name string, searchRegistries []string) []string {
+	if len(searchRegistries) == 0 {
+		return []string{name}
+	}
+	if domain, _, found := strings.Cut(name, "/"); found &&
+		(strings.ContainsAny(domain, ".:") || domain == "localhost") {
+		return []string{name}
+	}
+	candidates := make([]string, 0, len(searchRegistries))
+	for _, registry := range searchRegistries {
+		candidates = append(candidates, path.Join(registry, name))
+	}
+	return candidates
+}
+
+// imageCandidates groups the images of the set by the short name they resolve, sorting the candidates of each
+// group by priority. The images not resolved from a short name are in a group of their own.
+func imageCandidates(imageNamesSet sets.Set[containerImage]) [][]containerImage {
+	type groupKey struct {
+		name      string
+		os        string
+		skipCache bool
+	}
+	groups := map[groupKey][]containerImage{}
+	for imageContainer := range imageNamesSet {
+		key := groupKey{name: imageContainer.imageName, os: imageContainer.os, skipCache: imageContainer.skipCache}
+		if imageContainer.shortName != "" {
+			key.name = imageContainer.shortName
+		}
+		groups[key] = append(groups[key], imageContainer)
+	}
+	result := make([][]containerImage, 0, len(groups))
+	for _, candidates := range groups {
+		slices.SortFunc(candidates, func(a, b containerImage) int {
+			return a.priority - b.priority
+		})
+		result = append(result, candidates)
+	}
+	return result
+}
+
+// displayName returns the name of the image as set in the pod spec.
+func (c containerImage) displayName() string {
+	if c.shortName != "" {
+		return c.shortName
+	}
+	return strings.TrimPrefix(c.imageName, "//")
+}
+
+// inspectImage returns the architectures supported by the image. The concurrent inspections of the same image share
+// the result of a single call to the image inspector. The shared call runs on a context detached from the
+// cancellation of the caller starting it, bounded by sharedInspectionTimeout, so that the cancellation of a caller
+// does not fail the inspections of the others: each caller stops waiting for the result when its own context is done.
+func inspectImage(ctx context.Context, imageInspector image.ICache, imageContainer containerImage,
+	pullSecretDataList [][]byte) (sets.Set[string], error) {
+	key := imageInspectionKey(imageContainer, pullSecretDataList)
+	resultChan := inspectionGroup.DoChan(key, func() (interface{}, error) {
+		sharedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedInspectionTimeout)
+		defer cancel()
+		return imageInspector.GetCompatibleArchitecturesSet(sharedCtx, imageContainer.imageName, imageContainer.os,
+			imageContainer.skipCache, pullSecretDataList)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		if result.Shared {
+			ctrllog.FromContext(ctx).V(3).Info("The inspection of the image was shared with concurrent requests",
+				"imageName", imageContainer.displayName())
+		}
+		return result.Val.(sets.Set[string]).Clone(), nil
+	}
+}
+
+// inspectCandidates returns the architectures supported by the first of the candidates to be inspected successfully.
+// If none is, the error of the first candidate is returned.
+func inspectCandidates(ctx context.Context, imageInspector image.ICache, candidates []containerImage,
+	pullSecretDataList [][]byte) (sets.Set[string], error) {
+	var firstErr error
+	for _, imageContainer := range candidates {
+		architectures, err := inspectImage(ctx, imageInspector, imageContainer, pullSecretDataList)
+		if err == nil {
+			return architectures, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// operatingSystem returns the operating system the pod targets: windows if the pod sets it in spec.os or in
+// the kubernetes.io/os node selector, linux otherwise.
+func (pod *Pod) operatingSystem() string {
+	if pod.Spec.OS != nil && pod.Spec.OS.Name == corev1.Windows {
+		return utils.OSWindows
+	}
+	if pod.Spec.NodeSelector[utils.OSLabel] == utils.OSWindows {
+		return utils.OSWindows
+	}
+	return utils.OSLinux
+}
+
 // inspect returns the list of supported architectures for the images used by the pod.
 // if an error occurs, it returns the error and a nil slice of strings.
 func (pod *Pod) intersectImagesArchitecture(pullSecretDataList [][]byte) (supportedArchitectures []string, err error) {
-	log := ctrllog.FromContext(pod.ctx)
+	ctx, span := tracer.Start(pod.ctx, "intersectImagesArchitecture")
+	defer span.End()
+	log := ctrllog.FromContext(ctx)
 	imageNamesSet := pod.imagesNamesSet()
 	log.V(1).Info("Images list for pod", "imageNamesSet", fmt.Sprintf("%+v", imageNamesSet))
-	// https://github.com/containers/skopeo/blob/v1.11.1/cmd/skopeo/inspect.go#L72
-	// Iterate over the images, get their architectures and intersect (as in set intersection) them each other
-	var supportedArchitecturesSet sets.Set[string]
+	if imageNamesSet.Len() == 0 {
+		// All the containers use images with imagePullPolicy Never: no architecture constraint applies.
+		return sets.List(utils.AllSupportedArchitecturesSet()), nil
+	}
 	nowExternal := time.Now()
 	defer utils.HistogramObserve(nowExternal, metrics.TimeToInspectPodImages)
-	for imageContainer := range imageNamesSet {
-		log.V(3).Info("Checking image", "imageName", imageContainer.imageName,
-			"skipCache (imagePullPolicy==Always)", imageContainer.skipCache)
-		// We are collecting the time to inspect the image here to avoid implementing a metric in each of the
-		// cache implementations.
-		now := time.Now()
-		currentImageSupportedArchitectures, err := imageInspectionCache.GetCompatibleArchitecturesSet(pod.ctx,
-			imageContainer.imageName, imageContainer.skipCache, pullSecretDataList)
-		utils.HistogramObserve(now, metrics.TimeToInspectImage)
-		if err != nil {
-			log.V(1).Error(err, "Error inspecting the image", "imageName", imageContainer.imageName)
-			return nil, err
-		}
-		if supportedArchitecturesSet == nil {
-			supportedArchitecturesSet = currentImageSupportedArchitectures
-		} else {
-			supportedArchitecturesSet = supportedArchitecturesSet.Intersection(currentImageSupportedArchitectures)
+	return inspectImages(ctx, pod.imageInspector, imageNamesSet, pullSecretDataList, pod.maxConcurrentInspections)
+}
+
+// inspectImages returns the architectures supported by all the images, inspected with the given image.ICache. The
+// images are inspected concurrently, up to maxConcurrentInspections at a time, or the default of the
+// MaxConcurrentInspections of the ClusterPodPlacementConfig if it is lower than 1.
+func inspectImages(ctx context.Context, imageInspector image.ICache, imageNamesSet sets.Set[containerImage],
+	pullSecretDataList [][]byte, maxConcurrentInspections int) ([]string, error) {
+	log := ctrllog.FromContext(ctx)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+		// https://github.com/containers/skopeo/blob/v1.11.1/cmd/skopeo/inspect.go#L72
+		// The architectures of the images are intersected (as in set intersection) each other
+		supportedArchitecturesSet sets.Set[string]
+		inspectionErr             error
+	)
+	if maxConcurrentInspections < 1 {
+		maxConcurrentInspections = int(v1beta1.DefaultMaxConcurrentInspections)
+	}
+	semaphore := make(chan struct{}, maxConcurrentInspections)
+	for _, candidates := range imageCandidates(imageNamesSet) {
+		semaphore <- struct{}{}
+		mu.Lock()
+		failed := inspectionErr != nil
+		mu.Unlock()
+		if failed {
+			// The intersection is not computed anymore: the remaining images are not inspected
+			<-semaphore
+			break
 		}
+		wg.Add(1)
+		go func(candidates []containerImage) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			log.V(3).Info("Checking image", "imageName", candidates[0].displayName(), "candidates", len(candidates),
+				"skipCache (imagePullPolicy==Always or force-refresh)", candidates[0].skipCache)
+			// We are collecting the time to inspect the image here to avoid implementing a metric in each of the
+			// cache implementations.
+			now := time.Now()
+			currentImageSupportedArchitectures, err := inspectCandidates(ctx, imageInspector, candidates,
+				pullSecretDataList)
+			utils.HistogramObserve(now, metrics.TimeToInspectImage)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				log.V(1).Error(err, "Error inspecting the image", "imageName", candidates[0].displayName())
+				if inspectionErr == nil {
+					inspectionErr = err
+				}
+			case supportedArchitecturesSet == nil:
+				supportedArchitecturesSet = currentImageSupportedArchitectures
+			default:
+				supportedArchitecturesSet = intersectArchitectures(supportedArchitecturesSet, currentImageSupportedArchitectures)
+			}
+		}(candidates)
+	}
+	wg.Wait()
+	if inspectionErr != nil {
+		return nil, inspectionErr
 	}
 	return sets.List(supportedArchitecturesSet), nil
 }
 
+// inspectionKey returns the key of the inspection of the images with the given image inspector. The pull secrets are
+// part of the key, as the images they give access to are different. So is the image inspector, so that the
+// components injecting different inspectors, e.g., the tests, do not share their results.
+func inspectionKey(imageInspector image.ICache, imageNamesSet sets.Set[containerImage], pullSecretDataList [][]byte) string {
+	images := make([]string, 0, imageNamesSet.Len())
+	for imageContainer := range imageNamesSet {
+		images = append(images, fmt.Sprintf("%s|%s|%t|%s|%d", imageContainer.imageName, imageContainer.os,
+			imageContainer.skipCache, imageContainer.shortName, imageContainer.priority))
+	}
+	slices.Sort(images)
+	return fmt.Sprintf("%p|", imageInspector) + strings.Join(images, ",") + "#" + pullSecretsHash(pullSecretDataList)
+}
+
+// imageInspectionKey returns the key of the inspections of the image in inspectionGroup: the parameters of the
+// inspection, including the hash of the pull secrets, as the images they give access to are different.
+func imageInspectionKey(imageContainer containerImage, pullSecretDataList [][]byte) string {
+	return fmt.Sprintf("%s|%s|%t#%s", imageContainer.imageName, imageContainer.os, imageContainer.skipCache,
+		pullSecretsHash(pullSecretDataList))
+}
+
+// pullSecretsHash returns the hex-encoded hash of the pull secrets.
+func pullSecretsHash(pullSecretDataList [][]byte) string {
+	hash := fnv.New128()
+	for _, pullSecretData := range pullSecretDataList {
+		hash.Write(pullSecretData)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// intersectArchitectures returns the intersection of two sets of architectures, optionally qualified by
+// their variant. An architecture without variant is compatible with all its variants: in that case, the
+// intersection preserves the variant-qualified architecture (e.g., arm and arm/v7 intersect in arm/v7).
+// Different variants of the same architecture do not intersect.
+func intersectArchitectures(a, b sets.Set[string]) sets.Set[string] {
+	result := sets.New[string]()
+	for x := range a {
+		for y := range b {
+			switch {
+			case x == y:
+				result.Insert(x)
+			case utils.ArchitectureWithoutVariant(x) != utils.ArchitectureWithoutVariant(y):
+				continue
+			case x == utils.ArchitectureWithoutVariant(x):
+				result.Insert(y)
+			case y == utils.ArchitectureWithoutVariant(y):
+				result.Insert(x)
+			}
+		}
+	}
+	return result
+}
+
 func (pod *Pod) publishEvent(eventType, reason, message string) {
 	if pod.recorder != nil {
 		pod.recorder.Event(&pod.Pod, eventType, reason, message)
@@ -298,6 +974,19 @@ func (pod *Pod) ensureNoLabel(label string) {
 	delete(pod.Labels, label)
 }
 
+// RemoveAllOperatorManagedLabels removes the labels whose key has the utils.ArchLabelPrefix prefix, i.e., the labels
+// set by the operator, from the pod. It returns true if any label was removed.
+func (pod *Pod) RemoveAllOperatorManagedLabels() bool {
+	removed := false
+	for key := range pod.Labels {
+		if strings.HasPrefix(key, utils.ArchLabelPrefix) {
+			delete(pod.Labels, key)
+			removed = true
+		}
+	}
+	return removed
+}
+
 // ensureLabel ensures that the pod has the given label with the given value.
 func (pod *Pod) ensureAnnotation(annotation string, value string) {
 	if pod.Annotations == nil {
@@ -359,12 +1048,46 @@ func (pod *Pod) ensureArchitectureLabels(requirement corev1.NodeSelectorRequirem
 	}
 }
 
-// hasControlPlaneNodeSelector returns true if the pod has a node selector that matches the control plane nodes.
+// ensureArchitectureAnnotation records the inspection of the images of the pod, so that it can be debugged: the
+// utils.InspectedImagesAnnotation annotation is set to the JSON array of the inspected images, and the
+// utils.InspectedAtAnnotation annotation to the time of the inspection.
+func (pod *Pod) ensureArchitectureAnnotation(images []string, inspectionTime time.Time) {
+	if images == nil {
+		images = []string{}
+	}
+	// Marshalling a slice of strings cannot fail
+	value, _ := json.Marshal(images)
+	pod.ensureAnnotation(utils.InspectedImagesAnnotation, string(value))
+	pod.ensureAnnotation(utils.InspectedAtAnnotation, inspectionTime.UTC().Format(time.RFC3339))
+}
+
+// inspectedImages returns the sorted images of the pod that are inspected, as set in the pod spec.
+func (pod *Pod) inspectedImages() []string {
+	images := sets.New[string]()
+	for imageContainer := range pod.imagesNamesSet() {
+		images.Insert(imageContainer.displayName())
+	}
+	return sets.List(images)
+}
+
+// ensureArchitectureVariantLabels adds a label for each of the given architectures qualified by a variant,
+// e.g., multiarch.openshift.io/arm-v7 for arm/v7, so that the pods requiring a specific variant can be indexed.
+func (pod *Pod) ensureArchitectureVariantLabels(architectures []string) {
+	for _, architecture := range architectures {
+		if architecture != utils.ArchitectureWithoutVariant(architecture) {
+			pod.ensureLabel(utils.ArchLabelValue(architecture), "")
+		}
+	}
+}
+
+// hasControlPlaneNodeSelector returns true if the pod has a node selector that matches the control plane nodes or the
+// OpenShift infrastructure nodes.
 func (pod *Pod) hasControlPlaneNodeSelector() bool {
 	if pod.Spec.NodeSelector == nil {
 		return false
 	}
-	requiredSelectors := []string{utils.MasterNodeSelectorLabel, utils.ControlPlaneNodeSelectorLabel}
+	requiredSelectors := []string{utils.MasterNodeSelectorLabel, utils.ControlPlaneNodeSelectorLabel,
+		utils.InfraNodeSelectorLabel}
 	for _, value := range requiredSelectors {
 		if _, ok := pod.Spec.NodeSelector[value]; ok {
 			return true
@@ -373,35 +1096,145 @@ func (pod *Pod) hasControlPlaneNodeSelector() bool {
 	return false
 }
 
+// gateInjectionDisabled returns true if the DisableGateInjection switch of the ClusterPodPlacementConfig is set.
+// Unlike the ignore reasons, it only affects the gating of the pods, not the validation of their architectures.
+func gateInjectionDisabled(cppc *v1beta1.ClusterPodPlacementConfig) bool {
+	return cppc != nil && cppc.Spec.DisableGateInjection
+}
+
+// architectureLabelsDisabled returns true if the EnableArchitectureLabels of the ClusterPodPlacementConfig is set to
+// false.
+func architectureLabelsDisabled(cppc *v1beta1.ClusterPodPlacementConfig) bool {
+	return cppc != nil && !cppc.Spec.GetEnableArchitectureLabels()
+}
+
+// archLabelRequiredOnNodes returns true if the given cppc requires the utils.ArchLabel label on the nodes.
+func archLabelRequiredOnNodes(cppc *v1beta1.ClusterPodPlacementConfig) bool {
+	return cppc != nil && cppc.Spec.RequireArchLabelOnNodes
+}
+
 // shouldIgnorePod returns true if the pod should be ignored by the operator.
 // The operator should ignore the pods in the following cases:
+// - the pod has the utils.IgnoreAnnotation annotation set to "true"
 // - the pod is in the same namespace as the operator
 // - the pod is in a namespace with prefix kube-
 // - the pod has a node name set
-// - the pod has a node selector that matches the control plane nodes
+// - the pod has a node selector that matches the control plane nodes or the infrastructure nodes
 // - the pod is owned by a DaemonSet
+// - the pod is controlled by one of the ExcludedOwnerKinds
+// - the pod has the utils.OwnerKindAnnotation annotation set to one of the TrustedOwnerKinds
+// - the pod has hostNetwork or hostPID set to true and the IgnoreHostNetworkPods is enabled
+// - the pod targets a virtual node and the VirtualNodeTolerationStrategy is skip
 // - both the nodeSelector/nodeAffinity and the preferredAffinity are set for the kubernetes.io/arch label.
 // - only the nodeSelector/nodeAffinity is set for the kubernetes.io/arch label and the NodeAffinityScoring plugin is disabled.
 func (pod *Pod) shouldIgnorePod(cppc *v1beta1.ClusterPodPlacementConfig) bool {
-	return utils.Namespace() == pod.Namespace || strings.HasPrefix(pod.Namespace, "kube-") ||
-		pod.Spec.NodeName != "" || pod.hasControlPlaneNodeSelector() || pod.isFromDaemonSet() ||
-		pod.isNodeSelectorConfiguredForArchitecture() && (cppc.Spec.Plugins == nil ||
-			!cppc.Spec.Plugins.NodeAffinityScoring.IsEnabled() || pod.isPreferredAffinityConfiguredForArchitecture())
+	return pod.ignoreReason(cppc) != ""
+}
+
+// ignoreReason returns the reason why the pod should be ignored by the operator, or an empty string if the pod
+// should be processed. See shouldIgnorePod for the list of cases.
+func (pod *Pod) ignoreReason(cppc *v1beta1.ClusterPodPlacementConfig) string {
+	switch {
+	case pod.Annotations[utils.IgnoreAnnotation] == "true":
+		return IgnoreReasonIgnoreAnnotation
+	case utils.Namespace() == pod.Namespace:
+		return IgnoreReasonOperatorNamespace
+	case strings.HasPrefix(pod.Namespace, "kube-"):
+		return IgnoreReasonKubeNamespace
+	case pod.Spec.NodeName != "":
+		return IgnoreReasonNodeNameSet
+	case pod.hasControlPlaneNodeSelector():
+		return IgnoreReasonControlPlaneNodeSelector
+	case pod.isFromDaemonSet():
+		return IgnoreReasonDaemonSet
+	case pod.isFromExcludedOwnerKind(cppc):
+		return IgnoreReasonExcludedOwnerKind
+	case pod.hasTrustedOwnerKind(cppc):
+		return IgnoreReasonTrustedOwnerKind
+	case pod.isIgnoredHostNetworkPod(cppc):
+		return IgnoreReasonHostNetwork
+	case pod.targetsVirtualNode(cppc):
+		return IgnoreReasonVirtualNode
+	case pod.isNodeSelectorConfiguredForArchitecture() && (cppc == nil || cppc.Spec.Plugins == nil ||
+		!cppc.Spec.Plugins.NodeAffinityScoring.IsEnabled() || pod.isPreferredAffinityConfiguredForArchitecture()):
+		return IgnoreReasonArchitectureConstraintsSet
+	}
+	return ""
+}
+
+// hasTrustedOwnerKind returns true if the utils.OwnerKindAnnotation annotation of the pod is one of the
+// TrustedOwnerKinds of the ClusterPodPlacementConfig.
+func (pod *Pod) hasTrustedOwnerKind(cppc *v1beta1.ClusterPodPlacementConfig) bool {
+	kind, ok := pod.Annotations[utils.OwnerKindAnnotation]
+	return ok && cppc != nil && slices.Contains(cppc.Spec.TrustedOwnerKinds, kind)
+}
+
+// isIgnoredHostNetworkPod returns true if the IgnoreHostNetworkPods of the ClusterPodPlacementConfig is enabled and
+// the pod uses the network or the PID namespace of the host.
+func (pod *Pod) isIgnoredHostNetworkPod(cppc *v1beta1.ClusterPodPlacementConfig) bool {
+	return cppc != nil && cppc.Spec.IgnoreHostNetworkPods && (pod.Spec.HostNetwork || pod.Spec.HostPID)
+}
+
+// invalidIgnoreAnnotationWarning returns the admission warning for the pods whose utils.IgnoreAnnotation annotation
+// is neither "true" nor "false", or an empty string. Such pods are not ignored.
+func (pod *Pod) invalidIgnoreAnnotationWarning() string {
+	value, ok := pod.Annotations[utils.IgnoreAnnotation]
+	if !ok || value == "true" || value == "false" {
+		return ""
+	}
+	return fmt.Sprintf("invalid value %q of the %s annotation: it must be \"true\" or \"false\"; the pod is not ignored",
+		value, utils.IgnoreAnnotation)
+}
+
+// targetsVirtualNode returns true if the VirtualNodeTolerationStrategy of the ClusterPodPlacementConfig is skip, and
+// the namespace of the pod, or the node pool its nodeSelector targets, matches the VirtualNodeMatchers.
+func (pod *Pod) targetsVirtualNode(cppc *v1beta1.ClusterPodPlacementConfig) bool {
+	if cppc == nil || cppc.Spec.VirtualNodeTolerationStrategy != v1beta1.VirtualNodeTolerationStrategySkip ||
+		cppc.Spec.VirtualNodeMatchers == nil {
+		return false
+	}
+	matchers := cppc.Spec.VirtualNodeMatchers
+	if matchesAnyPattern(matchers.Namespaces, pod.Namespace) {
+		return true
+	}
+	nodePool, ok := pod.Spec.NodeSelector[matchers.GetNodePoolLabel()]
+	return ok && matchesAnyPattern(matchers.NodePools, nodePool)
+}
+
+// matchesAnyPattern returns true if the name matches any of the glob patterns.
+func matchesAnyPattern(patterns []string, name string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		matched, err := path.Match(pattern, name)
+		return err == nil && matched
+	})
+}
+
+// isExcludedByLabelSelector returns true if the labels of the pod match the PodExclusionLabelSelector of the
+// ClusterPodPlacementConfig. A nil selector excludes no pods.
+func (pod *Pod) isExcludedByLabelSelector(cppc *v1beta1.ClusterPodPlacementConfig) (bool, error) {
+	if cppc == nil || cppc.Spec.PodExclusionLabelSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cppc.Spec.PodExclusionLabelSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(pod.Labels)), nil
 }
 
-// ensureSchedulingGate ensures that the pod has the scheduling gate utils.SchedulingGateName.
+// ensureSchedulingGate ensures that the pod has the scheduling gate named as utils.GetSchedulingGateName, and the
+// ones in utils.GetManagedSchedulingGates.
 func (pod *Pod) ensureSchedulingGate() {
 	// https://github.com/kubernetes/enhancements/tree/master/keps/sig-scheduling/3521-pod-scheduling-readiness
 	if pod.Spec.SchedulingGates == nil {
 		pod.Spec.SchedulingGates = []corev1.PodSchedulingGate{}
 	}
-	// if the gate is already present, do not try to patch (it would fail)
-	for _, schedulingGate := range pod.Spec.SchedulingGates {
-		if schedulingGate.Name == utils.SchedulingGateName {
-			return
+	for _, name := range append([]string{utils.GetSchedulingGateName()}, utils.GetManagedSchedulingGates()...) {
+		// if the gate is already present, do not try to patch (it would fail)
+		if !pod.hasSchedulingGate(name) {
+			pod.Spec.SchedulingGates = append(pod.Spec.SchedulingGates, corev1.PodSchedulingGate{Name: name})
 		}
 	}
-	pod.Spec.SchedulingGates = append(pod.Spec.SchedulingGates, corev1.PodSchedulingGate{Name: utils.SchedulingGateName})
 }
 
 // isNodeSelectorConfiguredForArchitecture returns true if the pod has already a nodeSelector for the architecture label
@@ -449,6 +1282,36 @@ func (pod *Pod) isNodeSelectorConfiguredForArchitecture() bool {
 	return true
 }
 
+// logNodeNameSelectorTerms logs the required node selector terms of the pod selecting the nodes by name instead of by
+// architecture. A term pinning the pod to given nodes by name may be used as a proxy for the architecture: the
+// architecture is not a field of the nodes, so it cannot be verified and the requirement is added to the term anyway.
+func (pod *Pod) logNodeNameSelectorTerms() {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return
+	}
+	log := ctrllog.FromContext(pod.ctx)
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		hasArchLabel := slices.ContainsFunc(term.MatchExpressions, func(expression corev1.NodeSelectorRequirement) bool {
+			return expression.Key == utils.ArchLabel
+		})
+		if !hasArchLabel && hasNodeNameMatchField(term) {
+			log.V(1).Info("A node selector term of the pod selects the nodes by name instead of by architecture: "+
+				"the architecture requirement is added to it", "matchFields", term.MatchFields)
+		}
+	}
+}
+
+// hasNodeNameMatchField returns true if the term has a matchFields entry on the metadata.name field of the nodes.
+func hasNodeNameMatchField(term corev1.NodeSelectorTerm) bool {
+	for _, field := range term.MatchFields {
+		if field.Key == metav1.ObjectNameField {
+			return true
+		}
+	}
+	return false
+}
+
 // isPodFromDaemonSet returns true if the pod is from a daemonSet.
 func (pod *Pod) isFromDaemonSet() bool {
 	// Check all ownerRef
@@ -460,6 +1323,79 @@ func (pod *Pod) isFromDaemonSet() bool {
 	return false
 }
 
+// isFromExcludedOwnerKind returns true if the pod is controlled by one of the ExcludedOwnerKinds, or has an owner
+// reference matching one of the ExcludedOwnerReferenceKinds, of the ClusterPodPlacementConfig.
+func (pod *Pod) isFromExcludedOwnerKind(cppc *v1beta1.ClusterPodPlacementConfig) bool {
+	if cppc == nil {
+		return false
+	}
+	controller := metav1.GetControllerOf(pod)
+	if controller != nil && slices.Contains(cppc.Spec.GetExcludedOwnerKinds(), controller.Kind) {
+		return true
+	}
+	for _, ownerReference := range pod.OwnerReferences {
+		for _, excludedOwnerRef := range cppc.Spec.ExcludedOwnerReferenceKinds {
+			if excludedOwnerRef.Matches(ownerReference) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// architectureConstraintsConflicts returns a warning for each of the user-defined constraints on the
+// kubernetes.io/arch label, i.e., the nodeSelector and the required node affinity, that does not allow any of the
+// architectures supported by the images of the pod. Such a pod is not schedulable on the nodes its images can run on.
+func (pod *Pod) architectureConstraintsConflicts(supportedArchitectures []string) []string {
+	var warnings []string
+	supported := strings.Join(supportedArchitectures, ", ")
+	if architecture, ok := pod.Spec.NodeSelector[utils.ArchLabel]; ok &&
+		!slices.Contains(supportedArchitectures, architecture) {
+		warnings = append(warnings, fmt.Sprintf("the nodeSelector %s=%s conflicts with the architectures supported "+
+			"by the images of the pod (%s): the pod will not be schedulable", utils.ArchLabel, architecture, supported))
+	}
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil ||
+		len(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) == 0 {
+		return warnings
+	}
+	// The nodeSelectorTerms are ORed: the node affinity conflicts if none of them allows a supported architecture.
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, architecture := range supportedArchitectures {
+			if architectureMatchesTerm(architecture, term) {
+				return warnings
+			}
+		}
+	}
+	return append(warnings, fmt.Sprintf("the required node affinity on the %s label conflicts with the architectures "+
+		"supported by the images of the pod (%s): the pod will not be schedulable", utils.ArchLabel, supported))
+}
+
+// architectureMatchesTerm returns true if a node of the given architecture satisfies all the match expressions of the
+// nodeSelectorTerm on the kubernetes.io/arch label. The match expressions on other labels are not evaluated.
+func architectureMatchesTerm(architecture string, term corev1.NodeSelectorTerm) bool {
+	for _, matchExpression := range term.MatchExpressions {
+		if matchExpression.Key != utils.ArchLabel {
+			continue
+		}
+		switch matchExpression.Operator {
+		case corev1.NodeSelectorOpIn:
+			if !slices.Contains(matchExpression.Values, architecture) {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if slices.Contains(matchExpression.Values, architecture) {
+				return false
+			}
+		case corev1.NodeSelectorOpExists:
+		default:
+			// DoesNotExist, Gt and Lt are never satisfied by the kubernetes.io/arch label of a node
+			return false
+		}
+	}
+	return true
+}
+
 func (pod *Pod) publishIgnorePod() {
 	log := ctrllog.FromContext(pod.ctx)
 	log.V(1).Info("The pod has the nodeSelector or all the nodeAffinityTerms set for the kubernetes.io/arch label. Ignoring the pod...")
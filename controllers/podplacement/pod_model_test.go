@@ -2,9 +2,16 @@ package podplacement
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -12,6 +19,7 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
 
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common"
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common/plugins"
@@ -167,11 +175,91 @@ func TestPod_RemoveSchedulingGate(t *testing.T) {
 	}
 }
 
+func TestPod_RemoveSchedulingGate_ProcessingDurationAnnotation(t *testing.T) {
+	t.Run("gated pod", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		pod := &Pod{
+			Pod: *NewPod().WithSchedulingGates(utils.SchedulingGateName).Build(),
+			ctx: ctx,
+		}
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-5 * time.Second))
+		pod.RemoveSchedulingGate()
+		g.Expect(pod.Spec.SchedulingGates).To(BeEmpty())
+		g.Expect(pod.Annotations).To(HaveKey(utils.ProcessingDurationAnnotation))
+		duration, err := strconv.ParseFloat(pod.Annotations[utils.ProcessingDurationAnnotation], 64)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(duration).To(BeNumerically(">", 0))
+		g.Expect(duration).To(BeNumerically("~", 5, 2))
+	})
+	t.Run("pod without the scheduling gate", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		pod := &Pod{
+			Pod: *NewPod().WithSchedulingGates("some-other-scheduling-gate").Build(),
+			ctx: ctx,
+		}
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-5 * time.Second))
+		pod.RemoveSchedulingGate()
+		g.Expect(pod.Annotations).NotTo(HaveKey(utils.ProcessingDurationAnnotation))
+	})
+}
+
+func TestPod_ensureProcessingDurationAnnotation(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		created     time.Time
+		removalTime time.Time
+		want        string
+		wantNoKey   bool
+	}{
+		{
+			name:        "gate removed after 1.5 seconds",
+			created:     created,
+			removalTime: created.Add(1500 * time.Millisecond),
+			want:        "1.500",
+		},
+		{
+			name:        "gate removed after 2 minutes",
+			created:     created,
+			removalTime: created.Add(2 * time.Minute),
+			want:        "120.000",
+		},
+		{
+			name:        "removal time before the creation timestamp",
+			created:     created,
+			removalTime: created.Add(-time.Second),
+			want:        "0.000",
+		},
+		{
+			name:        "no creation timestamp",
+			removalTime: created,
+			wantNoKey:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{
+				Pod: *NewPod().Build(),
+				ctx: ctx,
+			}
+			pod.CreationTimestamp = metav1.NewTime(tt.created)
+			pod.ensureProcessingDurationAnnotation(tt.removalTime)
+			if tt.wantNoKey {
+				g.Expect(pod.Annotations).NotTo(HaveKey(utils.ProcessingDurationAnnotation))
+				return
+			}
+			g.Expect(pod.Annotations).To(HaveKeyWithValue(utils.ProcessingDurationAnnotation, tt.want))
+		})
+	}
+}
+
 func TestPod_imagesNamesSet(t *testing.T) {
 	tests := []struct {
-		name string
-		pod  *v1.Pod
-		want sets.Set[containerImage]
+		name             string
+		pod              *v1.Pod
+		searchRegistries []string
+		want             sets.Set[containerImage]
 	}{
 		{
 			name: "pod with a single container",
@@ -186,6 +274,7 @@ func TestPod_imagesNamesSet(t *testing.T) {
 			},
 			want: sets.New[containerImage](containerImage{
 				imageName: "//bar/foo:latest",
+				os:        utils.OSLinux,
 				skipCache: false,
 			}),
 		},
@@ -194,9 +283,11 @@ func TestPod_imagesNamesSet(t *testing.T) {
 			pod:  NewPod().WithContainersImages("bar/foo:latest", "bar/baz:latest", "bar/foo:latest").Build(),
 			want: sets.New[containerImage](containerImage{
 				imageName: "//bar/foo:latest",
+				os:        utils.OSLinux,
 				skipCache: false,
 			}, containerImage{
 				imageName: "//bar/baz:latest",
+				os:        utils.OSLinux,
 				skipCache: false,
 			}),
 		},
@@ -205,9 +296,9 @@ func TestPod_imagesNamesSet(t *testing.T) {
 			pod: NewPod().WithInitContainersImages("foo/bar:latest").WithContainersImages(
 				"bar/foo:latest", "bar/baz:latest", "bar/foo:latest").Build(),
 			want: sets.New[containerImage](
-				containerImage{imageName: "//bar/foo:latest"},
-				containerImage{imageName: "//bar/baz:latest"},
-				containerImage{imageName: "//foo/bar:latest"}),
+				containerImage{imageName: "//bar/foo:latest", os: utils.OSLinux},
+				containerImage{imageName: "//bar/baz:latest", os: utils.OSLinux},
+				containerImage{imageName: "//foo/bar:latest", os: utils.OSLinux}),
 		},
 		{
 			name: "pod with multiple containers, init containers, one image with imagePullPolicy Always",
@@ -215,18 +306,78 @@ func TestPod_imagesNamesSet(t *testing.T) {
 				"bar/foo:latest", "bar/baz:latest", "bar/foo:latest").
 				WithContainerImagePullAlways("foo/pull:always").Build(),
 			want: sets.New[containerImage](
-				containerImage{imageName: "//bar/foo:latest"},
-				containerImage{imageName: "//bar/baz:latest"},
-				containerImage{imageName: "//foo/bar:latest"},
-				containerImage{imageName: "//foo/pull:always", skipCache: true},
+				containerImage{imageName: "//bar/foo:latest", os: utils.OSLinux},
+				containerImage{imageName: "//bar/baz:latest", os: utils.OSLinux},
+				containerImage{imageName: "//foo/bar:latest", os: utils.OSLinux},
+				containerImage{imageName: "//foo/pull:always", os: utils.OSLinux, skipCache: true},
+			),
+		},
+		{
+			name: "pod with containers with imagePullPolicy Never, Always and IfNotPresent",
+			pod: NewPod().WithContainer("foo/pull:never", v1.PullNever).
+				WithContainerImagePullAlways("foo/pull:always").
+				WithContainer("foo/pull:if-not-present", v1.PullIfNotPresent).Build(),
+			want: sets.New[containerImage](
+				containerImage{imageName: "//foo/pull:always", os: utils.OSLinux, skipCache: true},
+				containerImage{imageName: "//foo/pull:if-not-present", os: utils.OSLinux},
+			),
+		},
+		{
+			name: "pod with the force-refresh annotation",
+			pod: NewPod().WithAnnotations(utils.ForceRefreshAnnotation, "true").
+				WithInitContainersImages("foo/bar:latest").WithContainersImages("bar/foo:latest").
+				WithContainer("foo/pull:never", v1.PullNever).Build(),
+			want: sets.New[containerImage](
+				containerImage{imageName: "//bar/foo:latest", os: utils.OSLinux, skipCache: true},
+				containerImage{imageName: "//foo/bar:latest", os: utils.OSLinux, skipCache: true},
+			),
+		},
+		{
+			name: "pod with the force-refresh annotation not set to true",
+			pod: NewPod().WithAnnotations(utils.ForceRefreshAnnotation, "false").
+				WithContainersImages("bar/foo:latest").Build(),
+			want: sets.New[containerImage](containerImage{imageName: "//bar/foo:latest", os: utils.OSLinux}),
+		},
+		{
+			name: "pod with the windows node selector",
+			pod: NewPod().WithNodeSelectors(utils.OSLabel, utils.OSWindows).
+				WithContainersImages("bar/foo:latest").Build(),
+			want: sets.New[containerImage](containerImage{imageName: "//bar/foo:latest", os: utils.OSWindows}),
+		},
+		{
+			name: "pod with the windows OS in its spec",
+			pod: func() *v1.Pod {
+				pod := NewPod().WithContainersImages("bar/foo:latest").Build()
+				pod.Spec.OS = &v1.PodOS{Name: v1.Windows}
+				return pod
+			}(),
+			want: sets.New[containerImage](containerImage{imageName: "//bar/foo:latest", os: utils.OSWindows}),
+		},
+		{
+			name: "pod with ephemeral containers",
+			pod: NewPod().WithContainersImages("bar/foo:latest").
+				WithEphemeralContainersImages("debug/tools:latest").Build(),
+			want: sets.New[containerImage](containerImage{imageName: "//bar/foo:latest", os: utils.OSLinux}),
+		},
+		{
+			name:             "pod with short names and search registries",
+			pod:              NewPod().WithContainersImages("nginx:latest", "quay.io/bar/foo:latest").Build(),
+			searchRegistries: []string{"registry.access.redhat.com", "docker.io"},
+			want: sets.New[containerImage](
+				containerImage{imageName: "//registry.access.redhat.com/nginx:latest", os: utils.OSLinux,
+					shortName: "nginx:latest", priority: 0},
+				containerImage{imageName: "//docker.io/nginx:latest", os: utils.OSLinux,
+					shortName: "nginx:latest", priority: 1},
+				containerImage{imageName: "//quay.io/bar/foo:latest", os: utils.OSLinux},
 			),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pod := &Pod{
-				Pod: *tt.pod,
-				ctx: ctx,
+				Pod:              *tt.pod,
+				ctx:              ctx,
+				searchRegistries: tt.searchRegistries,
 			}
 			g := NewGomegaWithT(t)
 			g.Expect(pod.imagesNamesSet()).To(Equal(tt.want))
@@ -234,6 +385,210 @@ func TestPod_imagesNamesSet(t *testing.T) {
 	}
 }
 
+func TestPod_intersectImagesArchitecture_UniversalImages(t *testing.T) {
+	// The universal images are not known to the fake inspector: their inspection would fail.
+	universalImages := []string{"registry.k8s.io/pause:3.9", "registry.example.com/base/*"}
+	tests := []struct {
+		name    string
+		pod     *v1.Pod
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "pod with universal images only",
+			pod: NewPod().WithContainersImages("registry.k8s.io/pause:3.9",
+				"registry.example.com/base/scratch:latest").Build(),
+			want: sets.List(utils.AllSupportedArchitecturesSet()),
+		},
+		{
+			name: "pod with universal and non-universal images",
+			pod: NewPod().WithContainersImages("registry.k8s.io/pause:3.9", fake.SingleArchAmd64Image).
+				WithInitContainersImages("registry.example.com/base/init:latest").Build(),
+			want: []string{utils.ArchitectureAmd64},
+		},
+		{
+			name: "pod with a non-universal image that cannot be inspected",
+			pod: NewPod().WithContainersImages("registry.k8s.io/pause:3.9",
+				"registry.example.com/base/nested/image:latest").Build(),
+			wantErr: true,
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{
+				Pod:             *tt.pod,
+				ctx:             ctx,
+				imageInspector:  fake.FacadeSingleton(),
+				universalImages: universalImages,
+			}
+			architectures, err := pod.intersectImagesArchitecture(nil)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(architectures).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestIsUniversalImage(t *testing.T) {
+	tests := []struct {
+		name            string
+		image           string
+		universalImages []string
+		want            bool
+	}{
+		{
+			name:            "exact match",
+			image:           "registry.k8s.io/pause:3.9",
+			universalImages: []string{"registry.k8s.io/pause:3.9"},
+			want:            true,
+		},
+		{
+			name:            "glob match",
+			image:           "registry.k8s.io/pause:3.9",
+			universalImages: []string{"quay.io/foo", "registry.k8s.io/pause:*"},
+			want:            true,
+		},
+		{
+			name:            "the wildcard does not match the path separator",
+			image:           "registry.example.com/base/nested/image:latest",
+			universalImages: []string{"registry.example.com/base/*"},
+			want:            false,
+		},
+		{
+			name:            "malformed pattern",
+			image:           "registry.example.com/base/image:latest",
+			universalImages: []string{"registry.example.com/base/["},
+			want:            false,
+		},
+		{
+			name:            "no universal images",
+			image:           "registry.k8s.io/pause:3.9",
+			universalImages: nil,
+			want:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(isUniversalImage(tt.image, tt.universalImages)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestResolveUnqualifiedImage(t *testing.T) {
+	tests := []struct {
+		name             string
+		image            string
+		searchRegistries []string
+		want             []string
+	}{
+		{
+			name:             "short name with multiple search registries",
+			image:            "nginx:latest",
+			searchRegistries: []string{"registry.access.redhat.com", "quay.io", "docker.io"},
+			want: []string{"registry.access.redhat.com/nginx:latest", "quay.io/nginx:latest",
+				"docker.io/nginx:latest"},
+		},
+		{
+			name:             "short name with a repository namespace",
+			image:            "library/nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			searchRegistries: []string{"quay.io", "docker.io"},
+			want: []string{
+				"quay.io/library/nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				"docker.io/library/nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+		},
+		{
+			name:             "short name without search registries",
+			image:            "nginx:latest",
+			searchRegistries: nil,
+			want:             []string{"nginx:latest"},
+		},
+		{
+			name:             "fully qualified name",
+			image:            "quay.io/bar/foo:latest",
+			searchRegistries: []string{"docker.io"},
+			want:             []string{"quay.io/bar/foo:latest"},
+		},
+		{
+			name:             "registry with a port",
+			image:            "my-registry:5000/foo:latest",
+			searchRegistries: []string{"docker.io"},
+			want:             []string{"my-registry:5000/foo:latest"},
+		},
+		{
+			name:             "localhost registry",
+			image:            "localhost/foo:latest",
+			searchRegistries: []string{"docker.io"},
+			want:             []string{"localhost/foo:latest"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(resolveUnqualifiedImage(tt.image, tt.searchRegistries)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestInspectImages_ShortNames(t *testing.T) {
+	tests := []struct {
+		name             string
+		images           []string
+		searchRegistries []string
+		want             []string
+		wantErr          bool
+	}{
+		{
+			name:             "the first search registry resolves the short name",
+			images:           []string{"library/single-arch-amd64-image:latest"},
+			searchRegistries: []string{"my-registry.io", "not-existing-registry.io"},
+			want:             []string{utils.ArchitectureAmd64},
+		},
+		{
+			name:             "a later search registry resolves the short name",
+			images:           []string{"library/multi-arch-image:latest"},
+			searchRegistries: []string{"not-existing-registry.io", "my-registry.io"},
+			want:             []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+		},
+		{
+			name:             "the short names are intersected with the other images",
+			images:           []string{"library/multi-arch-image:latest", fake.SingleArchArm64Image},
+			searchRegistries: []string{"not-existing-registry.io", "my-registry.io"},
+			want:             []string{utils.ArchitectureArm64},
+		},
+		{
+			name:             "no search registry resolves the short name",
+			images:           []string{"library/multi-arch-image:latest"},
+			searchRegistries: []string{"not-existing-registry.io", "another-not-existing-registry.io"},
+			wantErr:          true,
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{
+				Pod:              *NewPod().WithContainersImages(tt.images...).Build(),
+				ctx:              ctx,
+				searchRegistries: tt.searchRegistries,
+			}
+			architectures, err := inspectImages(ctx, fake.FacadeSingleton(), pod.imagesNamesSet(), nil, 0)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(architectures).To(Equal(tt.want))
+		})
+	}
+}
+
 func TestPod_intersectImagesArchitecture(t *testing.T) {
 	tests := []struct {
 		name string
@@ -269,6 +624,89 @@ func TestPod_intersectImagesArchitecture(t *testing.T) {
 			pod:                        NewPod().WithContainersImages(fake.MultiArchImage, fake.MultiArchImage2).Build(),
 			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
 		},
+		{
+			name:                       "pod with multiple containers, arm variant images",
+			pod:                        NewPod().WithContainersImages(fake.MultiArchArmImage, fake.SingleArchArmV7Image).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureArmV7),
+		},
+		{
+			name:                       "pod with multiple containers, OCI image index v1.1 and multi-arch images",
+			pod:                        NewPod().WithContainersImages(fake.MultiArchOCIIndexImage, fake.MultiArchImage2).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitecturePpc64le),
+		},
+		{
+			name:                       "linux pod with a multi-os image",
+			pod:                        NewPod().WithContainersImages(fake.MultiOSImage).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
+		},
+		{
+			name:                       "pod with a single container and wasm image",
+			pod:                        NewPod().WithContainersImages(fake.WasmImage).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureWasm32),
+		},
+		{
+			name:                       "pod with multiple containers, wasm image and single-arch image",
+			pod:                        NewPod().WithContainersImages(fake.WasmImage, fake.SingleArchAmd64Image).Build(),
+			wantSupportedArchitectures: sets.New[string](),
+		},
+		{
+			name:                       "pod with multiple containers, wasm image and multi-arch image",
+			pod:                        NewPod().WithContainersImages(fake.WasmImage, fake.MultiArchImage).Build(),
+			wantSupportedArchitectures: sets.New[string](),
+		},
+		{
+			name: "windows pod with a wasm image",
+			pod: NewPod().WithNodeSelectors(utils.OSLabel, utils.OSWindows).
+				WithContainersImages(fake.WasmImage).Build(),
+			wantSupportedArchitectures: sets.New[string](),
+		},
+		{
+			name: "windows pod with a multi-os image",
+			pod: NewPod().WithNodeSelectors(utils.OSLabel, utils.OSWindows).
+				WithContainersImages(fake.MultiOSImage).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64),
+		},
+		{
+			name: "windows pod with multi-os and windows images",
+			pod: NewPod().WithNodeSelectors(utils.OSLabel, utils.OSWindows).
+				WithContainersImages(fake.MultiOSImage, fake.SingleArchWindowsImage).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64),
+		},
+		{
+			name: "windows pod with a linux-only image",
+			pod: NewPod().WithNodeSelectors(utils.OSLabel, utils.OSWindows).
+				WithContainersImages(fake.MultiArchImage).Build(),
+			wantSupportedArchitectures: sets.New[string](),
+		},
+		{
+			name:                       "linux pod with a windows-only image",
+			pod:                        NewPod().WithContainersImages(fake.SingleArchWindowsImage).Build(),
+			wantSupportedArchitectures: sets.New[string](),
+		},
+		{
+			name: "pod with an ephemeral container with an image supporting none of the architectures",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithEphemeralContainersImages(fake.EphemeralContainerImage).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
+		},
+		{
+			name:                       "pod with multiple containers, arm variant and multi-arch images",
+			pod:                        NewPod().WithContainersImages(fake.MultiArchArmImage, fake.MultiArchImage2).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
+		},
+		{
+			name: "pod with containers with imagePullPolicy Never, Always and IfNotPresent",
+			// the image with imagePullPolicy Never is not inspected
+			pod: NewPod().WithContainer("non-existing-image", v1.PullNever).
+				WithContainerImagePullAlways(fake.MultiArchImage2).
+				WithContainer(fake.MultiArchImage, v1.PullIfNotPresent).Build(),
+			wantSupportedArchitectures: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
+		},
+		{
+			name:                       "pod with containers with imagePullPolicy Never only",
+			pod:                        NewPod().WithContainer("non-existing-image", v1.PullNever).Build(),
+			wantSupportedArchitectures: utils.AllSupportedArchitecturesSet(),
+		},
 		{
 			name:                       "pod with multiple containers, one non-existing image",
 			pod:                        NewPod().WithContainersImages(fake.MultiArchImage, "non-existing-image").Build(),
@@ -279,10 +717,10 @@ func TestPod_intersectImagesArchitecture(t *testing.T) {
 	metrics.InitPodPlacementControllerMetrics()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			imageInspectionCache = fake.FacadeSingleton()
 			pod := &Pod{
-				Pod: *tt.pod,
-				ctx: ctx,
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: fake.FacadeSingleton(),
 			}
 			gotSupportedArchitectures, err := pod.intersectImagesArchitecture(tt.pullSecretDataList)
 			g := NewGomegaWithT(t)
@@ -295,110 +733,443 @@ func TestPod_intersectImagesArchitecture(t *testing.T) {
 				return sets.New[string](arches...)
 			}, Equal(tt.wantSupportedArchitectures)),
 				"the set in gotSupportedArchitectures is not equal to the expected one")
-			imageInspectionCache = mmoimage.FacadeSingleton()
 		})
 	}
 }
 
-func TestPod_getArchitecturePredicate(t *testing.T) {
+func TestPod_intersectImagesArchitecture_ForceRefresh(t *testing.T) {
 	tests := []struct {
-		name               string
-		pod                *v1.Pod
-		pullSecretDataList [][]byte
-		// Be aware that the values in the want.Values slice must be sorted alphabetically
-		want    v1.NodeSelectorRequirement
-		wantErr bool
+		name            string
+		pod             *v1.Pod
+		wantInspections int
 	}{
 		{
-			name: "pod with several containers using multi-arch images",
-			pod: &v1.Pod{
-				Spec: v1.PodSpec{
-					Containers: []v1.Container{
-						{
-							Image: fake.MultiArchImage,
-						},
-					},
-					InitContainers: []v1.Container{
-						{
-							Image: fake.MultiArchImage2,
-						},
-					},
-				},
-			},
-			want: v1.NodeSelectorRequirement{
-				Key:      utils.ArchLabel,
-				Operator: v1.NodeSelectorOpIn,
-				Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
-			},
-		},
-		{
-			name: "pod with non-existing image",
-			pod: &v1.Pod{
-				Spec: v1.PodSpec{
-					Containers: []v1.Container{
-						{
-							Image: fake.MultiArchImage,
-						},
-						{
-							Image: "non-existing-image",
-						},
-					},
-				},
-			},
-			wantErr: true,
-			want:    v1.NodeSelectorRequirement{},
+			name:            "pod without the force-refresh annotation",
+			pod:             NewPod().WithContainersImages(fake.SingleArchArmV7Image).Build(),
+			wantInspections: 0,
 		},
 		{
-			name: "pod with conflicting architectures",
-			pod:  NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).Build(),
-			want: v1.NodeSelectorRequirement{
-				Key:      utils.NoSupportedArchLabel,
-				Operator: v1.NodeSelectorOpExists,
-			},
+			name: "pod with the force-refresh annotation",
+			pod: NewPod().WithAnnotations(utils.ForceRefreshAnnotation, "true").
+				WithContainersImages(fake.SingleArchArmV7Image).Build(),
+			wantInspections: 1,
 		},
 	}
+	metrics.InitPodPlacementControllerMetrics()
+	facade := fake.FacadeSingleton()
+	imageReference := "//" + fake.SingleArchArmV7Image
+	// Warm up the cache
+	_, err := (&Pod{Pod: *NewPod().WithContainersImages(fake.SingleArchArmV7Image).Build(), ctx: ctx,
+		imageInspector: facade}).intersectImagesArchitecture(nil)
+	NewGomegaWithT(t).Expect(err).NotTo(HaveOccurred())
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			imageInspectionCache = fake.FacadeSingleton()
+			g := NewGomegaWithT(t)
 			pod := &Pod{
-				Pod: *tt.pod,
-				ctx: ctx,
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: facade,
 			}
-			got, err := pod.getArchitecturePredicate(tt.pullSecretDataList)
-			g := NewGomegaWithT(t)
-			g.Expect(err).Should(WithTransform(func(err error) bool { return err != nil }, Equal(tt.wantErr)),
-				"error expectation failed")
-			// sort the architectures to make the comparison easier
-			sort.Strings(got.Values)
-			g.Expect(got).To(Equal(tt.want))
-			imageInspectionCache = mmoimage.FacadeSingleton()
+			inspections := facade.InspectionsCount(imageReference)
+			gotSupportedArchitectures, err := pod.intersectImagesArchitecture(nil)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gotSupportedArchitectures).To(Equal([]string{utils.ArchitectureArmV7}))
+			g.Expect(facade.InspectionsCount(imageReference)-inspections).To(Equal(tt.wantInspections),
+				"unexpected number of calls to the remote manifest API")
 		})
 	}
 }
 
-func TestPod_setArchNodeAffinity(t *testing.T) {
+func FuzzIntersectImagesArchitecture(f *testing.F) {
+	for _, image := range []string{
+		"registry:5000/repo/image@sha256:abc",
+		"localhost/foo",
+		"image",
+		"my-registry.io/library/image:" + strings.Repeat("a", 4096),
+		"my-registry.io/library/image:tag@sha256:" + strings.Repeat("0", 64),
+		"[::1]:5000/repo/image:v1.0_rc-1",
+		"my-registry.io/ライブラリ/イメージ:タグ",
+		"//my-registry.io/library/image",
+		"",
+		fake.MultiArchImage,
+		fake.SingleArchArm64Image,
+	} {
+		f.Add(image)
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	f.Fuzz(func(t *testing.T, image string) {
+		g := NewGomegaWithT(t)
+		pod := &Pod{Pod: *NewPod().WithContainersImages(image, fake.MultiArchImage).Build(), ctx: ctx,
+			imageInspector: fake.FacadeSingleton()}
+		imageNamesSet := pod.imagesNamesSet()
+		g.Expect(imageNamesSet.Has(containerImage{imageName: "//" + image, os: utils.OSLinux})).To(BeTrue(),
+			"the image name should be passed through unmodified")
+		g.Expect(imageNamesSet.Len()).To(BeNumerically("<=", 2))
+		supportedArchitectures, err := pod.intersectImagesArchitecture(nil)
+		want, ok := fake.MockImagesArchitectureMap()[image]
+		if !ok {
+			// The fake registry does not know the image: the inspection must fail rather than guess.
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(supportedArchitectures).To(BeNil())
+			return
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(sets.New(supportedArchitectures...)).To(Equal(
+			want.Intersection(fake.MockImagesArchitectureMap()[fake.MultiArchImage])))
+	})
+}
+
+func TestPod_intersectImagesArchitecture_ConcurrentPods(t *testing.T) {
+	const replicas = 100
 	tests := []struct {
 		name string
-		pod  *v1.Pod
-		want *v1.Pod
+		// images returns the images of the i-th pod
+		images func(i int) []string
+		want   func(i int) []string
 	}{
 		{
-			name: "pod with empty node selector terms",
-			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions().Build(),
-			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
-				[]v1.NodeSelectorRequirement{
-					{
-						Key:      utils.ArchLabel,
-						Operator: v1.NodeSelectorOpIn,
-						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
-					},
-				},
-			).Build(),
+			name: "pods with the same images",
+			images: func(int) []string {
+				return []string{fake.MultiArchImage, fake.SingleArchAmd64Image}
+			},
+			want: func(int) []string { return []string{utils.ArchitectureAmd64} },
 		},
 		{
-			name: "pod with node selector terms and nil match expressions",
-			pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image).WithNodeSelectorTermsMatchExpressions(
-				nil).Build(),
+			name: "pods with different images sharing a sidecar image",
+			images: func(i int) []string {
+				if i%2 == 0 {
+					return []string{fake.MultiArchImage, fake.SingleArchAmd64Image}
+				}
+				return []string{fake.MultiArchImage, fake.SingleArchArm64Image}
+			},
+			want: func(i int) []string {
+				if i%2 == 0 {
+					return []string{utils.ArchitectureAmd64}
+				}
+				return []string{utils.ArchitectureArm64}
+			},
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			// The pods of a Deployment scaled up at once, while the cache is empty
+			facade := fake.NewFacade()
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			images := sets.New[string]()
+			for i := 0; i < replicas; i++ {
+				images.Insert(tt.images(i)...)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					pod := &Pod{Pod: *NewPod().WithContainersImages(tt.images(i)...).Build(), ctx: ctx,
+						imageInspector: facade}
+					<-start
+					supportedArchitectures, err := pod.intersectImagesArchitecture(nil)
+					g.Expect(err).NotTo(HaveOccurred())
+					g.Expect(supportedArchitectures).To(Equal(tt.want(i)))
+				}()
+			}
+			close(start)
+			wg.Wait()
+			for image := range images {
+				g.Expect(facade.InspectionsCount("//"+image)).To(Equal(1),
+					"the concurrent pods should share the inspection of %s", image)
+			}
+		})
+	}
+}
+
+// blockingCache is an image.ICache blocking the inspections until released, and failing the ones whose context is
+// done by then.
+type blockingCache struct {
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (c *blockingCache) GetCompatibleArchitecturesSet(ctx context.Context, _ string, _ string, _ bool,
+	_ [][]byte) (sets.Set[string], error) {
+	c.calls.Add(1)
+	<-c.release
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return sets.New(utils.ArchitectureAmd64), nil
+}
+
+func TestInspectImage_SharedInspectionOutlivesCaller(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cache := &blockingCache{release: make(chan struct{})}
+	pod := &Pod{Pod: *NewPod().WithContainersImages("quay.io/example/shared:latest").Build(), ctx: ctx}
+	imageContainer := pod.imagesNamesSet().UnsortedList()[0]
+
+	// The first caller starts the shared inspection, and is cancelled while it is in flight
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := inspectImage(cancelledCtx, cache, imageContainer, nil)
+		firstErr <- err
+	}()
+	g.Eventually(cache.calls.Load).Should(Equal(int32(1)))
+	type result struct {
+		architectures sets.Set[string]
+		err           error
+	}
+	second := make(chan result, 1)
+	go func() {
+		architectures, err := inspectImage(ctx, cache, imageContainer, nil)
+		second <- result{architectures, err}
+	}()
+	// Let the second caller join the inspection in flight
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	g.Eventually(firstErr).Should(Receive(MatchError(context.Canceled)))
+
+	close(cache.release)
+	var got result
+	g.Eventually(second).Should(Receive(&got))
+	g.Expect(got.err).NotTo(HaveOccurred(), "the cancellation of the first caller should not fail the shared inspection")
+	g.Expect(sets.List(got.architectures)).To(Equal([]string{utils.ArchitectureAmd64}))
+	g.Expect(cache.calls.Load()).To(Equal(int32(1)), "the inspection should be shared")
+}
+
+func BenchmarkPod_intersectImagesArchitecture_ConcurrentPods(b *testing.B) {
+	const replicas = 100
+	images := []string{fake.MultiArchImage, fake.SingleArchAmd64Image}
+	metrics.InitPodPlacementControllerMetrics()
+	g := NewGomegaWithT(b)
+	for i := 0; i < b.N; i++ {
+		// The pods of a Deployment scaled up at once, while the cache is empty
+		facade := fake.NewFacade()
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for j := 0; j < replicas; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pod := &Pod{Pod: *NewPod().WithContainersImages(images...).Build(), ctx: ctx, imageInspector: facade}
+				<-start
+				supportedArchitectures, err := pod.intersectImagesArchitecture(nil)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(supportedArchitectures).To(Equal([]string{utils.ArchitectureAmd64}))
+			}()
+		}
+		close(start)
+		wg.Wait()
+		inspections := 0
+		for _, image := range images {
+			inspections += facade.InspectionsCount("//" + image)
+		}
+		g.Expect(inspections).To(BeNumerically("<=", len(images)),
+			"the concurrent pods with the same images should share the inspections")
+	}
+}
+
+// slowCache is an image.ICache taking latency to inspect any image, and recording the maximum number of concurrent
+// inspections.
+type slowCache struct {
+	latency       time.Duration
+	mu            sync.Mutex
+	inFlight      int
+	maxConcurrent int
+}
+
+func (c *slowCache) GetCompatibleArchitecturesSet(_ context.Context, _ string, _ string, _ bool,
+	_ [][]byte) (sets.Set[string], error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.maxConcurrent = max(c.maxConcurrent, c.inFlight)
+	c.mu.Unlock()
+	time.Sleep(c.latency)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return sets.New(utils.ArchitectureAmd64, utils.ArchitectureArm64), nil
+}
+
+// distinctImages returns n distinct image names.
+func distinctImages(n int) []string {
+	images := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		images = append(images, fmt.Sprintf("quay.io/example/image-%d:latest", i))
+	}
+	return images
+}
+
+func TestInspectImages_MaxConcurrentInspections(t *testing.T) {
+	tests := []struct {
+		name                     string
+		maxConcurrentInspections int
+		images                   int
+		wantMaxConcurrent        int
+	}{
+		{
+			name:                     "serial inspections",
+			maxConcurrentInspections: 1,
+			images:                   5,
+			wantMaxConcurrent:        1,
+		},
+		{
+			name:                     "inspections bounded by the limit",
+			maxConcurrentInspections: 3,
+			images:                   10,
+			wantMaxConcurrent:        3,
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cache := &slowCache{latency: 50 * time.Millisecond}
+			pod := &Pod{Pod: *NewPod().WithContainersImages(distinctImages(tt.images)...).Build(), ctx: ctx}
+			architectures, err := inspectImages(ctx, cache, pod.imagesNamesSet(), nil, tt.maxConcurrentInspections)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(architectures).To(Equal([]string{utils.ArchitectureAmd64, utils.ArchitectureArm64}))
+			g.Expect(cache.maxConcurrent).To(Equal(tt.wantMaxConcurrent))
+		})
+	}
+}
+
+func BenchmarkInspectImages_MaxConcurrentInspections(b *testing.B) {
+	metrics.InitPodPlacementControllerMetrics()
+	cache := &slowCache{latency: 10 * time.Millisecond}
+	pod := &Pod{Pod: *NewPod().WithContainersImages(distinctImages(10)...).Build(), ctx: ctx}
+	imageNamesSet := pod.imagesNamesSet()
+	for _, bb := range []struct {
+		name                     string
+		maxConcurrentInspections int
+	}{
+		{name: "serial", maxConcurrentInspections: 1},
+		{name: "parallel", maxConcurrentInspections: 10},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := inspectImages(ctx, cache, imageNamesSet, nil, bb.maxConcurrentInspections); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestPod_getArchitecturePredicate(t *testing.T) {
+	tests := []struct {
+		name               string
+		pod                *v1.Pod
+		pullSecretDataList [][]byte
+		// Be aware that the values in the want.Values slice must be sorted alphabetically
+		want    v1.NodeSelectorRequirement
+		wantErr bool
+	}{
+		{
+			name: "pod with several containers using multi-arch images",
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Image: fake.MultiArchImage,
+						},
+					},
+					InitContainers: []v1.Container{
+						{
+							Image: fake.MultiArchImage2,
+						},
+					},
+				},
+			},
+			want: v1.NodeSelectorRequirement{
+				Key:      utils.ArchLabel,
+				Operator: v1.NodeSelectorOpIn,
+				Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+			},
+		},
+		{
+			name: "pod with non-existing image",
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Image: fake.MultiArchImage,
+						},
+						{
+							Image: "non-existing-image",
+						},
+					},
+				},
+			},
+			wantErr: true,
+			want:    v1.NodeSelectorRequirement{},
+		},
+		{
+			name: "pod with conflicting architectures",
+			pod:  NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).Build(),
+			want: v1.NodeSelectorRequirement{
+				Key:      utils.NoSupportedArchLabel,
+				Operator: v1.NodeSelectorOpExists,
+			},
+		},
+		{
+			name: "pod with arm variant images",
+			pod:  NewPod().WithContainersImages(fake.MultiArchArmImage, fake.SingleArchArmV7Image).Build(),
+			want: v1.NodeSelectorRequirement{
+				Key:      utils.ArchLabel,
+				Operator: v1.NodeSelectorOpIn,
+				Values:   []string{"arm"},
+			},
+		},
+		{
+			name: "pod with a wasm image",
+			pod:  NewPod().WithContainersImages(fake.WasmImage).Build(),
+			want: v1.NodeSelectorRequirement{
+				Key:      utils.ArchLabel,
+				Operator: v1.NodeSelectorOpIn,
+				Values:   []string{utils.ArchitectureWasm32},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: fake.FacadeSingleton(),
+			}
+			got, _, err := pod.getArchitecturePredicate(tt.pullSecretDataList)
+			g := NewGomegaWithT(t)
+			g.Expect(err).Should(WithTransform(func(err error) bool { return err != nil }, Equal(tt.wantErr)),
+				"error expectation failed")
+			// sort the architectures to make the comparison easier
+			sort.Strings(got.Values)
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPod_setArchNodeAffinity(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want *v1.Pod
+	}{
+		{
+			name: "pod with empty node selector terms",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions().Build(),
+			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+					},
+				},
+			).Build(),
+		},
+		{
+			name: "pod with node selector terms and nil match expressions",
+			pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image).WithNodeSelectorTermsMatchExpressions(
+				nil).Build(),
 			want: NewPod().WithContainersImages(fake.SingleArchAmd64Image).WithNodeSelectorTermsMatchExpressions(
 				[]v1.NodeSelectorRequirement{
 					{
@@ -513,17 +1284,16 @@ func TestPod_setArchNodeAffinity(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			imageInspectionCache = fake.FacadeSingleton()
 			pod := &Pod{
-				Pod: *tt.pod,
-				ctx: ctx,
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: fake.FacadeSingleton(),
 			}
 			g := NewGomegaWithT(t)
-			pred, err := pod.getArchitecturePredicate(nil)
+			pred, _, err := pod.getArchitecturePredicate(nil)
 			g.Expect(err).ShouldNot(HaveOccurred())
 			pod.setRequiredArchNodeAffinity(pred)
 			g.Expect(pod.Spec.Affinity).Should(Equal(tt.want.Spec.Affinity))
-			imageInspectionCache = mmoimage.FacadeSingleton()
 		})
 	}
 }
@@ -563,10 +1333,10 @@ func TestPod_SetPreferredArchNodeAffinityWithCPPC(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			imageInspectionCache = fake.FacadeSingleton()
 			pod := &Pod{
-				Pod: *tt.pod,
-				ctx: ctx,
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: fake.FacadeSingleton(),
 			}
 			g := NewGomegaWithT(t)
 			pod.SetPreferredArchNodeAffinity(
@@ -575,7 +1345,164 @@ func TestPod_SetPreferredArchNodeAffinityWithCPPC(t *testing.T) {
 					WithNodeAffinityScoring(true).
 					WithNodeAffinityScoringTerm(utils.ArchitectureAmd64, 1).Build())
 			g.Expect(pod.Spec.Affinity).Should(Equal(tt.want.Spec.Affinity))
-			imageInspectionCache = mmoimage.FacadeSingleton()
+		})
+	}
+}
+
+func TestPod_SetNodeAffinityArchRequirement_PreferredArchitectureAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		cppc       *v1beta1.ClusterPodPlacementConfig
+		want       []v1.PreferredSchedulingTerm
+		wantEvents []string
+	}{
+		{
+			name: "pod with the annotation",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithAnnotations(utils.PreferredArchitectureAnnotation, "arm64:100,amd64:50").Build(),
+			want: []v1.PreferredSchedulingTerm{
+				*NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureArm64).WithWeight(100).Build(),
+				*NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureAmd64).WithWeight(50).Build(),
+			},
+		},
+		{
+			name: "pod with the annotation and the NodeAffinityScoring plugin enabled",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithAnnotations(utils.PreferredArchitectureAnnotation, " arm64 : 1 ").Build(),
+			cppc: NewClusterPodPlacementConfig().WithNodeAffinityScoring(true).
+				WithNodeAffinityScoringTerm(utils.ArchitectureAmd64, 80).Build(),
+			want: []v1.PreferredSchedulingTerm{
+				*NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureArm64).WithWeight(1).Build(),
+			},
+		},
+		{
+			name: "pod with an invalid annotation and the NodeAffinityScoring plugin enabled",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithAnnotations(utils.PreferredArchitectureAnnotation, "arm64:101").Build(),
+			cppc: NewClusterPodPlacementConfig().WithNodeAffinityScoring(true).
+				WithNodeAffinityScoringTerm(utils.ArchitectureAmd64, 80).Build(),
+			want: []v1.PreferredSchedulingTerm{
+				*NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureAmd64).WithWeight(80).Build(),
+			},
+			wantEvents: []string{ArchitecturePreferenceInvalid},
+		},
+		{
+			name: "pod with an invalid annotation",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithAnnotations(utils.PreferredArchitectureAnnotation, "riscv64:10").Build(),
+			wantEvents: []string{ArchitecturePreferenceInvalid},
+		},
+		{
+			name: "pod with a user-provided architecture preference",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithAnnotations(utils.PreferredArchitectureAnnotation, "arm64:100").
+				WithPreferredDuringSchedulingIgnoredDuringExecution(
+					NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureAmd64).WithWeight(30).Build()).
+				Build(),
+			want: []v1.PreferredSchedulingTerm{
+				*NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureAmd64).WithWeight(30).Build(),
+			},
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			recorder := record.NewFakeRecorder(10)
+			pod := &Pod{
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				recorder:       recorder,
+				imageInspector: fake.FacadeSingleton(),
+			}
+			if tt.cppc != nil {
+				pod.SetPreferredArchNodeAffinity(tt.cppc)
+			}
+			_, err := pod.SetNodeAffinityArchRequirement(nil)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(Equal(tt.want))
+			g.Expect(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).
+				To(HaveLen(1), "the required node affinity should be set alongside the preferred one")
+			close(recorder.Events)
+			var reasons []string
+			for event := range recorder.Events {
+				if strings.Contains(event, ArchitecturePreferenceInvalid) {
+					reasons = append(reasons, ArchitecturePreferenceInvalid)
+				}
+			}
+			g.Expect(reasons).To(Equal(tt.wantEvents))
+		})
+	}
+}
+
+func TestParsePreferredArchitectures(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		want      []plugins.NodeAffinityScoringPlatformTerm
+		wantError string
+	}{
+		{
+			name:  "single architecture",
+			value: "arm64:100",
+			want:  []plugins.NodeAffinityScoringPlatformTerm{{Architecture: utils.ArchitectureArm64, Weight: 100}},
+		},
+		{
+			name:  "multiple architectures with spaces",
+			value: "arm64:100, amd64 : 50,s390x:1",
+			want: []plugins.NodeAffinityScoringPlatformTerm{
+				{Architecture: utils.ArchitectureArm64, Weight: 100},
+				{Architecture: utils.ArchitectureAmd64, Weight: 50},
+				{Architecture: utils.ArchitectureS390x, Weight: 1},
+			},
+		},
+		{
+			name:      "empty value",
+			value:     "",
+			wantError: `"" is not an architecture:weight pair`,
+		},
+		{
+			name:      "missing weight",
+			value:     "arm64",
+			wantError: `"arm64" is not an architecture:weight pair`,
+		},
+		{
+			name:      "unsupported architecture",
+			value:     "arm64:100,riscv64:50",
+			wantError: `unsupported architecture "riscv64"`,
+		},
+		{
+			name:      "duplicate architecture",
+			value:     "arm64:100,arm64:50",
+			wantError: `duplicate architecture "arm64"`,
+		},
+		{
+			name:      "weight too low",
+			value:     "arm64:0",
+			wantError: `the weight of the architecture "arm64" must be an integer from 1 to 100, got "0"`,
+		},
+		{
+			name:      "weight too high",
+			value:     "amd64:101",
+			wantError: `the weight of the architecture "amd64" must be an integer from 1 to 100, got "101"`,
+		},
+		{
+			name:      "weight not an integer",
+			value:     "amd64:high",
+			wantError: `the weight of the architecture "amd64" must be an integer from 1 to 100, got "high"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			got, err := parsePreferredArchitectures(tt.value)
+			if tt.wantError != "" {
+				g.Expect(err).To(MatchError(tt.wantError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
 		})
 	}
 }
@@ -594,10 +1521,10 @@ func TestPod_SetPreferredArchNodeAffinity(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			imageInspectionCache = fake.FacadeSingleton()
 			pod := &Pod{
-				Pod: *tt.pod,
-				ctx: ctx,
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: fake.FacadeSingleton(),
 			}
 			g := NewGomegaWithT(t)
 			pod.SetPreferredArchNodeAffinity(&v1beta1.ClusterPodPlacementConfig{
@@ -616,7 +1543,6 @@ func TestPod_SetPreferredArchNodeAffinity(t *testing.T) {
 				},
 			})
 			g.Expect(pod.Spec.Affinity).Should(Equal(tt.want.Spec.Affinity))
-			imageInspectionCache = mmoimage.FacadeSingleton()
 		})
 	}
 }
@@ -664,159 +1590,910 @@ func TestPod_SetNodeAffinityArchRequirement(t *testing.T) {
 				utils.ArchLabel, utils.ArchitectureArm64).Build(),
 		},*/
 		{
-			name: "pod with no affinity",
-			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
-			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
-				[]v1.NodeSelectorRequirement{
-					{
-						Key:      utils.ArchLabel,
-						Operator: v1.NodeSelectorOpIn,
-						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
-					},
-				}).Build(),
+			name: "pod with no affinity",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+					},
+				}).Build(),
+		},
+		{
+			name: "pod with no node affinity",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+					},
+				}).Build(),
+		},
+		{
+			name: "pod with no required during scheduling ignored during execution",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithNodeAffinity().Build(),
+			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+					},
+				}).Build(),
+		},
+		{
+			name: "pod with predefined node selector terms in the required during scheduling ignored during execution",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions([]v1.NodeSelectorRequirement{
+				{
+					Key:      "foo",
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{"bar"},
+				},
+			}).Build(),
+			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      "foo",
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{"bar"},
+					},
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+					},
+				}).Build(),
+		},
+		{
+			name: "other affinity types should not be modified",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithAffinity(&v1.Affinity{
+				PodAffinity: &v1.PodAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+						{
+							TopologyKey: "foo",
+						},
+					},
+				},
+				NodeAffinity: &v1.NodeAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
+						{
+							Weight: 1,
+							Preference: v1.NodeSelectorTerm{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{
+										Key:      "foo",
+										Operator: v1.NodeSelectorOpIn,
+										Values:   []string{"bar"},
+									},
+								},
+							},
+						},
+					},
+				},
+			}).Build(),
+			want: NewPod().WithContainersImages(fake.MultiArchImage).WithAffinity(&v1.Affinity{
+				PodAffinity: &v1.PodAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+						{
+							TopologyKey: "foo",
+						},
+					},
+				},
+				NodeAffinity: &v1.NodeAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
+						{
+							Weight: 1,
+							Preference: v1.NodeSelectorTerm{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{
+										Key:      "foo",
+										Operator: v1.NodeSelectorOpIn,
+										Values:   []string{"bar"},
+									},
+								},
+							},
+						},
+					},
+				},
+			}).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+					},
+				}).Build(),
+		},
+		{
+			name:      "should not modify the pod if unable to inspect the images",
+			pod:       NewPod().WithContainersImages(fake.MultiArchImage, "non-readable-image").Build(),
+			want:      NewPod().WithContainersImages(fake.MultiArchImage, "non-readable-image").Build(),
+			expectErr: true,
+		},
+		{
+			name: "pod with a valid architecture override annotation",
+			// the image cannot be inspected: the annotation skips the inspection
+			pod: NewPod().WithContainersImages("non-readable-image").WithAnnotations(
+				utils.ArchitectureOverrideAnnotation, "arm64, amd64").Build(),
+			want: NewPod().WithContainersImages("non-readable-image").WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+					},
+				}).Build(),
+		},
+		{
+			name: "pod with an invalid architecture override annotation",
+			// the annotation is ignored and the images are inspected
+			pod: NewPod().WithContainersImages(fake.SingleArchArm64Image).WithAnnotations(
+				utils.ArchitectureOverrideAnnotation, "amd64,x86_64").Build(),
+			want: NewPod().WithContainersImages(fake.SingleArchArm64Image).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureArm64},
+					},
+				}).Build(),
+		},
+		{
+			name: "pod with an architecture override annotation and predefined node selector terms",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithAnnotations(
+				utils.ArchitectureOverrideAnnotation, utils.ArchitectureS390x).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      "foo",
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{"bar"},
+					},
+				}).Build(),
+			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      "foo",
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{"bar"},
+					},
+					{
+						Key:      utils.ArchLabel,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{utils.ArchitectureS390x},
+					},
+				}).Build(),
+		},
+		{
+			name: "should prevent the pod from being scheduled when no common architecture is found",
+			pod:  NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).Build(),
+			want: NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{
+						Key:      utils.NoSupportedArchLabel,
+						Operator: v1.NodeSelectorOpExists,
+					},
+				}).Build(),
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: fake.FacadeSingleton(),
+			}
+			_, err := pod.SetNodeAffinityArchRequirement(tt.pullSecretDataList)
+			g := NewGomegaWithT(t)
+			if tt.expectErr {
+				g.Expect(err).Should(HaveOccurred())
+			} else {
+				g.Expect(err).ShouldNot(HaveOccurred())
+			}
+			g.Expect(pod.Spec.Affinity).Should(Equal(tt.want.Spec.Affinity))
+		})
+	}
+}
+
+func BenchmarkPodSetNodeAffinityArchRequirement_SingleArch(b *testing.B) {
+	benchmarkPodSetNodeAffinityArchRequirement(b, NewPod().WithContainersImages(fake.SingleArchAmd64Image).Build())
+}
+
+func BenchmarkPodSetNodeAffinityArchRequirement_MultiArch(b *testing.B) {
+	benchmarkPodSetNodeAffinityArchRequirement(b, NewPod().WithContainersImages(fake.MultiArchImage).Build())
+}
+
+func BenchmarkPodSetNodeAffinityArchRequirement_Conflict(b *testing.B) {
+	benchmarkPodSetNodeAffinityArchRequirement(b, NewPod().WithContainersImages(fake.SingleArchAmd64Image,
+		fake.SingleArchArm64Image).Build())
+}
+
+// benchmarkPodSetNodeAffinityArchRequirement measures SetNodeAffinityArchRequirement on copies of the given pod. The
+// baseline numbers are in BENCHMARK.md.
+func benchmarkPodSetNodeAffinityArchRequirement(b *testing.B, template *v1.Pod) {
+	metrics.InitPodPlacementControllerMetrics()
+	imageInspector := fake.FacadeSingleton()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		pod := &Pod{
+			Pod:            *template.DeepCopy(),
+			ctx:            ctx,
+			imageInspector: imageInspector,
+		}
+		b.StartTimer()
+		if _, err := pod.SetNodeAffinityArchRequirement(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestValidateNoArchMatchField(t *testing.T) {
+	tests := []struct {
+		name    string
+		term    v1.NodeSelectorTerm
+		wantErr bool
+	}{
+		{
+			name: "term without matchFields",
+			term: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureAmd64}},
+			}},
+		},
+		{
+			name: "term with a matchFields entry on the node name",
+			term: v1.NodeSelectorTerm{MatchFields: []v1.NodeSelectorRequirement{
+				{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"node-1"}},
+			}},
+		},
+		{
+			name: "term with a matchFields entry on the architecture label",
+			term: v1.NodeSelectorTerm{MatchFields: []v1.NodeSelectorRequirement{
+				{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"node-1"}},
+				{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureArm64}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "term with a matchFields entry on the no supported architecture label",
+			term: v1.NodeSelectorTerm{MatchFields: []v1.NodeSelectorRequirement{
+				{Key: utils.NoSupportedArchLabel, Operator: v1.NodeSelectorOpExists},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			err := validateNoArchMatchField(tt.term)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(ErrArchMatchFieldConflict))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func TestPod_SetNodeAffinityArchRequirement_ArchMatchFieldConflict(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitPodPlacementControllerMetrics()
+	affinity := &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{
+			{MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: "foo", Operator: v1.NodeSelectorOpIn, Values: []string{"bar"}},
+			}},
+			{MatchFields: []v1.NodeSelectorRequirement{
+				{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureArm64}},
+			}},
+		}},
+	}}
+	recorder := record.NewFakeRecorder(10)
+	pod := &Pod{
+		Pod:            *NewPod().WithContainersImages(fake.MultiArchImage).WithAffinity(affinity.DeepCopy()).Build(),
+		ctx:            ctx,
+		recorder:       recorder,
+		imageInspector: fake.FacadeSingleton(),
+	}
+	set, err := pod.SetNodeAffinityArchRequirement(nil)
+	g.Expect(err).To(MatchError(ErrArchMatchFieldConflict))
+	g.Expect(set).To(BeFalse())
+	g.Expect(pod.Spec.Affinity).To(Equal(affinity), "the node affinity should not be mutated")
+	g.Expect(pod.Labels).NotTo(HaveKey(utils.MultiArchLabel), "the pod should not be labeled")
+	g.Expect(recorder.Events).To(Receive(HavePrefix(fmt.Sprintf("%s %s %s", v1.EventTypeWarning,
+		ArchitectureMatchFieldConflict, ArchitectureMatchFieldConflictMsg))))
+
+	// The fallback architectures of a timed out inspection are not set either
+	err = pod.ApplyInspectionTimeoutFallback(NewClusterPodPlacementConfig().
+		WithInspectionTimeoutFallback(v1beta1.InspectionTimeoutFallbackAllowAll).Build(), context.DeadlineExceeded)
+	g.Expect(err).To(MatchError(ErrArchMatchFieldConflict))
+	g.Expect(pod.Spec.Affinity).To(Equal(affinity), "the node affinity should not be mutated")
+}
+
+func TestPod_SetNodeAffinityArchRequirement_Idempotent(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+	}{
+		{
+			name: "multi-arch image",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+		},
+		{
+			name: "images with no common architecture",
+			pod:  NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).Build(),
+		},
+		{
+			name: "pod with multiple node selector terms",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{
+					{Key: "foo", Operator: v1.NodeSelectorOpIn, Values: []string{"bar"}},
+				}, []v1.NodeSelectorRequirement{
+					{Key: "baz", Operator: v1.NodeSelectorOpIn, Values: []string{"foo"}},
+				}).Build(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			metrics.InitPodPlacementControllerMetrics()
+			recorder := record.NewFakeRecorder(10)
+			pod := &Pod{
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				recorder:       recorder,
+				imageInspector: fake.FacadeSingleton(),
+			}
+			_, err := pod.SetNodeAffinityArchRequirement(nil)
+			g.Expect(err).NotTo(HaveOccurred())
+			processed := pod.DeepCopy()
+			for len(recorder.Events) > 0 {
+				<-recorder.Events
+			}
+
+			set, err := pod.SetNodeAffinityArchRequirement(nil)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(set).To(BeFalse())
+			g.Expect(&pod.Pod).To(Equal(processed), "the second call should not change the pod")
+			g.Expect(recorder.Events).To(BeEmpty(), "the second call should not publish events")
+		})
+	}
+}
+
+func TestPod_setRequiredArchNodeAffinity_Idempotent(t *testing.T) {
+	g := NewGomegaWithT(t)
+	recorder := record.NewFakeRecorder(10)
+	pod := &Pod{
+		Pod: *NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+			[]v1.NodeSelectorRequirement{
+				{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn,
+					Values: []string{utils.ArchitectureArm64, utils.ArchitectureAmd64}},
+			}).Build(),
+		ctx:      ctx,
+		recorder: recorder,
+	}
+	processed := pod.DeepCopy()
+	// The values of the requirement are the same, in a different order
+	pod.setRequiredArchNodeAffinity(v1.NodeSelectorRequirement{
+		Key:      utils.ArchLabel,
+		Operator: v1.NodeSelectorOpIn,
+		Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+	})
+	g.Expect(&pod.Pod).To(Equal(processed), "the pod should not change")
+	g.Expect(recorder.Events).To(BeEmpty(), "no event should be published")
+}
+
+func TestPod_setRequiredArchNodeAffinity_PreservesPodAffinity(t *testing.T) {
+	pod := &Pod{
+		Pod: *NewPod().WithContainersImages(fake.MultiArchImage).WithAffinity(&v1.Affinity{
+			PodAffinity: &v1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+					{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cache"}},
+						TopologyKey:   "kubernetes.io/hostname",
+					},
+				},
+			},
+			PodAntiAffinity: &v1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+					{
+						Weight: 50,
+						PodAffinityTerm: v1.PodAffinityTerm{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+							TopologyKey:   "topology.kubernetes.io/zone",
+						},
+					},
+				},
+			},
+		}).WithRequiredDuringSchedulingIgnoredDuringExecution().Build(),
+		ctx:      ctx,
+		recorder: record.NewFakeRecorder(10),
+	}
+	podAffinity := pod.Spec.Affinity.PodAffinity.DeepCopy()
+	podAntiAffinity := pod.Spec.Affinity.PodAntiAffinity.DeepCopy()
+	pod.setRequiredArchNodeAffinity(v1.NodeSelectorRequirement{
+		Key:      utils.ArchLabel,
+		Operator: v1.NodeSelectorOpIn,
+		Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+	})
+	if !reflect.DeepEqual(pod.Spec.Affinity.PodAffinity, podAffinity) {
+		t.Errorf("the pod affinity was changed: got %v, want %v", pod.Spec.Affinity.PodAffinity, podAffinity)
+	}
+	if !reflect.DeepEqual(pod.Spec.Affinity.PodAntiAffinity, podAntiAffinity) {
+		t.Errorf("the pod anti-affinity was changed: got %v, want %v", pod.Spec.Affinity.PodAntiAffinity,
+			podAntiAffinity)
+	}
+}
+
+func TestPod_setRequiredArchNodeAffinity_RequireArchLabelOnNodes(t *testing.T) {
+	archIn := v1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn,
+		Values: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64}}
+	archExists := v1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpExists}
+	archNotIn := v1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpNotIn,
+		Values: []string{utils.ArchitectureS390x}}
+	noSupportedArch := v1.NodeSelectorRequirement{Key: utils.NoSupportedArchLabel, Operator: v1.NodeSelectorOpExists}
+	hostname := v1.NodeSelectorRequirement{Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpIn,
+		Values: []string{"worker-0"}}
+	tests := []struct {
+		name                    string
+		pod                     *v1.Pod
+		requirement             v1.NodeSelectorRequirement
+		requireArchLabelOnNodes bool
+		want                    [][]v1.NodeSelectorRequirement
+	}{
+		{
+			name:                    "architectures requirement",
+			pod:                     NewPod().Build(),
+			requirement:             archIn,
+			requireArchLabelOnNodes: true,
+			want:                    [][]v1.NodeSelectorRequirement{{archIn}},
+		},
+		{
+			name:                    "no supported architecture requirement",
+			pod:                     NewPod().Build(),
+			requirement:             noSupportedArch,
+			requireArchLabelOnNodes: true,
+			want:                    [][]v1.NodeSelectorRequirement{{noSupportedArch, archExists}},
+		},
+		{
+			name:                    "no supported architecture requirement, not requiring the label",
+			pod:                     NewPod().Build(),
+			requirement:             noSupportedArch,
+			requireArchLabelOnNodes: false,
+			want:                    [][]v1.NodeSelectorRequirement{{noSupportedArch}},
+		},
+		{
+			name: "multiple node selector terms",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{hostname},
+				[]v1.NodeSelectorRequirement{archExists}).Build(),
+			requirement:             noSupportedArch,
+			requireArchLabelOnNodes: true,
+			want: [][]v1.NodeSelectorRequirement{
+				{hostname, noSupportedArch, archExists},
+				{archExists, noSupportedArch},
+			},
+		},
+		{
+			name: "node selector term with a NotIn requirement on the architecture",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{archNotIn},
+				[]v1.NodeSelectorRequirement{hostname}).Build(),
+			requirement:             archIn,
+			requireArchLabelOnNodes: true,
+			want: [][]v1.NodeSelectorRequirement{
+				{archNotIn, archExists},
+				{hostname, archIn},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{
+				Pod:                     *tt.pod,
+				ctx:                     ctx,
+				recorder:                record.NewFakeRecorder(10),
+				requireArchLabelOnNodes: tt.requireArchLabelOnNodes,
+			}
+			g.Expect(pod.setNodeAffinityArchRequirement(tt.requirement, tt.requirement.Values)).To(Succeed())
+			terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			g.Expect(terms).To(HaveLen(len(tt.want)))
+			for i := range tt.want {
+				g.Expect(terms[i].MatchExpressions).To(Equal(tt.want[i]))
+			}
+		})
+	}
+}
+
+func TestArchLabelRequiredOnNodes(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(archLabelRequiredOnNodes(nil)).To(BeFalse())
+	g.Expect(archLabelRequiredOnNodes(NewClusterPodPlacementConfig().Build())).To(BeFalse())
+	g.Expect(archLabelRequiredOnNodes(NewClusterPodPlacementConfig().WithRequireArchLabelOnNodes(true).
+		Build())).To(BeTrue())
+}
+
+func TestEqualNodeSelectorRequirements(t *testing.T) {
+	requirement := v1.NodeSelectorRequirement{
+		Key:      utils.ArchLabel,
+		Operator: v1.NodeSelectorOpIn,
+		Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+	}
+	tests := []struct {
+		name  string
+		other v1.NodeSelectorRequirement
+		want  bool
+	}{
+		{
+			name:  "same requirement",
+			other: *requirement.DeepCopy(),
+			want:  true,
+		},
+		{
+			name: "values in a different order",
+			other: v1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn,
+				Values: []string{utils.ArchitectureArm64, utils.ArchitectureAmd64}},
+			want: true,
+		},
+		{
+			name: "different key",
+			other: v1.NodeSelectorRequirement{Key: "foo", Operator: v1.NodeSelectorOpIn,
+				Values: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64}},
+		},
+		{
+			name: "different operator",
+			other: v1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpNotIn,
+				Values: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64}},
+		},
+		{
+			name: "subset of the values",
+			other: v1.NodeSelectorRequirement{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn,
+				Values: []string{utils.ArchitectureAmd64}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(equalNodeSelectorRequirements(requirement, tt.other)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPod_DryRunSetNodeAffinityArchRequirement(t *testing.T) {
+	tests := []struct {
+		name              string
+		pod               *v1.Pod
+		wantRequirement   *v1.NodeSelectorRequirement
+		wantArchitectures []string
+		wantErr           error
+	}{
+		{
+			name: "pod with no affinity",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+			wantRequirement: &v1.NodeSelectorRequirement{
+				Key:      utils.ArchLabel,
+				Operator: v1.NodeSelectorOpIn,
+				Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+			},
+			wantArchitectures: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+		},
+		{
+			name: "pod with node selector terms",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage, fake.SingleArchArm64Image).
+				WithNodeSelectorTermsMatchExpressions([]v1.NodeSelectorRequirement{
+					{Key: "foo", Operator: v1.NodeSelectorOpIn, Values: []string{"bar"}},
+				}).Build(),
+			wantRequirement: &v1.NodeSelectorRequirement{
+				Key:      utils.ArchLabel,
+				Operator: v1.NodeSelectorOpIn,
+				Values:   []string{utils.ArchitectureArm64},
+			},
+			wantArchitectures: []string{utils.ArchitectureArm64},
+		},
+		{
+			name: "pod with the architecture in its node selector",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).
+				WithNodeSelectors(utils.ArchLabel, utils.ArchitectureAmd64).Build(),
+		},
+		{
+			name: "pod with a conflicting matchFields entry",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithAffinity(&v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{MatchFields: []v1.NodeSelectorRequirement{
+						{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureArm64}},
+					}},
+				}},
+			}}).Build(),
+			wantErr: ErrArchMatchFieldConflict,
+		},
+		{
+			name:    "pod with an image that cannot be inspected",
+			pod:     NewPod().WithContainersImages(fake.TimeoutImage).Build(),
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			recorder := record.NewFakeRecorder(10)
+			pod := &Pod{
+				Pod:            *tt.pod.DeepCopy(),
+				ctx:            ctx,
+				recorder:       recorder,
+				imageInspector: fake.FacadeSingleton(),
+			}
+			requirement, architectures, err := pod.DryRunSetNodeAffinityArchRequirement(nil)
+			g.Expect(pod.Pod).To(Equal(*tt.pod), "the pod should not be mutated")
+			g.Expect(recorder.Events).To(BeEmpty(), "no events should be published")
+			if tt.wantErr != nil {
+				g.Expect(err).To(MatchError(tt.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(requirement).To(Equal(tt.wantRequirement))
+			g.Expect(architectures).To(Equal(tt.wantArchitectures))
+			if requirement == nil {
+				return
+			}
+			// The predicate is the one the real method applies to every node selector term
+			set, err := pod.SetNodeAffinityArchRequirement(nil)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(set).To(BeTrue())
+			for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+				g.Expect(term.MatchExpressions).To(ContainElement(*requirement))
+			}
+		})
+	}
+}
+
+func TestPod_SetNodeAffinityArchRequirement_WasmLabel(t *testing.T) {
+	tests := []struct {
+		name          string
+		pod           *v1.Pod
+		wantWasmLabel bool
+	}{
+		{
+			name:          "pod with a wasm image",
+			pod:           NewPod().WithContainersImages(fake.WasmImage).Build(),
+			wantWasmLabel: true,
+		},
+		{
+			name: "pod with wasm and single-arch images",
+			pod:  NewPod().WithContainersImages(fake.WasmImage, fake.SingleArchAmd64Image).Build(),
+		},
+		{
+			name: "pod without wasm images",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Pod:            *tt.pod,
+				ctx:            ctx,
+				imageInspector: fake.FacadeSingleton(),
+			}
+			_, err := pod.SetNodeAffinityArchRequirement(nil)
+			g := NewGomegaWithT(t)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			if tt.wantWasmLabel {
+				g.Expect(pod.Labels).To(HaveKeyWithValue(utils.WasmArchLabel, ""))
+			} else {
+				g.Expect(pod.Labels).NotTo(HaveKey(utils.WasmArchLabel))
+			}
+		})
+	}
+}
+
+func TestPod_SetNodeAffinityArchRequirement_ArchitectureLabelsDisabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		wantValues []string
+	}{
+		{
+			name:       "pod with a multi-arch image",
+			pod:        NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+			wantValues: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
+		},
+		{
+			name:       "pod with a single-arch image",
+			pod:        NewPod().WithContainersImages(fake.SingleArchArm64Image).Build(),
+			wantValues: []string{utils.ArchitectureArm64},
+		},
+		{
+			name: "pod with images with no common architecture",
+			pod:  NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).Build(),
+		},
+		{
+			name:       "pod with a wasm image",
+			pod:        NewPod().WithContainersImages(fake.WasmImage).Build(),
+			wantValues: []string{utils.ArchitectureWasm32},
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	cppc := NewClusterPodPlacementConfig().WithEnableArchitectureLabels(false).Build()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Pod:                       *tt.pod,
+				ctx:                       ctx,
+				imageInspector:            fake.FacadeSingleton(),
+				disableArchitectureLabels: architectureLabelsDisabled(cppc),
+			}
+			_, err := pod.SetNodeAffinityArchRequirement(nil)
+			g := NewGomegaWithT(t)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			for label := range pod.Labels {
+				g.Expect(label).To(BeElementOf(utils.NodeAffinityLabel),
+					"only the node affinity label should be set on the pod")
+			}
+			g.Expect(pod.Labels).To(HaveKeyWithValue(utils.NodeAffinityLabel, utils.NodeAffinityLabelValueSet))
+			terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			g.Expect(terms).To(HaveLen(1))
+			g.Expect(terms[0].MatchExpressions).To(HaveLen(1))
+			if tt.wantValues == nil {
+				g.Expect(terms[0].MatchExpressions[0].Key).To(Equal(utils.NoSupportedArchLabel))
+			} else {
+				g.Expect(terms[0].MatchExpressions[0].Values).To(Equal(tt.wantValues))
+			}
+		})
+	}
+}
+
+func TestArchitectureLabelsDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(architectureLabelsDisabled(nil)).To(BeFalse())
+	g.Expect(architectureLabelsDisabled(NewClusterPodPlacementConfig().Build())).To(BeFalse())
+	g.Expect(architectureLabelsDisabled(NewClusterPodPlacementConfig().WithEnableArchitectureLabels(true).
+		Build())).To(BeFalse())
+	g.Expect(architectureLabelsDisabled(NewClusterPodPlacementConfig().WithEnableArchitectureLabels(false).
+		Build())).To(BeTrue())
+}
+
+func TestPod_ApplyInspectionTimeoutFallback(t *testing.T) {
+	tests := []struct {
+		name                   string
+		cppc                   *v1beta1.ClusterPodPlacementConfig
+		wantFallback           v1beta1.InspectionTimeoutFallback
+		wantErr                bool
+		wantArchitectures      []string
+		wantEventMessagePrefix string
+	}{
+		{
+			name:                   "no ClusterPodPlacementConfig",
+			wantFallback:           v1beta1.InspectionTimeoutFallbackBlock,
+			wantErr:                true,
+			wantEventMessagePrefix: ImageInspectionTimeoutBlockMsg,
 		},
 		{
-			name: "pod with no node affinity",
-			pod:  NewPod().WithContainersImages(fake.MultiArchImage).Build(),
-			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
-				[]v1.NodeSelectorRequirement{
-					{
-						Key:      utils.ArchLabel,
-						Operator: v1.NodeSelectorOpIn,
-						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
-					},
-				}).Build(),
+			name: "block",
+			cppc: NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithInspectionTimeoutFallback(v1beta1.InspectionTimeoutFallbackBlock).Build(),
+			wantFallback:           v1beta1.InspectionTimeoutFallbackBlock,
+			wantErr:                true,
+			wantEventMessagePrefix: ImageInspectionTimeoutBlockMsg,
 		},
 		{
-			name: "pod with no required during scheduling ignored during execution",
-			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithNodeAffinity().Build(),
-			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
-				[]v1.NodeSelectorRequirement{
-					{
-						Key:      utils.ArchLabel,
-						Operator: v1.NodeSelectorOpIn,
-						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
-					},
-				}).Build(),
+			name: "allow-all",
+			cppc: NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithInspectionTimeoutFallback(v1beta1.InspectionTimeoutFallbackAllowAll).Build(),
+			wantFallback: v1beta1.InspectionTimeoutFallbackAllowAll,
+			wantArchitectures: []string{utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitecturePpc64le,
+				utils.ArchitectureS390x},
+			wantEventMessagePrefix: ImageInspectionTimeoutAllowAllMsg,
 		},
 		{
-			name: "pod with predefined node selector terms in the required during scheduling ignored during execution",
-			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions([]v1.NodeSelectorRequirement{
-				{
-					Key:      "foo",
-					Operator: v1.NodeSelectorOpIn,
-					Values:   []string{"bar"},
-				},
-			}).Build(),
-			want: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
-				[]v1.NodeSelectorRequirement{
-					{
-						Key:      "foo",
-						Operator: v1.NodeSelectorOpIn,
-						Values:   []string{"bar"},
-					},
-					{
-						Key:      utils.ArchLabel,
-						Operator: v1.NodeSelectorOpIn,
-						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
-					},
-				}).Build(),
+			name: "allow-configured",
+			cppc: NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithInspectionTimeoutFallback(v1beta1.InspectionTimeoutFallbackAllowConfigured).
+				WithFallbackArchitectures(utils.ArchitectureS390x, utils.ArchitectureAmd64).Build(),
+			wantFallback:           v1beta1.InspectionTimeoutFallbackAllowConfigured,
+			wantArchitectures:      []string{utils.ArchitectureAmd64, utils.ArchitectureS390x},
+			wantEventMessagePrefix: ImageInspectionTimeoutAllowConfiguredMsg,
 		},
-		{
-			name: "other affinity types should not be modified",
-			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithAffinity(&v1.Affinity{
-				PodAffinity: &v1.PodAffinity{
-					RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
-						{
-							TopologyKey: "foo",
-						},
-					},
-				},
-				NodeAffinity: &v1.NodeAffinity{
-					PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
-						{
-							Weight: 1,
-							Preference: v1.NodeSelectorTerm{
-								MatchExpressions: []v1.NodeSelectorRequirement{
-									{
-										Key:      "foo",
-										Operator: v1.NodeSelectorOpIn,
-										Values:   []string{"bar"},
-									},
-								},
-							},
-						},
-					},
-				},
-			}).Build(),
-			want: NewPod().WithContainersImages(fake.MultiArchImage).WithAffinity(&v1.Affinity{
-				PodAffinity: &v1.PodAffinity{
-					RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
-						{
-							TopologyKey: "foo",
-						},
-					},
-				},
-				NodeAffinity: &v1.NodeAffinity{
-					PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	fallbacks := func(fallback v1beta1.InspectionTimeoutFallback) float64 {
+		m := &dto.Metric{}
+		if err := metrics.InspectionTimeoutFallbacks.WithLabelValues(string(fallback)).Write(m); err != nil {
+			t.Fatalf("failed to read the inspection timeout fallbacks metric: %v", err)
+		}
+		return m.GetCounter().GetValue()
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			recorder := record.NewFakeRecorder(10)
+			pod := &Pod{
+				Pod:            *NewPod().WithContainersImages(fake.MultiArchImage, fake.TimeoutImage).Build(),
+				ctx:            ctx,
+				recorder:       recorder,
+				imageInspector: fake.FacadeSingleton(),
+			}
+			_, err := pod.SetNodeAffinityArchRequirement(nil)
+			g.Expect(err).To(MatchError(context.DeadlineExceeded))
+			g.Expect(mmoimage.IsTimeoutError(err)).To(BeTrue())
+			previousFallbacks := fallbacks(tt.wantFallback)
+
+			err = pod.ApplyInspectionTimeoutFallback(tt.cppc, err)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(context.DeadlineExceeded))
+				g.Expect(pod.Spec.Affinity).To(BeNil(), "the node affinity should not be set")
+				g.Expect(pod.Labels).NotTo(HaveKey(utils.InspectionFallbackLabel))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(pod.Labels).To(HaveKeyWithValue(utils.InspectionFallbackLabel, string(tt.wantFallback)))
+				g.Expect(pod.Spec.Affinity).To(Equal(NewPod().WithNodeSelectorTermsMatchExpressions(
+					[]v1.NodeSelectorRequirement{
 						{
-							Weight: 1,
-							Preference: v1.NodeSelectorTerm{
-								MatchExpressions: []v1.NodeSelectorRequirement{
-									{
-										Key:      "foo",
-										Operator: v1.NodeSelectorOpIn,
-										Values:   []string{"bar"},
-									},
-								},
-							},
+							Key:      utils.ArchLabel,
+							Operator: v1.NodeSelectorOpIn,
+							Values:   tt.wantArchitectures,
 						},
-					},
-				},
-			}).WithNodeSelectorTermsMatchExpressions(
-				[]v1.NodeSelectorRequirement{
-					{
-						Key:      utils.ArchLabel,
-						Operator: v1.NodeSelectorOpIn,
-						Values:   []string{utils.ArchitectureAmd64, utils.ArchitectureArm64},
-					},
-				}).Build(),
-		},
+					}).Build().Spec.Affinity))
+			}
+			g.Expect(fallbacks(tt.wantFallback)).To(Equal(previousFallbacks + 1))
+			g.Expect(recorder.Events).To(Receive(And(HavePrefix(v1.EventTypeWarning+" "+ImageInspectionTimeout),
+				ContainSubstring(tt.wantEventMessagePrefix))))
+		})
+	}
+}
+
+func TestPod_setArchRequirementOrFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		fallback      v1beta1.InspectionTimeoutFallback
+		wantErr       bool
+		wantErrorMark bool
+	}{
 		{
-			name:      "should not modify the pod if unable to inspect the images",
-			pod:       NewPod().WithContainersImages(fake.MultiArchImage, "non-readable-image").Build(),
-			want:      NewPod().WithContainersImages(fake.MultiArchImage, "non-readable-image").Build(),
-			expectErr: true,
+			name:          "block",
+			fallback:      v1beta1.InspectionTimeoutFallbackBlock,
+			wantErr:       true,
+			wantErrorMark: true,
 		},
 		{
-			name: "should prevent the pod from being scheduled when no common architecture is found",
-			pod:  NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).Build(),
-			want: NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.SingleArchArm64Image).WithNodeSelectorTermsMatchExpressions(
-				[]v1.NodeSelectorRequirement{
-					{
-						Key:      utils.NoSupportedArchLabel,
-						Operator: v1.NodeSelectorOpExists,
-					},
-				}).Build(),
+			name:     "allow-all",
+			fallback: v1beta1.InspectionTimeoutFallbackAllowAll,
 		},
 	}
 	metrics.InitPodPlacementControllerMetrics()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			imageInspectionCache = fake.FacadeSingleton()
+			g := NewGomegaWithT(t)
+			recorder := record.NewFakeRecorder(10)
 			pod := &Pod{
-				Pod: *tt.pod,
-				ctx: ctx,
+				Pod:            *NewPod().WithContainersImages(fake.MultiArchImage, fake.TimeoutImage).Build(),
+				ctx:            ctx,
+				recorder:       recorder,
+				imageInspector: fake.FacadeSingleton(),
 			}
-			_, err := pod.SetNodeAffinityArchRequirement(tt.pullSecretDataList)
-			g := NewGomegaWithT(t)
-			if tt.expectErr {
-				g.Expect(err).Should(HaveOccurred())
-			} else {
-				g.Expect(err).ShouldNot(HaveOccurred())
+			err := pod.setArchRequirementOrFallback(NewClusterPodPlacementConfig().
+				WithInspectionTimeoutFallback(tt.fallback).Build(), nil)
+			g.Expect(err != nil).To(Equal(tt.wantErr))
+			if tt.wantErrorMark {
+				g.Expect(pod.Labels).To(HaveKey(utils.ImageInspectionErrorLabel))
+				g.Expect(pod.Labels).To(HaveKeyWithValue(utils.ImageInspectionErrorCountLabel, "1"))
+				return
+			}
+			// The pods given the fallback are not reported as failed
+			g.Expect(pod.Labels).NotTo(HaveKey(utils.ImageInspectionErrorLabel))
+			g.Expect(pod.Labels).NotTo(HaveKey(utils.ImageInspectionErrorCountLabel))
+			g.Expect(pod.Annotations).NotTo(HaveKey(utils.ImageInspectionErrorLabel))
+			g.Expect(pod.Labels).To(HaveKeyWithValue(utils.InspectionFallbackLabel, string(tt.fallback)))
+			close(recorder.Events)
+			for event := range recorder.Events {
+				g.Expect(event).NotTo(ContainSubstring(ImageArchitectureInspectionError))
 			}
-			g.Expect(pod.Spec.Affinity).Should(Equal(tt.want.Spec.Affinity))
-			imageInspectionCache = mmoimage.FacadeSingleton()
 		})
 	}
 }
@@ -874,6 +2551,55 @@ func TestEnsureLabel(t *testing.T) {
 	}
 }
 
+func TestPod_RemoveAllOperatorManagedLabels(t *testing.T) {
+	tests := []struct {
+		name           string
+		initialLabels  []string
+		want           bool
+		expectedLabels map[string]string
+	}{
+		{
+			name:          "no labels",
+			initialLabels: nil,
+			want:          false,
+		},
+		{
+			name:           "no operator-managed labels",
+			initialLabels:  []string{"app", "test", "example.com/arch", "arm64"},
+			want:           false,
+			expectedLabels: map[string]string{"app": "test", "example.com/arch": "arm64"},
+		},
+		{
+			name: "operator-managed labels",
+			initialLabels: []string{
+				utils.NodeAffinityLabel, utils.NodeAffinityLabelValueSet,
+				utils.SchedulingGateLabel, utils.SchedulingGateLabelValueRemoved,
+				utils.PreferredNodeAffinityLabel, utils.LabelValueNotSet,
+				utils.MultiArchLabel, "",
+				utils.ArchLabelValue(utils.ArchitectureArm64), "",
+				utils.ArchLabel, utils.ArchitectureArm64,
+				"app", "test",
+			},
+			want:           true,
+			expectedLabels: map[string]string{utils.ArchLabel: utils.ArchitectureArm64, "app": "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{
+				Pod: *NewPod().WithLabels(tt.initialLabels...).Build(),
+			}
+			g.Expect(pod.RemoveAllOperatorManagedLabels()).To(Equal(tt.want))
+			if tt.expectedLabels == nil {
+				g.Expect(pod.Labels).To(BeEmpty())
+				return
+			}
+			g.Expect(pod.Labels).To(Equal(tt.expectedLabels))
+		})
+	}
+}
+
 // TestEnsureArchitectureLabels checks the ensureArchitectureLabels method to ensure it sets the correct labels based on NodeSelectorRequirement.
 func TestEnsureArchitectureLabels(t *testing.T) {
 	tests := []struct {
@@ -941,6 +2667,142 @@ func TestEnsureArchitectureLabels(t *testing.T) {
 	}
 }
 
+func TestEnsureArchitectureVariantLabels(t *testing.T) {
+	pod := &Pod{
+		Pod: *NewPod().Build(),
+	}
+	pod.ensureArchitectureVariantLabels([]string{utils.ArchitectureAmd64, utils.ArchitectureArmV6,
+		utils.ArchitectureArmV7})
+	g := NewGomegaWithT(t)
+	g.Expect(pod.Labels).To(Equal(map[string]string{
+		"multiarch.openshift.io/arm-v6": "",
+		"multiarch.openshift.io/arm-v7": "",
+	}))
+}
+
+func TestPod_ensureArchitectureAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		images     []string
+		wantImages []string
+	}{
+		{
+			name:       "multiple images",
+			images:     []string{fake.MultiArchImage, fake.SingleArchAmd64Image},
+			wantImages: []string{fake.MultiArchImage, fake.SingleArchAmd64Image},
+		},
+		{
+			name:       "no images",
+			images:     nil,
+			wantImages: []string{},
+		},
+	}
+	inspectionTime := time.Date(2024, time.March, 1, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *NewPod().Build()}
+			pod.ensureArchitectureAnnotation(tt.images, inspectionTime)
+			var images []string
+			g.Expect(json.Unmarshal([]byte(pod.Annotations[utils.InspectedImagesAnnotation]), &images)).To(Succeed(),
+				"the inspected images annotation is not a JSON array")
+			g.Expect(images).To(Equal(tt.wantImages))
+			g.Expect(pod.Annotations).To(HaveKeyWithValue(utils.InspectedAtAnnotation, "2024-03-01T09:30:00Z"))
+			inspectedAt, err := time.Parse(time.RFC3339, pod.Annotations[utils.InspectedAtAnnotation])
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(inspectedAt.Equal(inspectionTime)).To(BeTrue())
+		})
+	}
+}
+
+func TestPod_SetNodeAffinityArchRequirement_InspectionAnnotations(t *testing.T) {
+	metrics.InitPodPlacementControllerMetrics()
+	t.Run("inspected images", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		pod := &Pod{
+			Pod: *NewPod().WithContainersImages(fake.SingleArchAmd64Image, fake.MultiArchImage,
+				fake.SingleArchAmd64Image).WithContainer("foo/pull:never", v1.PullNever).Build(),
+			ctx:            ctx,
+			imageInspector: fake.FacadeSingleton(),
+		}
+		before := time.Now().Truncate(time.Second)
+		_, err := pod.SetNodeAffinityArchRequirement(nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(pod.Annotations).To(HaveKeyWithValue(utils.InspectedImagesAnnotation,
+			fmt.Sprintf("[%q,%q]", fake.MultiArchImage, fake.SingleArchAmd64Image)))
+		inspectedAt, err := time.Parse(time.RFC3339, pod.Annotations[utils.InspectedAtAnnotation])
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(inspectedAt).To(BeTemporally(">=", before))
+	})
+	t.Run("architecture override", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		pod := &Pod{
+			Pod: *NewPod().WithContainersImages(fake.MultiArchImage).
+				WithAnnotations(utils.ArchitectureOverrideAnnotation, utils.ArchitectureAmd64).Build(),
+			ctx:            ctx,
+			imageInspector: fake.FacadeSingleton(),
+		}
+		_, err := pod.SetNodeAffinityArchRequirement(nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(pod.Annotations).NotTo(HaveKey(utils.InspectedImagesAnnotation),
+			"the images are not inspected when the architectures are overridden")
+		g.Expect(pod.Annotations).NotTo(HaveKey(utils.InspectedAtAnnotation))
+	})
+}
+
+func TestIntersectArchitectures(t *testing.T) {
+	tests := []struct {
+		name string
+		a    sets.Set[string]
+		b    sets.Set[string]
+		want sets.Set[string]
+	}{
+		{
+			name: "architectures without variant",
+			a:    sets.New(utils.ArchitectureAmd64, utils.ArchitectureArm64),
+			b:    sets.New(utils.ArchitectureArm64, utils.ArchitectureS390x),
+			want: sets.New(utils.ArchitectureArm64),
+		},
+		{
+			name: "same variants",
+			a:    sets.New(utils.ArchitectureArmV6, utils.ArchitectureArmV7),
+			b:    sets.New(utils.ArchitectureArmV7),
+			want: sets.New(utils.ArchitectureArmV7),
+		},
+		{
+			name: "different variants",
+			a:    sets.New(utils.ArchitectureArmV6),
+			b:    sets.New(utils.ArchitectureArmV7),
+			want: sets.New[string](),
+		},
+		{
+			name: "architecture without variant and variant-qualified architecture",
+			a:    sets.New("arm", utils.ArchitectureAmd64),
+			b:    sets.New(utils.ArchitectureArmV7),
+			want: sets.New(utils.ArchitectureArmV7),
+		},
+		{
+			name: "wasm32 does not intersect with the other architectures",
+			a:    sets.New(utils.ArchitectureWasm32),
+			b:    sets.New(utils.ArchitectureAmd64, utils.ArchitectureArmV7),
+			want: sets.New[string](),
+		},
+		{
+			name: "wasm32 in both sets",
+			a:    sets.New(utils.ArchitectureWasm32, utils.ArchitectureAmd64),
+			b:    sets.New(utils.ArchitectureWasm32),
+			want: sets.New(utils.ArchitectureWasm32),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(intersectArchitectures(tt.a, tt.b)).To(Equal(tt.want))
+			g.Expect(intersectArchitectures(tt.b, tt.a)).To(Equal(tt.want))
+		})
+	}
+}
+
 func TestPod_EnsureSchedulingGate(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -981,21 +2843,108 @@ func TestPod_EnsureSchedulingGate(t *testing.T) {
 			},
 		},
 	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			pod := &Pod{
-				Pod: v1.Pod{
-					Spec: v1.PodSpec{
-						SchedulingGates: test.schedulingGates,
-					},
-				},
-			}
-
-			pod.ensureSchedulingGate()
-			if !reflect.DeepEqual(pod.Spec.SchedulingGates, test.expectedGates) {
-				t.Errorf("expected %v, got %v", test.expectedGates, pod.Spec.SchedulingGates)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &Pod{
+				Pod: v1.Pod{
+					Spec: v1.PodSpec{
+						SchedulingGates: test.schedulingGates,
+					},
+				},
+			}
+
+			pod.ensureSchedulingGate()
+			if !reflect.DeepEqual(pod.Spec.SchedulingGates, test.expectedGates) {
+				t.Errorf("expected %v, got %v", test.expectedGates, pod.Spec.SchedulingGates)
+			}
+		})
+	}
+}
+
+func TestPod_SchedulingGateNameOverride(t *testing.T) {
+	const customGateName = "canary.multiarch.openshift.io/scheduling-gate"
+	g := NewGomegaWithT(t)
+	utils.SetSchedulingGateName(customGateName)
+	defer utils.SetSchedulingGateName("")
+	pod := &Pod{
+		Pod: *NewPod().WithSchedulingGates(utils.SchedulingGateName).Build(),
+		ctx: ctx,
+	}
+	g.Expect(pod.HasSchedulingGate()).To(BeFalse(), "the default scheduling gate is not the configured one")
+
+	pod.ensureSchedulingGate()
+	g.Expect(pod.Spec.SchedulingGates).To(Equal([]v1.PodSchedulingGate{
+		{Name: utils.SchedulingGateName},
+		{Name: customGateName},
+	}))
+	g.Expect(pod.HasSchedulingGate()).To(BeTrue())
+
+	pod.RemoveSchedulingGate()
+	g.Expect(pod.Spec.SchedulingGates).To(Equal([]v1.PodSchedulingGate{
+		{Name: utils.SchedulingGateName},
+	}), "only the configured scheduling gate is removed")
+	g.Expect(pod.HasSchedulingGate()).To(BeFalse())
+}
+
+func TestPod_ManagedSchedulingGates(t *testing.T) {
+	const (
+		scanGate  = "example.com/security-scan"
+		quotaGate = "example.com/quota"
+	)
+	g := NewGomegaWithT(t)
+	utils.SetManagedSchedulingGates([]string{scanGate, quotaGate})
+	defer utils.SetManagedSchedulingGates(nil)
+	pod := &Pod{
+		Pod: *NewPod().WithSchedulingGates(quotaGate).Build(),
+		ctx: ctx,
+	}
+	pod.ensureSchedulingGate()
+	g.Expect(pod.Spec.SchedulingGates).To(Equal([]v1.PodSchedulingGate{
+		{Name: quotaGate},
+		{Name: utils.SchedulingGateName},
+		{Name: scanGate},
+	}), "all the configured scheduling gates are added once")
+
+	pod.RemoveSchedulingGate()
+	g.Expect(pod.Spec.SchedulingGates).To(Equal([]v1.PodSchedulingGate{
+		{Name: quotaGate},
+		{Name: scanGate},
+	}), "removing the architecture-aware scheduling gate does not affect the managed ones")
+}
+
+func TestPod_isSchedulingGateCompleted(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:       "gate listed alone",
+			annotation: "example.com/security-scan",
+			want:       true,
+		},
+		{
+			name:       "gate listed with others",
+			annotation: "example.com/quota, example.com/security-scan",
+			want:       true,
+		},
+		{
+			name:       "gate not listed",
+			annotation: "example.com/quota,example.com/security",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *NewPod().Build()}
+			if tt.annotation != "" {
+				pod.Annotations = map[string]string{utils.CompletedSchedulingGatesAnnotation: tt.annotation}
 			}
+			g.Expect(pod.isSchedulingGateCompleted("example.com/security-scan")).To(Equal(tt.want))
 		})
 	}
 }
@@ -1046,6 +2995,20 @@ func TestPod_hasControlPlaneNodeSelector(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "pod with node selector terms and infra node selector",
+			fields: fields{
+				Pod: NewPod().WithNodeSelectors("foo", "bar", utils.InfraNodeSelectorLabel, utils.InfraNodeSelectorValue).Build(),
+			},
+			want: true,
+		},
+		{
+			name: "pod with only the infra node selector",
+			fields: fields{
+				Pod: NewPod().WithNodeSelectors(utils.InfraNodeSelectorLabel, utils.InfraNodeSelectorValue).Build(),
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1107,6 +3070,41 @@ func TestPod_shouldIgnorePod(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "pod with the ignore annotation set to true",
+			fields: fields{
+				Pod: NewPod().WithAnnotations(utils.IgnoreAnnotation, "true").Build(),
+			},
+			want: true,
+		},
+		{
+			name: "pod with the ignore annotation set to false",
+			fields: fields{
+				Pod: NewPod().WithAnnotations(utils.IgnoreAnnotation, "false").Build(),
+			},
+			want: false,
+		},
+		{
+			name: "pod with an invalid value of the ignore annotation",
+			fields: fields{
+				Pod: NewPod().WithAnnotations(utils.IgnoreAnnotation, "yes").Build(),
+			},
+			want: false,
+		},
+		{
+			name: "pod with hostNetwork set and the IgnoreHostNetworkPods disabled",
+			fields: fields{
+				Pod: NewPod().WithHostNetwork(true).Build(),
+			},
+			want: false,
+		},
+		{
+			name: "pod with hostPID set and the IgnoreHostNetworkPods disabled",
+			fields: fields{
+				Pod: NewPod().WithHostPID(true).Build(),
+			},
+			want: false,
+		},
 		{
 			name: "pod with DaemonSet ownerReference and Controller is true",
 			fields: fields{
@@ -1127,6 +3125,13 @@ func TestPod_shouldIgnorePod(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "pod with the DaemonSet owner-kind annotation not trusted by the ClusterPodPlacementConfig",
+			fields: fields{
+				Pod: NewPod().WithAnnotations(utils.OwnerKindAnnotation, "DaemonSet").Build(),
+			},
+			want: false,
+		},
 		{
 			name: "pod with DaemonSet ownerReference but Controller is nil",
 			fields: fields{
@@ -1166,109 +3171,475 @@ func TestPod_shouldIgnorePod(t *testing.T) {
 	}
 }
 
-func TestPod_shouldIgnorePodWithPluginsEnabledInCPPC(t *testing.T) {
-	type fields struct {
-		Pod      *v1.Pod
-		ctx      context.Context
-		recorder record.EventRecorder
+func TestPod_shouldIgnorePodWithPluginsEnabledInCPPC(t *testing.T) {
+	type fields struct {
+		Pod      *v1.Pod
+		ctx      context.Context
+		recorder record.EventRecorder
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   bool
+	}{
+		{
+			name: "pod with nodeSelector/nodeAffinity and the preferredAffinity is set for the kubernetes.io/arch label",
+			fields: fields{
+				Pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image).WithPreferredDuringSchedulingIgnoredDuringExecution(
+					NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureAmd64).WithWeight(1).Build()).
+					WithNodeSelectorTermsMatchExpressions(
+						[]v1.NodeSelectorRequirement{
+							{
+								Key:      utils.ArchLabel,
+								Operator: v1.NodeSelectorOpExists,
+								Values:   []string{utils.ArchitectureAmd64},
+							},
+						},
+					).Build(),
+			},
+			want: true,
+		},
+		{
+			name: "pod with set nodeAffinity, the preferredAffinity is not set for the kubernetes.io/arch label",
+			fields: fields{
+				Pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image).
+					WithNodeSelectorTermsMatchExpressions(
+						[]v1.NodeSelectorRequirement{
+							{
+								Key:      utils.ArchLabel,
+								Operator: v1.NodeSelectorOpExists,
+								Values:   []string{utils.ArchitectureAmd64},
+							},
+						},
+					).Build(),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Pod:      *tt.fields.Pod,
+				ctx:      tt.fields.ctx,
+				recorder: tt.fields.recorder,
+			}
+			if got := pod.shouldIgnorePod(NewClusterPodPlacementConfig().
+				WithName(common.SingletonResourceObjectName).
+				WithNodeAffinityScoring(true).
+				WithNodeAffinityScoringTerm(utils.ArchitectureAmd64, 1).Build(),
+			); got != tt.want {
+				t.Errorf("shouldIgnorePod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPod_shouldIgnorePodWithPluginsDisabledInCPPC(t *testing.T) {
+	type fields struct {
+		Pod      *v1.Pod
+		ctx      context.Context
+		recorder record.EventRecorder
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   bool
+	}{
+		{
+			name: "pod with nodeSelector/nodeAffinity is set for the kubernetes.io/arch label and the NodeAffinityScoring plugin is disabled ",
+			fields: fields{
+				Pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
+					[]v1.NodeSelectorRequirement{
+						{
+							Key:      utils.ArchLabel,
+							Operator: v1.NodeSelectorOpExists,
+							Values:   []string{utils.ArchitectureAmd64},
+						},
+					},
+				).Build(),
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Pod:      *tt.fields.Pod,
+				ctx:      tt.fields.ctx,
+				recorder: tt.fields.recorder,
+			}
+			if got := pod.shouldIgnorePod(NewClusterPodPlacementConfig().
+				WithName(common.SingletonResourceObjectName).
+				WithNodeAffinityScoring(false).
+				WithNodeAffinityScoringTerm(utils.ArchitectureAmd64, 1).Build()); got != tt.want {
+				t.Errorf("shouldIgnorePod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPod_ignoreReason_VirtualNode(t *testing.T) {
+	matchers := &v1beta1.VirtualNodeMatchers{
+		Namespaces: []string{"virtual-*"},
+		NodePools:  []string{"virtual-kubelet"},
+	}
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		strategy v1beta1.VirtualNodeTolerationStrategy
+		matchers *v1beta1.VirtualNodeMatchers
+		want     string
+	}{
+		{
+			name:     "pod in a virtual node namespace",
+			pod:      NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("virtual-workloads").Build(),
+			strategy: v1beta1.VirtualNodeTolerationStrategySkip,
+			matchers: matchers,
+			want:     IgnoreReasonVirtualNode,
+		},
+		{
+			name: "pod targeting a virtual node pool",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("test-namespace").
+				WithNodeSelectors(v1beta1.DefaultVirtualNodePoolLabel, "virtual-kubelet").Build(),
+			strategy: v1beta1.VirtualNodeTolerationStrategySkip,
+			matchers: matchers,
+			want:     IgnoreReasonVirtualNode,
+		},
+		{
+			name: "pod targeting a virtual node pool with a custom node pool label",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("test-namespace").
+				WithNodeSelectors("node-pool", "virtual-kubelet").Build(),
+			strategy: v1beta1.VirtualNodeTolerationStrategySkip,
+			matchers: &v1beta1.VirtualNodeMatchers{
+				NodePools:     []string{"virtual-*"},
+				NodePoolLabel: "node-pool",
+			},
+			want: IgnoreReasonVirtualNode,
+		},
+		{
+			name: "pod targeting another node pool",
+			pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("test-namespace").
+				WithNodeSelectors(v1beta1.DefaultVirtualNodePoolLabel, "workers").Build(),
+			strategy: v1beta1.VirtualNodeTolerationStrategySkip,
+			matchers: matchers,
+		},
+		{
+			name:     "pod in a virtual node namespace with the none strategy",
+			pod:      NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("virtual-workloads").Build(),
+			strategy: v1beta1.VirtualNodeTolerationStrategyNone,
+			matchers: matchers,
+		},
+		{
+			name:     "skip strategy without matchers",
+			pod:      NewPod().WithContainersImages(fake.MultiArchImage).WithNamespace("virtual-workloads").Build(),
+			strategy: v1beta1.VirtualNodeTolerationStrategySkip,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *tt.pod, ctx: context.TODO()}
+			cppc := NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithVirtualNodeTolerationStrategy(tt.strategy).WithVirtualNodeMatchers(tt.matchers).Build()
+			g.Expect(pod.ignoreReason(cppc)).To(Equal(tt.want))
+			if tt.want == IgnoreReasonVirtualNode {
+				// The ignored pods pass through without the architecture affinity
+				g.Expect(pod.Spec.Affinity).To(BeNil())
+			}
+		})
+	}
+}
+
+func TestPod_shouldIgnorePod_IgnoreHostNetworkPods(t *testing.T) {
+	tests := []struct {
+		name                  string
+		pod                   *v1.Pod
+		ignoreHostNetworkPods bool
+		want                  string
+	}{
+		{
+			name:                  "pod with hostNetwork set, opt-in",
+			pod:                   NewPod().WithContainersImages(fake.MultiArchImage).WithHostNetwork(true).Build(),
+			ignoreHostNetworkPods: true,
+			want:                  IgnoreReasonHostNetwork,
+		},
+		{
+			name:                  "pod with hostPID set, opt-in",
+			pod:                   NewPod().WithContainersImages(fake.MultiArchImage).WithHostPID(true).Build(),
+			ignoreHostNetworkPods: true,
+			want:                  IgnoreReasonHostNetwork,
+		},
+		{
+			name:                  "pod with neither hostNetwork nor hostPID set, opt-in",
+			pod:                   NewPod().WithContainersImages(fake.MultiArchImage).Build(),
+			ignoreHostNetworkPods: true,
+		},
+		{
+			name: "pod with hostNetwork set, opt-out",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithHostNetwork(true).Build(),
+		},
+		{
+			name: "pod with hostPID set, opt-out",
+			pod:  NewPod().WithContainersImages(fake.MultiArchImage).WithHostPID(true).Build(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *tt.pod, ctx: context.TODO()}
+			cppc := NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithIgnoreHostNetworkPods(tt.ignoreHostNetworkPods).Build()
+			g.Expect(pod.ignoreReason(cppc)).To(Equal(tt.want))
+			g.Expect(pod.shouldIgnorePod(cppc)).To(Equal(tt.want != ""))
+		})
+	}
+}
+
+func TestPod_ignoreReason_TrustedOwnerKind(t *testing.T) {
+	tests := []struct {
+		name              string
+		pod               *v1.Pod
+		trustedOwnerKinds []string
+		want              string
+	}{
+		{
+			name: "pod with a trusted owner-kind annotation",
+			pod: NewPod().WithNamespace("test-namespace").
+				WithAnnotations(utils.OwnerKindAnnotation, "DaemonSet").Build(),
+			trustedOwnerKinds: []string{"DaemonSet"},
+			want:              IgnoreReasonTrustedOwnerKind,
+		},
+		{
+			name: "pod with an untrusted owner-kind annotation",
+			pod: NewPod().WithNamespace("test-namespace").
+				WithAnnotations(utils.OwnerKindAnnotation, "ReplicaSet").Build(),
+			trustedOwnerKinds: []string{"DaemonSet"},
+		},
+		{
+			name: "pod with an owner-kind annotation and no trusted owner kinds",
+			pod: NewPod().WithNamespace("test-namespace").
+				WithAnnotations(utils.OwnerKindAnnotation, "DaemonSet").Build(),
+		},
+		{
+			name:              "pod without owner-kind annotation",
+			pod:               NewPod().WithNamespace("test-namespace").Build(),
+			trustedOwnerKinds: []string{"DaemonSet"},
+		},
+		{
+			name: "pod owned by a DaemonSet",
+			pod: NewPod().WithNamespace("test-namespace").WithOwnerReferences(
+				NewOwnerReferenceBuilder().WithKind("DaemonSet").WithController(utils.NewPtr(true)).Build()).Build(),
+			want: IgnoreReasonDaemonSet,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *tt.pod, ctx: context.TODO()}
+			cppc := NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithTrustedOwnerKinds(tt.trustedOwnerKinds...).Build()
+			g.Expect(pod.ignoreReason(cppc)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPod_ignoreReason_ExcludedOwnerKind(t *testing.T) {
+	controlledBy := func(kind string, controller bool) *v1.Pod {
+		return NewPod().WithNamespace("test-namespace").WithOwnerReferences(
+			NewOwnerReferenceBuilder().WithKind(kind).WithController(utils.NewPtr(controller)).Build()).Build()
+	}
+	tests := []struct {
+		name               string
+		pod                *v1.Pod
+		excludedOwnerKinds []string
+		want               string
+	}{
+		{
+			name:               "pod controlled by an excluded owner kind",
+			pod:                controlledBy("VirtualMachine", true),
+			excludedOwnerKinds: []string{"DaemonSet", "VirtualMachine"},
+			want:               IgnoreReasonExcludedOwnerKind,
+		},
+		{
+			name:               "pod controlled by another excluded owner kind",
+			pod:                controlledBy("StatefulSet", true),
+			excludedOwnerKinds: []string{"VirtualMachine", "StatefulSet"},
+			want:               IgnoreReasonExcludedOwnerKind,
+		},
+		{
+			name:               "pod owned but not controlled by an excluded owner kind",
+			pod:                controlledBy("VirtualMachine", false),
+			excludedOwnerKinds: []string{"VirtualMachine"},
+		},
+		{
+			name:               "pod controlled by a kind that is not excluded",
+			pod:                controlledBy("ReplicaSet", true),
+			excludedOwnerKinds: []string{"VirtualMachine"},
+		},
+		{
+			name: "pod controlled by a custom kind and the default excluded owner kinds",
+			pod:  controlledBy("VirtualMachine", true),
+		},
+		{
+			name:               "pod controlled by a DaemonSet not in the excluded owner kinds",
+			pod:                controlledBy("DaemonSet", true),
+			excludedOwnerKinds: []string{"VirtualMachine"},
+			want:               IgnoreReasonDaemonSet,
+		},
+		{
+			name: "pod controlled by a DaemonSet and the default excluded owner kinds",
+			pod:  controlledBy("DaemonSet", true),
+			want: IgnoreReasonDaemonSet,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *tt.pod, ctx: context.TODO()}
+			cppc := NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithExcludedOwnerKinds(tt.excludedOwnerKinds...).Build()
+			g.Expect(pod.ignoreReason(cppc)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPod_ignoreReason_ExcludedOwnerReferenceKinds(t *testing.T) {
+	ownedBy := func(apiVersion, kind string, controller *bool) *v1.Pod {
+		return NewPod().WithNamespace("test-namespace").WithOwnerReferences(
+			NewOwnerReferenceBuilder().WithAPIVersion(apiVersion).WithKind(kind).WithController(controller).Build()).Build()
 	}
+	vmi := v1beta1.ExcludedOwnerRef{Kind: "VirtualMachineInstance", APIGroup: "kubevirt.io"}
+	controlledVMI := v1beta1.ExcludedOwnerRef{Kind: "VirtualMachineInstance", APIGroup: "kubevirt.io",
+		RequireControllerTrue: true}
 	tests := []struct {
-		name   string
-		fields fields
-		want   bool
+		name              string
+		pod               *v1.Pod
+		excludedOwnerRefs []v1beta1.ExcludedOwnerRef
+		want              string
 	}{
 		{
-			name: "pod with nodeSelector/nodeAffinity and the preferredAffinity is set for the kubernetes.io/arch label",
-			fields: fields{
-				Pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image).WithPreferredDuringSchedulingIgnoredDuringExecution(
-					NewPreferredSchedulingTerm().WithArchitecture(utils.ArchitectureAmd64).WithWeight(1).Build()).
-					WithNodeSelectorTermsMatchExpressions(
-						[]v1.NodeSelectorRequirement{
-							{
-								Key:      utils.ArchLabel,
-								Operator: v1.NodeSelectorOpExists,
-								Values:   []string{utils.ArchitectureAmd64},
-							},
-						},
-					).Build(),
-			},
-			want: true,
+			name:              "pod controlled by a custom owner kind",
+			pod:               ownedBy("kubevirt.io/v1", "VirtualMachineInstance", utils.NewPtr(true)),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{vmi},
+			want:              IgnoreReasonExcludedOwnerKind,
 		},
 		{
-			name: "pod with set nodeAffinity, the preferredAffinity is not set for the kubernetes.io/arch label",
-			fields: fields{
-				Pod: NewPod().WithContainersImages(fake.SingleArchAmd64Image).
-					WithNodeSelectorTermsMatchExpressions(
-						[]v1.NodeSelectorRequirement{
-							{
-								Key:      utils.ArchLabel,
-								Operator: v1.NodeSelectorOpExists,
-								Values:   []string{utils.ArchitectureAmd64},
-							},
-						},
-					).Build(),
-			},
-			want: false,
+			name:              "pod owned but not controlled by a custom owner kind not requiring the controller",
+			pod:               ownedBy("kubevirt.io/v1", "VirtualMachineInstance", utils.NewPtr(false)),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{vmi},
+			want:              IgnoreReasonExcludedOwnerKind,
+		},
+		{
+			name:              "pod with an owner reference without the controller field and a custom owner kind not requiring it",
+			pod:               ownedBy("kubevirt.io/v1", "VirtualMachineInstance", nil),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{vmi},
+			want:              IgnoreReasonExcludedOwnerKind,
+		},
+		{
+			name:              "pod controlled by a custom owner kind requiring the controller",
+			pod:               ownedBy("kubevirt.io/v1", "VirtualMachineInstance", utils.NewPtr(true)),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{controlledVMI},
+			want:              IgnoreReasonExcludedOwnerKind,
+		},
+		{
+			name:              "pod owned but not controlled by a custom owner kind requiring the controller",
+			pod:               ownedBy("kubevirt.io/v1", "VirtualMachineInstance", utils.NewPtr(false)),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{controlledVMI},
+		},
+		{
+			name:              "pod owned by the same kind of another API group",
+			pod:               ownedBy("example.com/v1", "VirtualMachineInstance", utils.NewPtr(true)),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{vmi},
+		},
+		{
+			name:              "static pod owned by a Node of the core API group",
+			pod:               ownedBy("v1", "Node", utils.NewPtr(true)),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{{Kind: "Node"}},
+			want:              IgnoreReasonExcludedOwnerKind,
+		},
+		{
+			name: "pod owned by a DaemonSet-like kind of a custom API group",
+			pod: NewPod().WithNamespace("test-namespace").WithOwnerReferences(NewOwnerReferenceBuilder().
+				WithGroupVersion("custom.io", "v1alpha1").WithKind("DaemonSet").WithName("custom").
+				WithController(utils.NewPtr(false)).Build()).Build(),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{{Kind: "DaemonSet", APIGroup: "custom.io"}},
+			want:              IgnoreReasonExcludedOwnerKind,
+		},
+		{
+			name: "pod owned by a DaemonSet of the apps API group and a DaemonSet-like kind of a custom API group",
+			pod: NewPod().WithNamespace("test-namespace").WithOwnerReferences(NewOwnerReferenceBuilder().
+				WithGroupVersion("apps", "v1").WithKind("DaemonSet").WithName("apps").
+				WithController(utils.NewPtr(false)).Build()).Build(),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{{Kind: "DaemonSet", APIGroup: "custom.io"}},
+		},
+		{
+			name: "pod owned by a kind that is not excluded",
+			pod:  ownedBy("apps/v1", "ReplicaSet", utils.NewPtr(true)),
+			excludedOwnerRefs: []v1beta1.ExcludedOwnerRef{vmi, {Kind: "Node"},
+				{Kind: "ReplicaSet", APIGroup: "example.com"}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pod := &Pod{
-				Pod:      *tt.fields.Pod,
-				ctx:      tt.fields.ctx,
-				recorder: tt.fields.recorder,
-			}
-			if got := pod.shouldIgnorePod(NewClusterPodPlacementConfig().
-				WithName(common.SingletonResourceObjectName).
-				WithNodeAffinityScoring(true).
-				WithNodeAffinityScoringTerm(utils.ArchitectureAmd64, 1).Build(),
-			); got != tt.want {
-				t.Errorf("shouldIgnorePod() = %v, want %v", got, tt.want)
-			}
+			g := NewGomegaWithT(t)
+			pod := &Pod{Pod: *tt.pod, ctx: context.TODO()}
+			cppc := NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).
+				WithExcludedOwnerReferenceKinds(tt.excludedOwnerRefs...).Build()
+			g.Expect(pod.ignoreReason(cppc)).To(Equal(tt.want))
 		})
 	}
 }
 
-func TestPod_shouldIgnorePodWithPluginsDisabledInCPPC(t *testing.T) {
-	type fields struct {
-		Pod      *v1.Pod
-		ctx      context.Context
-		recorder record.EventRecorder
-	}
+func TestPod_isExcludedByLabelSelector(t *testing.T) {
 	tests := []struct {
-		name   string
-		fields fields
-		want   bool
+		name     string
+		pod      *v1.Pod
+		selector *metav1.LabelSelector
+		want     bool
+		wantErr  bool
 	}{
 		{
-			name: "pod with nodeSelector/nodeAffinity is set for the kubernetes.io/arch label and the NodeAffinityScoring plugin is disabled ",
-			fields: fields{
-				Pod: NewPod().WithContainersImages(fake.MultiArchImage).WithNodeSelectorTermsMatchExpressions(
-					[]v1.NodeSelectorRequirement{
-						{
-							Key:      utils.ArchLabel,
-							Operator: v1.NodeSelectorOpExists,
-							Values:   []string{utils.ArchitectureAmd64},
-						},
-					},
-				).Build(),
+			name: "nil selector",
+			pod:  NewPod().WithLabels("app", "infra").Build(),
+			want: false,
+		},
+		{
+			name: "matching selector",
+			pod:  NewPod().WithLabels("app", "infra").Build(),
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"infra", "monitoring"}},
+				},
 			},
 			want: true,
 		},
+		{
+			name:     "non-matching selector",
+			pod:      NewPod().WithLabels("app", "web").Build(),
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "infra"}},
+			want:     false,
+		},
+		{
+			name: "invalid selector",
+			pod:  NewPod().WithLabels("app", "infra").Build(),
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
 			pod := &Pod{
-				Pod:      *tt.fields.Pod,
-				ctx:      tt.fields.ctx,
-				recorder: tt.fields.recorder,
-			}
-			if got := pod.shouldIgnorePod(NewClusterPodPlacementConfig().
-				WithName(common.SingletonResourceObjectName).
-				WithNodeAffinityScoring(false).
-				WithNodeAffinityScoringTerm(utils.ArchitectureAmd64, 1).Build()); got != tt.want {
-				t.Errorf("shouldIgnorePod() = %v, want %v", got, tt.want)
+				Pod: *tt.pod,
+				ctx: ctx,
 			}
+			cppc := NewClusterPodPlacementConfig().WithName(common.SingletonResourceObjectName).Build()
+			cppc.Spec.PodExclusionLabelSelector = tt.selector
+			got, err := pod.isExcludedByLabelSelector(cppc)
+			g.Expect(err != nil).To(Equal(tt.wantErr))
+			g.Expect(got).To(Equal(tt.want))
 		})
 	}
 }
@@ -1448,6 +3819,80 @@ func TestIsNodeSelectorConfiguredForArchitecture(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name:         "No NodeSelector, NodeSelectorTerm with MatchFields only",
+			nodeSelector: nil,
+			affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{
+								MatchFields: []v1.NodeSelectorRequirement{
+									{Key: metav1.ObjectNameField, Operator: v1.NodeSelectorOpIn, Values: []string{"worker-0"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name:         "No NodeSelector, NodeSelectorTerm with Arch Label and MatchFields",
+			nodeSelector: nil,
+			affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureAmd64}},
+								},
+								MatchFields: []v1.NodeSelectorRequirement{
+									{Key: metav1.ObjectNameField, Operator: v1.NodeSelectorOpIn, Values: []string{"worker-0"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			name:         "No NodeSelector, One NodeSelectorTerm has Arch Label, Another has MatchFields only",
+			nodeSelector: nil,
+			affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureAmd64}},
+								},
+							},
+							{
+								MatchFields: []v1.NodeSelectorRequirement{
+									{Key: metav1.ObjectNameField, Operator: v1.NodeSelectorOpIn, Values: []string{"worker-0"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name:         "No NodeSelector, NodeAffinity with nil NodeSelectorTerms",
+			nodeSelector: nil,
+			affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: nil,
+					},
+				},
+			},
+			expected: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -1468,3 +3913,148 @@ func TestIsNodeSelectorConfiguredForArchitecture(t *testing.T) {
 		})
 	}
 }
+
+func TestHasNodeNameMatchField(t *testing.T) {
+	tests := []struct {
+		name string
+		term v1.NodeSelectorTerm
+		want bool
+	}{
+		{
+			name: "empty term",
+			term: v1.NodeSelectorTerm{},
+			want: false,
+		},
+		{
+			name: "term with matchExpressions only",
+			term: v1.NodeSelectorTerm{
+				MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureAmd64}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "term with a metadata.name matchFields entry",
+			term: v1.NodeSelectorTerm{
+				MatchFields: []v1.NodeSelectorRequirement{
+					{Key: metav1.ObjectNameField, Operator: v1.NodeSelectorOpIn, Values: []string{"worker-0"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "term with matchExpressions and a metadata.name matchFields entry",
+			term: v1.NodeSelectorTerm{
+				MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: utils.ArchLabel, Operator: v1.NodeSelectorOpIn, Values: []string{utils.ArchitectureAmd64}},
+				},
+				MatchFields: []v1.NodeSelectorRequirement{
+					{Key: metav1.ObjectNameField, Operator: v1.NodeSelectorOpIn, Values: []string{"worker-0"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "term with a matchFields entry on another field",
+			term: v1.NodeSelectorTerm{
+				MatchFields: []v1.NodeSelectorRequirement{
+					{Key: "metadata.namespace", Operator: v1.NodeSelectorOpIn, Values: []string{"default"}},
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(hasNodeNameMatchField(tt.term)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPod_architectureConstraintsConflicts(t *testing.T) {
+	arch := func(operator v1.NodeSelectorOperator, values ...string) v1.NodeSelectorRequirement {
+		return *NewNodeSelectorRequirement().WithKeyAndValues(utils.ArchLabel, operator, values...).Build()
+	}
+	zone := *NewNodeSelectorRequirement().WithKeyAndValues("topology.kubernetes.io/zone", v1.NodeSelectorOpIn,
+		"zone-a").Build()
+	const (
+		nodeSelectorConflict = "the nodeSelector kubernetes.io/arch=s390x conflicts with the architectures supported " +
+			"by the images of the pod (amd64, arm64): the pod will not be schedulable"
+		affinityConflict = "the required node affinity on the kubernetes.io/arch label conflicts with the " +
+			"architectures supported by the images of the pod (amd64, arm64): the pod will not be schedulable"
+	)
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want []string
+	}{
+		{
+			name: "no architecture constraints",
+			pod:  NewPod().Build(),
+		},
+		{
+			name: "nodeSelector matching a supported architecture",
+			pod:  NewPod().WithNodeSelectors(utils.ArchLabel, utils.ArchitectureArm64).Build(),
+		},
+		{
+			name: "nodeSelector conflict",
+			pod:  NewPod().WithNodeSelectors(utils.ArchLabel, utils.ArchitectureS390x).Build(),
+			want: []string{nodeSelectorConflict},
+		},
+		{
+			name: "affinity conflict with the In operator",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{arch(v1.NodeSelectorOpIn, utils.ArchitectureS390x), zone}).Build(),
+			want: []string{affinityConflict},
+		},
+		{
+			name: "affinity conflict with the NotIn operator",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions([]v1.NodeSelectorRequirement{
+				arch(v1.NodeSelectorOpNotIn, utils.ArchitectureAmd64, utils.ArchitectureArm64)}).Build(),
+			want: []string{affinityConflict},
+		},
+		{
+			name: "affinity conflict with the DoesNotExist operator",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions([]v1.NodeSelectorRequirement{
+				arch(v1.NodeSelectorOpDoesNotExist)}).Build(),
+			want: []string{affinityConflict},
+		},
+		{
+			name: "affinity with a term allowing a supported architecture",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{arch(v1.NodeSelectorOpIn, utils.ArchitectureS390x)},
+				[]v1.NodeSelectorRequirement{arch(v1.NodeSelectorOpIn, utils.ArchitectureArm64), zone}).Build(),
+		},
+		{
+			name: "affinity with the Exists operator",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions([]v1.NodeSelectorRequirement{
+				arch(v1.NodeSelectorOpExists)}).Build(),
+		},
+		{
+			name: "affinity with a term not constraining the architecture",
+			pod: NewPod().WithNodeSelectorTermsMatchExpressions(
+				[]v1.NodeSelectorRequirement{arch(v1.NodeSelectorOpIn, utils.ArchitectureS390x)},
+				[]v1.NodeSelectorRequirement{zone}).Build(),
+		},
+		{
+			name: "nodeSelector and affinity conflicts",
+			pod: NewPod().WithNodeSelectors(utils.ArchLabel, utils.ArchitectureS390x).
+				WithNodeSelectorTermsMatchExpressions([]v1.NodeSelectorRequirement{
+					arch(v1.NodeSelectorOpIn, utils.ArchitecturePpc64le)}).Build(),
+			want: []string{nodeSelectorConflict, affinityConflict},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &Pod{
+				Pod: *tt.pod,
+				ctx: ctx,
+			}
+			g.Expect(pod.architectureConstraintsConflicts(
+				[]string{utils.ArchitectureAmd64, utils.ArchitectureArm64})).To(Equal(tt.want))
+		})
+	}
+}
@@ -18,10 +18,12 @@ package podplacement
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	runtime2 "runtime"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,7 +34,9 @@ import (
 	ctrl2 "sigs.k8s.io/controller-runtime/pkg/controller"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
 	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
 	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
 	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 )
@@ -43,6 +47,30 @@ type PodReconciler struct {
 	Scheme    *runtime.Scheme
 	ClientSet *kubernetes.Clientset
 	Recorder  record.EventRecorder
+	// ImageInspector inspects the images of the pods.
+	ImageInspector image.ICache
+	// ArchInventory keeps the architectures of the nodes of the cluster. The architectures of the pods are not trimmed
+	// to the ones of the nodes if it is nil.
+	ArchInventory *ClusterArchInventory
+	// workerPoolSize is the maximum number of pods the controller processes concurrently.
+	workerPoolSize int
+	// maxConcurrentInspections is the maximum number of distinct images of a pod inspected concurrently.
+	maxConcurrentInspections int
+}
+
+func NewPodReconciler(client client.Client, scheme *runtime.Scheme, clientSet *kubernetes.Clientset,
+	recorder record.EventRecorder, imageInspector image.ICache, archInventory *ClusterArchInventory,
+	workerPoolSize, maxConcurrentInspections int) *PodReconciler {
+	return &PodReconciler{
+		Client:                   client,
+		Scheme:                   scheme,
+		ClientSet:                clientSet,
+		Recorder:                 recorder,
+		ImageInspector:           imageInspector,
+		ArchInventory:            archInventory,
+		workerPoolSize:           workerPoolSize,
+		maxConcurrentInspections: maxConcurrentInspections,
+	}
 }
 
 // RBACs for the operands' controllers are added manually because kubebuilder can't handle multiple service accounts
@@ -52,6 +80,7 @@ type PodReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=use
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -68,11 +97,17 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	metrics.InitPodPlacementControllerMetrics()
 	now := time.Now()
 	defer utils.HistogramObserve(now, metrics.TimeToProcessPod)
+	ctx, span := tracer.Start(ctx, "PodReconciler.Reconcile", trace.WithAttributes(
+		attribute.String("namespace", req.Namespace), attribute.String("name", req.Name)))
+	defer span.End()
 	log := ctrllog.FromContext(ctx)
 
 	pod := &Pod{
-		ctx:      ctx,
-		recorder: r.Recorder,
+		ctx:                      ctx,
+		recorder:                 r.Recorder,
+		imageInspector:           r.ImageInspector,
+		searchRegistries:         image.UnqualifiedSearchRegistries(ctx),
+		maxConcurrentInspections: r.maxConcurrentInspections,
 	}
 
 	if err := r.Get(ctx, req.NamespacedName, &pod.Pod); err != nil {
@@ -90,13 +125,17 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	err := r.Update(ctx, &pod.Pod)
 	if err != nil {
 		log.Error(err, "Unable to update the pod")
-		pod.publishEvent(corev1.EventTypeWarning, ArchitectureAwareSchedulingGateRemovalFailure, SchedulingGateRemovalFailureMsg)
+		metrics.ObserveGateRemovalDuration(pod.Namespace, time.Since(pod.CreationTimestamp.Time), err)
+		pod.publishEvent(corev1.EventTypeWarning, ArchitectureAwareSchedulingGateRemovalFailure,
+			fmt.Sprintf(SchedulingGateRemovalFailureMsg, utils.GetSchedulingGateName()))
 		return ctrl.Result{}, err
 	}
 	if !pod.HasSchedulingGate() {
 		// Only publish the event if the scheduling gate has been removed and the pod has been updated successfully.
-		pod.publishEvent(corev1.EventTypeNormal, ArchitectureAwareSchedulingGateRemovalSuccess, SchedulingGateRemovalSuccessMsg)
+		pod.publishEvent(corev1.EventTypeNormal, ArchitectureAwareSchedulingGateRemovalSuccess,
+			fmt.Sprintf(SchedulingGateRemovalSuccessMsg, utils.GetSchedulingGateName()))
 		metrics.GatedPodsGauge.Dec()
+		metrics.ObserveGateRemovalDuration(pod.Namespace, time.Since(pod.CreationTimestamp.Time), nil)
 	}
 	return ctrl.Result{}, nil
 }
@@ -106,10 +145,15 @@ func (r *PodReconciler) processPod(ctx context.Context, pod *Pod) {
 	log.V(1).Info("Processing pod")
 
 	cppc := clusterpodplacementconfig.GetClusterPodPlacementConfig()
-	if pod.shouldIgnorePod(cppc) {
+	pod.universalImages = universalImagesOf(cppc)
+	pod.disableArchitectureLabels = architectureLabelsDisabled(cppc)
+	pod.clusterArchitectures = clusterArchitecturesOf(cppc, r.ArchInventory)
+	pod.requireArchLabelOnNodes = archLabelRequiredOnNodes(cppc)
+	if pod.shouldIgnorePod(cppc) || gateInjectionDisabled(cppc) {
 		log.V(3).Info("A pod with the scheduling gate should be ignored. Ignoring...")
 		// We can reach this branch when:
-		// - The pod has been gated but not processed before the operator changed configuration such that the pod should be ignored.
+		// - The pod has been gated but not processed before the operator changed configuration such that the pod should be ignored,
+		//   or the gate injection was disabled.
 		// - The pod has got some other changes in the admission chain from another webhook that makes it not suitable for processing anymore
 		//	(for example another actor set the nodeAffinity already for the kubernetes.io/arch label).
 		// In both cases, we should just remove the scheduling gate.
@@ -128,8 +172,13 @@ func (r *PodReconciler) processPod(ctx context.Context, pod *Pod) {
 	pod.handleError(err, "Unable to retrieve the image pull secret data for the pod.")
 	// If no error occurred when retrieving the image pull secret data, set the node affinity.
 	if err == nil {
-		_, err = pod.SetNodeAffinityArchRequirement(psdl)
-		pod.handleError(err, "Unable to set the node affinity for the pod.")
+		err = pod.setArchRequirementOrFallback(cppc, psdl)
+		if errors.Is(err, ErrArchMatchFieldConflict) {
+			// The conflict is not transient: the pod is ungated without the architecture requirement, as reported by
+			// the Warning event.
+			log.Info("Not setting the architecture requirement of the pod", "error", err)
+			err = nil
+		}
 	}
 	if pod.maxRetries() && err != nil {
 		// the number of retries is incremented in the handleError function when the error is not nil.
@@ -154,11 +203,17 @@ func (r *PodReconciler) processPod(ctx context.Context, pod *Pod) {
 
 // pullSecretDataList returns the list of secrets data for the given pod given its imagePullSecrets field
 func (r *PodReconciler) pullSecretDataList(ctx context.Context, pod *Pod) ([][]byte, error) {
+	return getPullSecretDataList(ctx, r.ClientSet, pod), nil
+}
+
+// getPullSecretDataList returns the list of secrets data for the given pod given its imagePullSecrets field.
+// The secrets that cannot be retrieved or parsed are logged and skipped.
+func getPullSecretDataList(ctx context.Context, clientSet kubernetes.Interface, pod *Pod) [][]byte {
 	log := ctrllog.FromContext(ctx)
 	secretAuths := make([][]byte, 0)
 	secretList := pod.GetPodImagePullSecrets()
 	for _, pullsecret := range secretList {
-		secret, err := r.ClientSet.CoreV1().Secrets(pod.Namespace).Get(ctx, pullsecret, metav1.GetOptions{})
+		secret, err := clientSet.CoreV1().Secrets(pod.Namespace).Get(ctx, pullsecret, metav1.GetOptions{})
 		if err != nil {
 			log.Error(err, "Error getting secret", "secret", pullsecret)
 			continue
@@ -170,18 +225,22 @@ func (r *PodReconciler) pullSecretDataList(ctx context.Context, pod *Pod) ([][]b
 			secretAuths = append(secretAuths, secretData)
 		}
 	}
-	return secretAuths, nil
+	return secretAuths
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// As the main bottleneck is the image inspection, which is strongly I/O bound, the number of concurrent
+	// reconciles is not tied to the number of CPUs and can be tuned via the ClusterPodPlacementConfig.
+	maxConcurrentReconciles := r.workerPoolSize
+	if maxConcurrentReconciles <= 0 {
+		maxConcurrentReconciles = int(v1beta1.DefaultGateRemovalWorkerPoolSize)
+	}
 	ctrllog.FromContext(context.Background()).Info("Setting up the PodReconciler with the manager with max"+
-		" concurrent reconciles", "maxConcurrentReconciles", runtime2.NumCPU()*2)
-	// As the main bottleneck is the image inspection, which is strongly I/O bound, we can increase the number of concurrent
-	// reconciles to the number of CPUs * 4.
+		" concurrent reconciles", "maxConcurrentReconciles", maxConcurrentReconciles)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Pod{}).WithOptions(ctrl2.Options{
-		MaxConcurrentReconciles: runtime2.NumCPU() * 4,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).
 		Complete(r)
 }
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+)
+
+const (
+	registriesProbeInterval = 30 * time.Second
+	registryProbeTimeout    = 5 * time.Second
+)
+
+// RegistriesReadinessProbe periodically probes the unqualified search registries with a HEAD request to their /v2/
+// endpoint. Its Check fails while none of them is reachable, so that the webhook is not ready while it cannot inspect
+// the images. The registries are probed at most once per interval: Check returns the result of the last probe, as
+// the probes of the registries can take longer than the ones of the kubelet.
+type RegistriesReadinessProbe struct {
+	// registries returns the registries to probe. None of them is probed, and the check succeeds, if it is empty.
+	registries func(ctx context.Context) []string
+	client     *http.Client
+	interval   time.Duration
+	// mutex protects the err field
+	mutex sync.RWMutex
+	// err is the error of the last probe, if none of the registries was reachable
+	err error
+	log logr.Logger
+}
+
+func NewRegistriesReadinessProbe() *RegistriesReadinessProbe {
+	return &RegistriesReadinessProbe{
+		registries: image.UnqualifiedSearchRegistries,
+		client: &http.Client{
+			Timeout:   registryProbeTimeout,
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		},
+		interval: registriesProbeInterval,
+		log:      logr.Discard(),
+	}
+}
+
+// NeedLeaderElection returns false: all the replicas of the webhook serve the requests.
+func (p *RegistriesReadinessProbe) NeedLeaderElection() bool {
+	return false
+}
+
+func (p *RegistriesReadinessProbe) Start(ctx context.Context) error {
+	p.log = log.FromContext(ctx, "handler", "RegistriesReadinessProbe")
+	p.log.Info("Starting the Registries Readiness Probe")
+	metrics.InitWebhookMetrics()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.probe(ctx)
+		select {
+		case <-ctx.Done():
+			p.log.Info("Stopping the Registries Readiness Probe")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check implements healthz.Checker: it returns an error if none of the registries was reachable in the last probe.
+func (p *RegistriesReadinessProbe) Check(_ *http.Request) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.err
+}
+
+// probe probes the registries until one of them is reachable, and stores the result.
+func (p *RegistriesReadinessProbe) probe(ctx context.Context) {
+	registries := p.registries(ctx)
+	var err error
+	if len(registries) > 0 {
+		err = fmt.Errorf("none of the unqualified search registries is reachable: %s", strings.Join(registries, ", "))
+	}
+	for _, registry := range registries {
+		if reachErr := p.reach(ctx, registry); reachErr != nil {
+			p.log.V(2).Info("The registry is not reachable", "registry", registry, "error", reachErr)
+			continue
+		}
+		err = nil
+		break
+	}
+	if err != nil {
+		p.log.Error(err, "The webhook is not ready")
+		metrics.RegistriesReady.Set(0)
+	} else {
+		metrics.RegistriesReady.Set(1)
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.err = err
+}
+
+// reach sends a HEAD request to the /v2/ endpoint of the registry. Any response, e.g., a 401 Unauthorized, means the
+// registry is reachable, as well as the certificates the probe cannot verify, e.g., signed by the additional trusted
+// CAs of the image inspections.
+func (p *RegistriesReadinessProbe) reach(ctx context.Context, registry string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+registry+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthorityErr) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
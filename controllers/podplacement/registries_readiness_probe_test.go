@@ -0,0 +1,86 @@
+package podplacement
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+)
+
+func TestRegistriesReadinessProbe(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitWebhookMetrics()
+	var heads atomic.Int32
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && r.URL.Path == "/v2/" {
+			heads.Add(1)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+	failingRegistry := httptest.NewTLSServer(http.NotFoundHandler())
+	failingRegistry.Close()
+
+	registries := []string{failingRegistry.Listener.Addr().String(), registry.Listener.Addr().String()}
+	p := &RegistriesReadinessProbe{
+		registries: func(context.Context) []string { return registries },
+		client:     registry.Client(),
+		log:        logr.Discard(),
+	}
+	ready := func() float64 {
+		m := &dto.Metric{}
+		g.Expect(metrics.RegistriesReady.Write(m)).To(Succeed())
+		return m.GetGauge().GetValue()
+	}
+	// The manager serves the ready checks with the healthz.Handler, which fails with a 500 status code
+	readyz := &healthz.Handler{Checks: map[string]healthz.Checker{"registries": p.Check}}
+	status := func() int {
+		rec := httptest.NewRecorder()
+		readyz.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	// A single reachable registry is enough, even if it requires authentication
+	p.probe(context.TODO())
+	g.Expect(heads.Load()).To(BeEquivalentTo(1))
+	g.Expect(status()).To(Equal(http.StatusOK))
+	g.Expect(ready()).To(Equal(1.0))
+
+	// All the registries are unreachable
+	registries = registries[:1]
+	p.probe(context.TODO())
+	g.Expect(status()).To(Equal(http.StatusInternalServerError))
+	g.Expect(ready()).To(Equal(0.0))
+
+	// The check returns the result of the last probe, without probing the registries
+	registries = []string{registry.Listener.Addr().String()}
+	g.Expect(status()).To(Equal(http.StatusInternalServerError))
+	g.Expect(heads.Load()).To(BeEquivalentTo(1))
+	p.probe(context.TODO())
+	g.Expect(status()).To(Equal(http.StatusOK))
+	g.Expect(ready()).To(Equal(1.0))
+
+	// No registries to probe
+	registries = nil
+	p.probe(context.TODO())
+	g.Expect(status()).To(Equal(http.StatusOK))
+}
+
+func TestRegistriesReadinessProbe_UntrustedCertificate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitWebhookMetrics()
+	registry := httptest.NewTLSServer(http.NotFoundHandler())
+	defer registry.Close()
+	p := NewRegistriesReadinessProbe()
+	// The probe does not trust the certificate of the registry, which is reachable nonetheless
+	g.Expect(p.reach(context.TODO(), registry.Listener.Addr().String())).To(Succeed())
+}
@@ -18,16 +18,24 @@ package podplacement
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"net/http"
 
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
@@ -38,6 +46,7 @@ import (
 
 	"github.com/panjf2000/ants/v2"
 
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
 	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
 	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
 	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
@@ -45,6 +54,31 @@ import (
 
 // [disabled:operator]kubebuilder:webhook:path=/add-pod-scheduling-gate,mutating=true,sideEffects=None,admissionReviewVersions=v1,failurePolicy=ignore,groups="",resources=pods,verbs=create,versions=v1,name=pod-placement-scheduling-gate.multiarch.openshift.io
 
+// ArchitectureDecisionAuditAnnotation is the key of the audit annotation that explains the decision taken by the
+// webhook for a pod. The API server prefixes the keys of the audit annotations with the name of the webhook, so
+// the audit events will include the pod-placement-scheduling-gate.multiarch.openshift.io/architecture-decision key.
+const ArchitectureDecisionAuditAnnotation = "architecture-decision"
+
+const (
+	ArchitectureDecisionGated   = "gated"
+	ArchitectureDecisionIgnored = "ignored"
+)
+
+// architectureDecision is the JSON summary set as the value of the ArchitectureDecisionAuditAnnotation.
+// The architectures supported by the images are not known at admission time: they are computed by the pod placement
+// controller after the pod is persisted with the scheduling gate.
+type architectureDecision struct {
+	Decision string   `json:"decision"`
+	Reason   string   `json:"reason,omitempty"`
+	Images   []string `json:"images"`
+}
+
+// maxWorkerPools is the maximum number of pools the workers of the MultiPool returned by NewWorkerPool are spread across.
+const maxWorkerPools = 16
+
+// DefaultWebhookDrainTimeout is the default time the webhook waits for the in-flight event jobs on shutdown.
+const DefaultWebhookDrainTimeout = 30 * time.Second
+
 // PodSchedulingGateMutatingWebHook annotates Pods
 type PodSchedulingGateMutatingWebHook struct {
 	client     client.Client
@@ -54,6 +88,10 @@ type PodSchedulingGateMutatingWebHook struct {
 	scheme     *runtime.Scheme
 	recorder   record.EventRecorder
 	workerPool *ants.MultiPool
+	// busyWorkers is the number of jobs submitted to the worker pool that are not completed yet.
+	busyWorkers atomic.Int32
+	// delayedEventBackoff is the backoff of the requests getting the gated pods to publish their events.
+	delayedEventBackoff wait.Backoff
 }
 
 func (a *PodSchedulingGateMutatingWebHook) patchedPodResponse(pod *corev1.Pod, req admission.Request) admission.Response {
@@ -64,13 +102,40 @@ func (a *PodSchedulingGateMutatingWebHook) patchedPodResponse(pod *corev1.Pod, r
 	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
 }
 
+// decisionResponse returns the patched pod response with the ArchitectureDecisionAuditAnnotation set.
+func (a *PodSchedulingGateMutatingWebHook) decisionResponse(pod *Pod, req admission.Request, decision, reason string) admission.Response {
+	resp := a.patchedPodResponse(&pod.Pod, req)
+	if warning := pod.invalidIgnoreAnnotationWarning(); warning != "" {
+		resp = resp.WithWarnings(warning)
+	}
+	images := sets.New[string]()
+	for _, container := range append(pod.Spec.Containers, pod.Spec.InitContainers...) {
+		images.Insert(container.Image)
+	}
+	marshaledDecision, err := json.Marshal(architectureDecision{
+		Decision: decision,
+		Reason:   reason,
+		Images:   sets.List(images),
+	})
+	if err != nil {
+		ctrllog.FromContext(pod.ctx).Error(err, "Failed to marshal the architecture decision audit annotation")
+		return resp
+	}
+	resp.AuditAnnotations = map[string]string{
+		ArchitectureDecisionAuditAnnotation: string(marshaledDecision),
+	}
+	return resp
+}
+
 func (a *PodSchedulingGateMutatingWebHook) Handle(ctx context.Context, req admission.Request) admission.Response {
 	responseTimeStart := time.Now()
 	defer utils.HistogramObserve(responseTimeStart, metrics.ResponseTime)
 	metrics.ProcessedPodsWH.Inc()
-	a.once.Do(func() {
-		a.decoder = admission.NewDecoder(a.scheme)
-	})
+	metrics.ProcessedPodsWHPerNamespace.WithLabelValues(metrics.NamespaceLabelValue(req.Namespace)).Inc()
+	ctx, span := tracer.Start(ctx, "PodSchedulingGateMutatingWebHook.Handle", trace.WithAttributes(
+		attribute.String("namespace", req.Namespace), attribute.String("name", req.Name)))
+	defer span.End()
+	a.initDecoder()
 	pod := &Pod{
 		ctx:      ctx,
 		recorder: nil, // do we want to publish events if the pod is ignored?
@@ -88,9 +153,28 @@ func (a *PodSchedulingGateMutatingWebHook) Handle(ctx context.Context, req admis
 	pod.ensureLabel(utils.NodeAffinityLabel, utils.LabelValueNotSet)
 	pod.ensureLabel(utils.SchedulingGateLabel, utils.LabelValueNotSet)
 
-	if pod.shouldIgnorePod(cppc) {
-		log.V(3).Info("Ignoring the pod")
-		return a.patchedPodResponse(&pod.Pod, req)
+	// The admission of the ignored pods is logged for a sample of them only, as they can be many, e.g., in the
+	// namespaces where the pods are created by the thousands. The errors are always logged.
+	ignoredPodLog := sampledLogger(log, logSamplingRate(cppc))
+	if gateInjectionDisabled(cppc) {
+		ignoredPodLog.V(3).Info("Ignoring the pod", "reason", IgnoreReasonGateInjectionDisabled)
+		metrics.IgnoredPods.WithLabelValues(IgnoreReasonGateInjectionDisabled).Inc()
+		return a.decisionResponse(pod, req, ArchitectureDecisionIgnored, IgnoreReasonGateInjectionDisabled)
+	}
+	if reason := pod.ignoreReason(cppc); reason != "" {
+		ignoredPodLog.V(3).Info("Ignoring the pod", "reason", reason)
+		metrics.IgnoredPods.WithLabelValues(reason).Inc()
+		return a.decisionResponse(pod, req, ArchitectureDecisionIgnored, reason)
+	}
+	excluded, err := pod.isExcludedByLabelSelector(cppc)
+	if err != nil {
+		// The selector is validated by the API server: an error here is not expected, and the pod is gated.
+		log.Error(err, "Unable to evaluate the pod exclusion label selector")
+	}
+	if excluded {
+		ignoredPodLog.V(3).Info("Ignoring the pod", "reason", IgnoreReasonExcludedByLabelSelector)
+		metrics.ExcludedPods.Inc()
+		return a.decisionResponse(pod, req, ArchitectureDecisionIgnored, IgnoreReasonExcludedByLabelSelector)
 	}
 
 	pod.ensureSchedulingGate()
@@ -103,33 +187,52 @@ func (a *PodSchedulingGateMutatingWebHook) Handle(ctx context.Context, req admis
 	// we know it will finish eventually by design, and we don't need to block the response as we
 	// are right in the admission pipeline, before the pod is persisted.
 	log.V(3).Info("Scheduling gate added to the pod, launching the event creation goroutine")
-	a.delayedSchedulingGatedEvent(ctx, pod.DeepCopy())
+	if err = a.delayedSchedulingGatedEvent(ctx, pod.DeepCopy()); err != nil {
+		log.Error(err, "Failed to submit the delayedSchedulingGatedEvent job")
+	}
 	metrics.GatedPods.Inc()
 	metrics.GatedPodsGauge.Inc()
+	metrics.GatedPodsPerNamespace.WithLabelValues(metrics.NamespaceLabelValue(req.Namespace)).Inc()
 	log.V(2).Info("Accepting pod")
-	return a.patchedPodResponse(&pod.Pod, req)
+	return a.decisionResponse(pod, req, ArchitectureDecisionGated, "")
 }
 
-func (a *PodSchedulingGateMutatingWebHook) delayedSchedulingGatedEvent(ctx context.Context, pod *corev1.Pod) {
-	err := a.workerPool.Submit(func() {
+// logSamplingRate returns the fraction of the ignored pods whose admission is logged.
+func logSamplingRate(cppc *v1beta1.ClusterPodPlacementConfig) float64 {
+	if cppc == nil {
+		return v1beta1.DefaultLogSamplingRate
+	}
+	return cppc.Spec.GetLogSamplingRate()
+}
+
+// sampledLogger returns the logger with the probability given by the rate, and a logger discarding all the logs
+// otherwise.
+func sampledLogger(log logr.Logger, rate float64) logr.Logger {
+	if rate >= 1 || rand.Float64() < rate {
+		return log
+	}
+	return logr.Discard()
+}
+
+// delayedSchedulingGatedEvent submits a job to the worker pool to publish the SchedulingGateAdded event once the pod
+// is persisted. It returns the error of the submission, if any, so that jobs are not silently dropped.
+func (a *PodSchedulingGateMutatingWebHook) delayedSchedulingGatedEvent(ctx context.Context, pod *corev1.Pod) error {
+	return a.submit(func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 		defer cancel()
 		log := ctrllog.FromContext(ctx).WithValues("namespace", pod.Namespace, "name", pod.Name,
 			"function", "delayedSchedulingGatedEvent")
-		// We try to get the pod from the API with exponential backoff until we find it or a timeout is reached
-		err := wait.ExponentialBackoff(wait.Backoff{
-			// The maximum time, excluding the time for the execution of the request,
-			// is the sum of a geometric series with factor != 1.
-			// maxTime = duration * (factor^steps - 1) / (factor - 1)
-			// maxTime = 2e-3s * (2^15 - 1) = 65.534s
-			Duration: 2 * time.Millisecond,
-			Factor:   2,
-			Steps:    15,
-		}, func() (bool, error) {
+		// We try to get the pod from the API with exponential backoff until we find it or a timeout is reached.
+		// With the default backoff, the maximum time, excluding the time for the execution of the requests,
+		// is the sum of a geometric series with factor != 1.
+		// maxTime = duration * (factor^steps - 1) / (factor - 1)
+		// maxTime = 2e-3s * (2^15 - 1) = 65.534s
+		err := wait.ExponentialBackoffWithContext(ctx, a.delayedEventBackoff, func(ctx context.Context) (bool, error) {
 			createdPod, err := a.clientSet.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
 			if err == nil {
 				log.V(2).Info("Pod was found", "namespace", pod.Namespace, "name", pod.Name)
-				a.recorder.Event(createdPod, corev1.EventTypeNormal, ArchitectureAwareSchedulingGateAdded, SchedulingGateAddedMsg)
+				a.recorder.Event(createdPod, corev1.EventTypeNormal, ArchitectureAwareSchedulingGateAdded,
+					fmt.Sprintf(SchedulingGateAddedMsg, utils.GetSchedulingGateName()))
 				// Pod was found, return true to stop retrying
 				return true, nil
 			}
@@ -147,10 +250,66 @@ func (a *PodSchedulingGateMutatingWebHook) delayedSchedulingGatedEvent(ctx conte
 				"error", err)
 		}
 	})
+}
+
+// submit submits the job to the worker pool, keeping track of the busy workers.
+func (a *PodSchedulingGateMutatingWebHook) submit(job func()) error {
+	a.busyWorkers.Add(1)
+	err := a.workerPool.Submit(func() {
+		defer a.busyWorkers.Add(-1)
+		job()
+	})
 	if err != nil {
-		ctrllog.FromContext(ctx).WithValues("namespace", pod.Namespace, "name", pod.Name,
-			"function", "delayedSchedulingGatedEvent").Error(err, "Failed to submit the delayedSchedulingGatedEvent job")
+		a.busyWorkers.Add(-1)
 	}
+	return err
+}
+
+// initDecoder initializes the admission decoder of the webhook, once.
+func (a *PodSchedulingGateMutatingWebHook) initDecoder() {
+	a.once.Do(func() {
+		if a.scheme != nil {
+			a.decoder = admission.NewDecoder(a.scheme)
+		}
+	})
+}
+
+// Check implements healthz.Checker: it returns an error if the webhook cannot admit the pods without delay, i.e., if
+// its admission decoder cannot be initialized, or if its worker pool is closed or has no free worker, as submitting
+// the event job of a gated pod would block the admission. The reachability of the registries is checked by the
+// RegistriesReadinessProbe.
+func (a *PodSchedulingGateMutatingWebHook) Check(_ *http.Request) error {
+	a.initDecoder()
+	if a.decoder == nil {
+		return errors.New("the admission decoder is not initialized")
+	}
+	if a.workerPool.IsClosed() {
+		return errors.New("the worker pool is closed")
+	}
+	if busy := int(a.busyWorkers.Load()); busy >= a.workerPool.Cap() {
+		return fmt.Errorf("none of the %d workers of the worker pool is free", a.workerPool.Cap())
+	}
+	return nil
+}
+
+// Shutdown drains the worker pool publishing the events of the gated pods: it waits for the in-flight jobs to
+// complete until the deadline of the context, or DefaultWebhookDrainTimeout if the context has no deadline.
+func (a *PodSchedulingGateMutatingWebHook) Shutdown(ctx context.Context) error {
+	timeout := DefaultWebhookDrainTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	return a.workerPool.ReleaseTimeout(timeout)
+}
+
+// NewWorkerPool returns a MultiPool with at least size workers, spread across at most maxWorkerPools pools
+// to reduce the lock contention when submitting jobs.
+func NewWorkerPool(size int, options ...ants.Option) (*ants.MultiPool, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("invalid worker pool size %d", size)
+	}
+	pools := min(size, maxWorkerPools)
+	return ants.NewMultiPool(pools, (size+pools-1)/pools, ants.LeastTasks, options...)
 }
 
 func NewPodSchedulingGateMutatingWebHook(client client.Client, clientSet *kubernetes.Clientset,
@@ -161,7 +320,19 @@ func NewPodSchedulingGateMutatingWebHook(client client.Client, clientSet *kubern
 		scheme:     scheme,
 		recorder:   recorder,
 		workerPool: workerPool,
+		delayedEventBackoff: wait.Backoff{
+			Duration: v1beta1.DefaultDelayedEventBackoffDuration,
+			Factor:   v1beta1.DefaultDelayedEventBackoffFactor,
+			Steps:    int(v1beta1.DefaultDelayedEventBackoffSteps),
+			Jitter:   v1beta1.DefaultDelayedEventBackoffJitter,
+		},
 	}
 	metrics.InitWebhookMetrics()
 	return a
 }
+
+// SetDelayedEventBackoff sets the backoff of the requests getting the gated pods to publish their events. It must be
+// called before the webhook serves any request.
+func (a *PodSchedulingGateMutatingWebHook) SetDelayedEventBackoff(backoff wait.Backoff) {
+	a.delayedEventBackoff = backoff
+}
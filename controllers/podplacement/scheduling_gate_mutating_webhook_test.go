@@ -1,15 +1,43 @@
 package podplacement
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
 
+	"github.com/go-logr/logr/funcr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	dto "github.com/prometheus/client_model/go"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common"
+	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1beta1"
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
 	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
 	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake/registry"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 )
 
 var _ = Describe("Controllers/PodPlacement/scheduling_gate_mutating_webhook", func() {
@@ -26,6 +54,514 @@ var _ = Describe("Controllers/PodPlacement/scheduling_gate_mutating_webhook", fu
 				err := k8sClient.Create(ctx, pod)
 				Expect(err).NotTo(HaveOccurred(), "failed to create the pod", err)
 			})
+			It("should not gate the pods while the gate injection is disabled", func() {
+				setGateInjectionDisabled := func(disabled bool) {
+					Eventually(func(g Gomega) {
+						cppc := &v1beta1.ClusterPodPlacementConfig{}
+						g.Expect(k8sClient.Get(ctx, crclient.ObjectKey{Name: common.SingletonResourceObjectName}, cppc)).To(Succeed())
+						cppc.Spec.DisableGateInjection = disabled
+						g.Expect(k8sClient.Update(ctx, cppc)).To(Succeed())
+					}).Should(Succeed(), "failed to update the ClusterPodPlacementConfig")
+					Eventually(func() bool {
+						return clusterpodplacementconfig.GetClusterPodPlacementConfig().Spec.DisableGateInjection
+					}).Should(Equal(disabled), "the cache did not reflect the ClusterPodPlacementConfig")
+				}
+				setGateInjectionDisabled(true)
+				DeferCleanup(setGateInjectionDisabled, false)
+
+				pod := builder.NewPod().
+					WithContainersImages(fmt.Sprintf("%s/%s/%s:latest", registryAddress,
+						registry.PublicRepo, registry.ComputeNameByMediaType(imgspecv1.MediaTypeImageIndex))).
+					WithGenerateName("test-pod-").
+					WithNamespace("test-namespace").
+					Build()
+				err := k8sClient.Create(ctx, pod)
+				Expect(err).NotTo(HaveOccurred(), "failed to create the pod", err)
+				Expect(k8sClient.Get(ctx, crclient.ObjectKeyFromObject(pod), pod)).To(Succeed())
+				Expect(pod.Spec.SchedulingGates).To(BeEmpty(), "the pod should not be gated")
+				Expect(pod.Labels).To(HaveKeyWithValue(utils.SchedulingGateLabel, utils.LabelValueNotSet))
+				Expect(pod.Labels).To(HaveKeyWithValue(utils.NodeAffinityLabel, utils.LabelValueNotSet))
+				Expect(pod.Spec.Affinity).To(BeNil(), "the pod should not have the architecture node affinity")
+			})
 		})
 	})
 })
+
+func TestPodSchedulingGateMutatingWebHook_delayedSchedulingGatedEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pool, err := NewWorkerPool(1)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		g.Expect(pool.ReleaseTimeout(30 * time.Second)).To(Succeed())
+	}()
+	// The jobs fail fast as no API server is listening at this address.
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: "http://127.0.0.1:1"})
+	g.Expect(err).NotTo(HaveOccurred())
+	a := NewPodSchedulingGateMutatingWebHook(nil, clientSet, nil, nil, pool)
+
+	const requests = 100
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Int32
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pod := builder.NewPod().WithGenerateName(fmt.Sprintf("test-pod-%d-", i)).
+				WithNamespace("test-namespace").Build()
+			if err := a.delayedSchedulingGatedEvent(context.TODO(), pod); err != nil {
+				failed.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	g.Expect(failed.Load()).To(BeZero(), "some delayedSchedulingGatedEvent jobs were dropped")
+}
+
+func TestPodSchedulingGateMutatingWebHook_delayedSchedulingGatedEvent_Backoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff wait.Backoff
+	}{
+		{
+			name:    "without jitter",
+			backoff: wait.Backoff{Duration: 20 * time.Millisecond, Factor: 2, Steps: 4},
+		},
+		{
+			name:    "with jitter",
+			backoff: wait.Backoff{Duration: 20 * time.Millisecond, Factor: 1.5, Steps: 3, Jitter: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			var (
+				mu       sync.Mutex
+				attempts []time.Time
+			)
+			// The fake API server never finds the pod, so that the webhook retries until the steps are exhausted.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/test-namespace/pods/test-pod" {
+					mu.Lock()
+					attempts = append(attempts, time.Now())
+					mu.Unlock()
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(&metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Reason:   metav1.StatusReasonNotFound,
+					Code:     http.StatusNotFound,
+				})
+			}))
+			defer server.Close()
+			clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			g.Expect(err).NotTo(HaveOccurred())
+			pool, err := NewWorkerPool(1)
+			g.Expect(err).NotTo(HaveOccurred())
+			a := NewPodSchedulingGateMutatingWebHook(nil, clientSet, nil, nil, pool)
+			a.SetDelayedEventBackoff(tt.backoff)
+
+			pod := builder.NewPod().WithNamespace("test-namespace").Build()
+			pod.Name = "test-pod"
+			g.Expect(a.delayedSchedulingGatedEvent(context.TODO(), pod)).To(Succeed())
+			// Releasing the pool waits for the job to complete
+			g.Expect(pool.ReleaseTimeout(30 * time.Second)).To(Succeed())
+
+			mu.Lock()
+			defer mu.Unlock()
+			g.Expect(attempts).To(HaveLen(tt.backoff.Steps), "the number of attempts should be the steps of the backoff")
+			interval := tt.backoff.Duration
+			for i := 1; i < len(attempts); i++ {
+				gap := attempts[i].Sub(attempts[i-1])
+				g.Expect(gap).To(BeNumerically(">=", interval), "attempt %d was not delayed by the backoff", i)
+				interval = time.Duration(float64(interval) * tt.backoff.Factor)
+			}
+		})
+	}
+}
+
+func TestPodSchedulingGateMutatingWebHook_Handle_AuditAnnotations(t *testing.T) {
+	tests := []struct {
+		name         string
+		pod          *builder.PodBuilder
+		wantDecision architectureDecision
+	}{
+		{
+			name: "pod with nodeName set is ignored",
+			pod: builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").
+				WithNamespace("test-namespace").WithNodeName("test-node-name"),
+			wantDecision: architectureDecision{
+				Decision: ArchitectureDecisionIgnored,
+				Reason:   IgnoreReasonNodeNameSet,
+				Images:   []string{"quay.io/foo/bar:latest"},
+			},
+		},
+		{
+			name: "pod in a kube- namespace is ignored",
+			pod:  builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").WithNamespace("kube-system"),
+			wantDecision: architectureDecision{
+				Decision: ArchitectureDecisionIgnored,
+				Reason:   IgnoreReasonKubeNamespace,
+				Images:   []string{"quay.io/foo/bar:latest"},
+			},
+		},
+		{
+			name: "pod is gated",
+			pod: builder.NewPod().WithContainersImages("quay.io/foo/bar:latest", "quay.io/foo/baz:latest").
+				WithInitContainersImages("quay.io/foo/init:latest").WithNamespace("test-namespace"),
+			wantDecision: architectureDecision{
+				Decision: ArchitectureDecisionGated,
+				Images:   []string{"quay.io/foo/bar:latest", "quay.io/foo/baz:latest", "quay.io/foo/init:latest"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pool, err := NewWorkerPool(1)
+			g.Expect(err).NotTo(HaveOccurred())
+			defer func() {
+				g.Expect(pool.ReleaseTimeout(30 * time.Second)).To(Succeed())
+			}()
+			// The event jobs fail fast as no API server is listening at this address.
+			clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: "http://127.0.0.1:1"})
+			g.Expect(err).NotTo(HaveOccurred())
+			a := NewPodSchedulingGateMutatingWebHook(nil, clientSet, scheme.Scheme, nil, pool)
+
+			raw, err := json.Marshal(tt.pod.Build())
+			g.Expect(err).NotTo(HaveOccurred())
+			resp := a.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			g.Expect(resp.Allowed).To(BeTrue())
+			g.Expect(resp.AuditAnnotations).To(HaveKey(ArchitectureDecisionAuditAnnotation))
+			var got architectureDecision
+			g.Expect(json.Unmarshal([]byte(resp.AuditAnnotations[ArchitectureDecisionAuditAnnotation]), &got)).To(Succeed())
+			g.Expect(got).To(Equal(tt.wantDecision))
+		})
+	}
+}
+
+func TestPodSchedulingGateMutatingWebHook_Handle_IgnoredPodsMetric(t *testing.T) {
+	tests := []struct {
+		name       string
+		pod        *builder.PodBuilder
+		wantReason string
+	}{
+		{
+			name:       "pod in the operator namespace",
+			pod:        builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").WithNamespace(utils.Namespace()),
+			wantReason: IgnoreReasonOperatorNamespace,
+		},
+		{
+			name:       "pod in a kube- namespace",
+			pod:        builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").WithNamespace("kube-system"),
+			wantReason: IgnoreReasonKubeNamespace,
+		},
+		{
+			name: "pod with nodeName set",
+			pod: builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").
+				WithNamespace("test-namespace").WithNodeName("test-node-name"),
+			wantReason: IgnoreReasonNodeNameSet,
+		},
+		{
+			name: "pod with a control plane node selector",
+			pod: builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").
+				WithNamespace("test-namespace").WithNodeSelectors(utils.ControlPlaneNodeSelectorLabel, ""),
+			wantReason: IgnoreReasonControlPlaneNodeSelector,
+		},
+		{
+			name: "pod owned by a DaemonSet",
+			pod: builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").WithNamespace("test-namespace").
+				WithOwnerReferences(&metav1.OwnerReference{
+					APIVersion: "apps/v1",
+					Kind:       "DaemonSet",
+					Name:       "test-daemonset",
+					Controller: utils.NewPtr(true),
+				}),
+			wantReason: IgnoreReasonDaemonSet,
+		},
+		{
+			name: "pod with the ignore annotation",
+			pod: builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").WithNamespace("test-namespace").
+				WithAnnotations(utils.IgnoreAnnotation, "true"),
+			wantReason: IgnoreReasonIgnoreAnnotation,
+		},
+	}
+	ignoredPods := func(reason string) float64 {
+		m := &dto.Metric{}
+		if err := metrics.IgnoredPods.WithLabelValues(reason).Write(m); err != nil {
+			t.Fatalf("failed to read the ignored pods metric: %v", err)
+		}
+		return m.GetCounter().GetValue()
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pool, err := NewWorkerPool(1)
+			g.Expect(err).NotTo(HaveOccurred())
+			defer func() {
+				g.Expect(pool.ReleaseTimeout(30 * time.Second)).To(Succeed())
+			}()
+			a := NewPodSchedulingGateMutatingWebHook(nil, nil, scheme.Scheme, nil, pool)
+			before := map[string]float64{}
+			for _, test := range tests {
+				before[test.wantReason] = ignoredPods(test.wantReason)
+			}
+
+			raw, err := json.Marshal(tt.pod.Build())
+			g.Expect(err).NotTo(HaveOccurred())
+			resp := a.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			g.Expect(resp.Allowed).To(BeTrue())
+			for reason, value := range before {
+				want := value
+				if reason == tt.wantReason {
+					want++
+				}
+				g.Expect(ignoredPods(reason)).To(Equal(want), "unexpected value for the reason %q", reason)
+			}
+		})
+	}
+}
+
+func TestPodSchedulingGateMutatingWebHook_Handle_IgnoreAnnotationWarning(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		wantWarnings []string
+	}{
+		{
+			name:  "ignore annotation set to true",
+			value: "true",
+		},
+		{
+			name:  "ignore annotation set to false",
+			value: "false",
+		},
+		{
+			name:  "invalid value of the ignore annotation",
+			value: "yes",
+			wantWarnings: []string{"invalid value \"yes\" of the " + utils.IgnoreAnnotation +
+				" annotation: it must be \"true\" or \"false\"; the pod is not ignored"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pool, err := NewWorkerPool(1)
+			g.Expect(err).NotTo(HaveOccurred())
+			defer func() {
+				g.Expect(pool.ReleaseTimeout(30 * time.Second)).To(Succeed())
+			}()
+			// The event jobs fail fast as no API server is listening at this address.
+			clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: "http://127.0.0.1:1"})
+			g.Expect(err).NotTo(HaveOccurred())
+			a := NewPodSchedulingGateMutatingWebHook(nil, clientSet, scheme.Scheme, nil, pool)
+
+			raw, err := json.Marshal(builder.NewPod().WithContainersImages("quay.io/foo/bar:latest").
+				WithNamespace("test-namespace").WithAnnotations(utils.IgnoreAnnotation, tt.value).Build())
+			g.Expect(err).NotTo(HaveOccurred())
+			resp := a.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			g.Expect(resp.Allowed).To(BeTrue())
+			g.Expect(resp.Warnings).To(Equal(tt.wantWarnings))
+		})
+	}
+}
+
+func TestSampledLogger(t *testing.T) {
+	const requests = 1000
+	tests := []struct {
+		name     string
+		rate     float64
+		wantLogs func(logs int) bool
+	}{
+		{
+			name:     "no ignored pod logged",
+			rate:     0,
+			wantLogs: func(logs int) bool { return logs == 0 },
+		},
+		{
+			name:     "all the ignored pods logged",
+			rate:     1,
+			wantLogs: func(logs int) bool { return logs == requests },
+		},
+		{
+			name:     "a sample of the ignored pods logged",
+			rate:     0.5,
+			wantLogs: func(logs int) bool { return logs > 0 && logs < requests },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			var logs int
+			log := funcr.New(func(_, _ string) {
+				logs++
+			}, funcr.Options{Verbosity: 3})
+			for i := 0; i < requests; i++ {
+				sampledLogger(log, tt.rate).V(3).Info("Ignoring the pod")
+			}
+			g.Expect(tt.wantLogs(logs)).To(BeTrue(), "unexpected number of logs: %d", logs)
+		})
+	}
+}
+
+func TestPodSchedulingGateMutatingWebHook_Shutdown(t *testing.T) {
+	tests := []struct {
+		name         string
+		jobDuration  time.Duration
+		drainTimeout time.Duration
+		wantErr      bool
+	}{
+		{
+			name:         "the in-flight jobs complete before the drain timeout",
+			jobDuration:  200 * time.Millisecond,
+			drainTimeout: 10 * time.Second,
+		},
+		{
+			name:         "the in-flight jobs do not complete before the drain timeout",
+			jobDuration:  5 * time.Second,
+			drainTimeout: 200 * time.Millisecond,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pool, err := NewWorkerPool(10)
+			g.Expect(err).NotTo(HaveOccurred())
+			a := NewPodSchedulingGateMutatingWebHook(nil, nil, scheme.Scheme, nil, pool)
+			var completed atomic.Int32
+			for i := 0; i < 10; i++ {
+				g.Expect(pool.Submit(func() {
+					time.Sleep(tt.jobDuration)
+					completed.Add(1)
+				})).To(Succeed())
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tt.drainTimeout)
+			defer cancel()
+			err = a.Shutdown(ctx)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(completed.Load()).To(BeEquivalentTo(10), "all the in-flight jobs should complete")
+		})
+	}
+}
+
+// newAdmissionRequest returns the admission request to create the pod.
+func newAdmissionRequest(b *testing.B, pod *builder.PodBuilder) admission.Request {
+	raw, err := json.Marshal(pod.Build())
+	if err != nil {
+		b.Fatal(err)
+	}
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: "test-namespace",
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func BenchmarkWebhookHandle_MultiArchImage(b *testing.B) {
+	pool, err := NewWorkerPool(int(v1beta1.DefaultWebhookWorkerPoolSize))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		_ = pool.ReleaseTimeout(30 * time.Second)
+	}()
+	// The event jobs fail fast as no API server is listening at this address.
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: "http://127.0.0.1:1"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	a := NewPodSchedulingGateMutatingWebHook(nil, clientSet, scheme.Scheme, nil, pool)
+	req := newAdmissionRequest(b, builder.NewPod().WithContainersImages(fake.MultiArchImage).
+		WithNamespace("test-namespace"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if resp := a.Handle(context.TODO(), req); !resp.Allowed {
+			b.Fatalf("the pod was not allowed: %v", resp.Result)
+		}
+	}
+}
+
+func TestPodSchedulingGateMutatingWebHook_Check(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitWebhookMetrics()
+	pool, err := NewWorkerPool(2)
+	g.Expect(err).NotTo(HaveOccurred())
+	a := NewPodSchedulingGateMutatingWebHook(nil, nil, scheme.Scheme, nil, pool)
+	registries := &RegistriesReadinessProbe{}
+	// The manager serves the ready checks with the healthz.Handler, which fails with a 500 status code
+	readyz := &healthz.Handler{Checks: map[string]healthz.Checker{
+		"webhook":    a.Check,
+		"registries": registries.Check,
+	}}
+	status := func() int {
+		rec := httptest.NewRecorder()
+		readyz.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+	g.Expect(a.Check(nil)).To(Succeed())
+	g.Expect(status()).To(Equal(http.StatusOK))
+
+	// The worker pool is exhausted
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	for i := 0; i < pool.Cap(); i++ {
+		started.Add(1)
+		g.Expect(a.submit(func() {
+			started.Done()
+			<-release
+		})).To(Succeed())
+	}
+	started.Wait()
+	g.Expect(a.Check(nil)).To(MatchError(fmt.Sprintf("none of the %d workers of the worker pool is free", pool.Cap())))
+	g.Expect(status()).To(Equal(http.StatusInternalServerError))
+	close(release)
+	g.Eventually(func() error { return a.Check(nil) }).Should(Succeed())
+	g.Expect(status()).To(Equal(http.StatusOK))
+
+	// The registries are unreachable
+	registries.err = errors.New("none of the unqualified search registries is reachable")
+	g.Expect(status()).To(Equal(http.StatusInternalServerError))
+	registries.err = nil
+	g.Expect(status()).To(Equal(http.StatusOK))
+
+	// The worker pool is closed
+	g.Expect(pool.ReleaseTimeout(time.Second)).To(Succeed())
+	g.Expect(a.Check(nil)).To(MatchError("the worker pool is closed"))
+	g.Expect(status()).To(Equal(http.StatusInternalServerError))
+}
+
+func TestPodSchedulingGateMutatingWebHook_Check_NoDecoder(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitWebhookMetrics()
+	pool, err := NewWorkerPool(1)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer pool.ReleaseTimeout(time.Second) //nolint:errcheck
+	a := NewPodSchedulingGateMutatingWebHook(nil, nil, nil, nil, pool)
+	g.Expect(a.Check(nil)).To(MatchError("the admission decoder is not initialized"))
+}
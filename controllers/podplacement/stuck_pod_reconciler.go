@@ -0,0 +1,116 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// stuckPodReconcileInterval is the period of the checks for the pods gated for too long.
+const stuckPodReconcileInterval = time.Minute
+
+// StuckPodReconciler periodically removes the scheduling gate from the pods that are gated for longer than
+// maxGateDuration, e.g., because the operator was not running or a registry is permanently down.
+// The pods can then proceed to the scheduler evaluation, without the architecture-aware node affinity.
+type StuckPodReconciler struct {
+	pods     corev1client.PodsGetter
+	recorder record.EventRecorder
+	clock    clock.PassiveClock
+	// maxGateDuration is the maximum time, since their creation, the pods can stay gated
+	maxGateDuration time.Duration
+	log             logr.Logger
+}
+
+func NewStuckPodReconciler(clientSet kubernetes.Interface, recorder record.EventRecorder,
+	maxGateDuration time.Duration) *StuckPodReconciler {
+	return &StuckPodReconciler{
+		pods:            clientSet.CoreV1(),
+		recorder:        recorder,
+		clock:           clock.RealClock{},
+		maxGateDuration: maxGateDuration,
+	}
+}
+
+func (r *StuckPodReconciler) Start(ctx context.Context) error {
+	r.log = log.FromContext(ctx, "handler", "StuckPodReconciler", "kind", "Pod [core/v1]")
+	r.log.Info("Starting the Stuck Pod Reconciler", "maxGateDuration", r.maxGateDuration)
+	metrics.InitPodPlacementControllerMetrics()
+	ticker := time.NewTicker(stuckPodReconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping the Stuck Pod Reconciler")
+			return nil
+		case <-ticker.C:
+			if err := r.reconcile(ctx); err != nil {
+				r.log.Error(err, "Unable to list the gated pods")
+			}
+		}
+	}
+}
+
+// reconcile removes the scheduling gate from the gated pods created more than maxGateDuration ago.
+// The pods that fail to be updated are retried at the next period.
+func (r *StuckPodReconciler) reconcile(ctx context.Context) error {
+	podList, err := r.pods.Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			utils.SchedulingGateLabel: utils.SchedulingGateLabelValueGated,
+		}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	for i := range podList.Items {
+		pod := &Pod{
+			Pod:      podList.Items[i],
+			ctx:      ctx,
+			recorder: r.recorder,
+		}
+		if !pod.HasSchedulingGate() || r.clock.Since(pod.CreationTimestamp.Time) < r.maxGateDuration {
+			continue
+		}
+		log := r.log.WithValues("namespace", pod.Namespace, "name", pod.Name)
+		pod.RemoveSchedulingGate()
+		if _, err := r.pods.Pods(pod.Namespace).Update(ctx, &pod.Pod, metav1.UpdateOptions{}); err != nil {
+			log.Error(err, "Unable to remove the scheduling gate from the stuck pod")
+			continue
+		}
+		log.Info("Forcibly removed the scheduling gate from the stuck pod",
+			"gatedFor", r.clock.Since(pod.CreationTimestamp.Time))
+		pod.publishEvent(corev1.EventTypeWarning, ArchitectureAwareSchedulingGateForcedRemoval,
+			fmt.Sprintf(SchedulingGateForcedRemovalMsg, utils.GetSchedulingGateName(), r.maxGateDuration))
+		metrics.GatedPodsGauge.Dec()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package podplacement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// fakePods stores the pods in memory. Only the methods used by the StuckPodReconciler and the
+// CompletedPodLabelsReconciler are implemented.
+type fakePods struct {
+	corev1client.CoreV1Interface
+	corev1client.PodInterface
+	pods map[string]*v1.Pod
+}
+
+func (f *fakePods) Pods(_ string) corev1client.PodInterface {
+	return f
+}
+
+func (f *fakePods) List(_ context.Context, opts metav1.ListOptions) (*v1.PodList, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	podList := &v1.PodList{}
+	for _, pod := range f.pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			podList.Items = append(podList.Items, *pod.DeepCopy())
+		}
+	}
+	return podList, nil
+}
+
+func (f *fakePods) Update(_ context.Context, pod *v1.Pod, _ metav1.UpdateOptions) (*v1.Pod, error) {
+	f.pods[pod.Name] = pod.DeepCopy()
+	return pod, nil
+}
+
+func TestStuckPodReconciler_Reconcile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	metrics.InitPodPlacementControllerMetrics()
+	now := time.Now()
+	fakeClock := clocktesting.NewFakePassiveClock(now)
+	newGatedPod := func(name string) *v1.Pod {
+		pod := builder.NewPod().WithSchedulingGates(utils.SchedulingGateName).
+			WithLabels(utils.SchedulingGateLabel, utils.SchedulingGateLabelValueGated).Build()
+		pod.Name = name
+		pod.CreationTimestamp = metav1.NewTime(now)
+		return pod
+	}
+	pods := &fakePods{pods: map[string]*v1.Pod{
+		"stuck": newGatedPod("stuck"),
+	}}
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &StuckPodReconciler{
+		pods:            pods,
+		recorder:        recorder,
+		clock:           fakeClock,
+		maxGateDuration: 10 * time.Minute,
+		log:             logr.Discard(),
+	}
+
+	fakeClock.SetTime(now.Add(5 * time.Minute))
+	// A pod created later is not stuck when the threshold is passed for the first one
+	pods.pods["recent"] = newGatedPod("recent")
+	pods.pods["recent"].CreationTimestamp = metav1.NewTime(now.Add(5 * time.Minute))
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+	g.Expect(pods.pods["stuck"].Spec.SchedulingGates).To(HaveLen(1), "the gate was removed before the threshold")
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	fakeClock.SetTime(now.Add(11 * time.Minute))
+	g.Expect(reconciler.reconcile(context.TODO())).To(Succeed())
+	g.Expect(pods.pods["stuck"].Spec.SchedulingGates).To(BeEmpty())
+	g.Expect(pods.pods["stuck"].Labels).To(HaveKeyWithValue(utils.SchedulingGateLabel,
+		utils.SchedulingGateLabelValueRemoved))
+	g.Expect(pods.pods["recent"].Spec.SchedulingGates).To(HaveLen(1))
+	g.Expect(recorder.Events).To(Receive(And(HavePrefix(v1.EventTypeWarning),
+		ContainSubstring(ArchitectureAwareSchedulingGateForcedRemoval))))
+	g.Expect(recorder.Events).To(BeEmpty())
+}
@@ -332,10 +332,11 @@ func runManager() {
 
 	By("Setting up PodPlacement controller")
 	Expect((&PodReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		ClientSet: clientset,
-		Recorder:  mgr.GetEventRecorderFor(utils.OperatorName),
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		ClientSet:      clientset,
+		Recorder:       mgr.GetEventRecorderFor(utils.OperatorName),
+		ImageInspector: image.FacadeSingleton(),
 	}).SetupWithManager(mgr)).NotTo(HaveOccurred())
 	pool, err := ants.NewMultiPool(10, 10, ants.LeastTasks, ants.WithPreAlloc(true),
 		ants.WithNonblocking(true))
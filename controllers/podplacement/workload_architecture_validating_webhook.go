@@ -0,0 +1,109 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podplacement
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
+)
+
+// [disabled:operator]kubebuilder:webhook:path=/validate-workload-architecture,mutating=false,sideEffects=None,admissionReviewVersions=v1,failurePolicy=ignore,groups="apps",resources=deployments;statefulsets;replicasets,verbs=create;update,versions=v1,name=workload-architecture-validation.multiarch.openshift.io
+
+// WorkloadArchitectureValidatingWebHook warns the users applying Deployments, StatefulSets and ReplicaSets whose pod
+// templates use images that do not support any common architecture, as the JobArchitectureValidatingWebHook does
+// for the Jobs. The workloads are always admitted: the warning gives an immediate feedback at apply time, before
+// their pods are created. The updates leaving the spec of the pod template unchanged, e.g., the scaling ones, are
+// admitted without inspecting the images again.
+// It is registered in the same ValidatingWebhookConfiguration as the PodArchitectureValidatingWebHook.
+type WorkloadArchitectureValidatingWebHook struct {
+	imageInspector image.ICache
+	// maxConcurrentInspections is the maximum number of distinct images of a pod inspected concurrently.
+	maxConcurrentInspections int
+	decoder                  admission.Decoder
+	once                     sync.Once
+	scheme                   *runtime.Scheme
+}
+
+func (a *WorkloadArchitectureValidatingWebHook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	a.once.Do(func() {
+		a.decoder = admission.NewDecoder(a.scheme)
+	})
+	// The image inspection metrics are the ones of the pod placement controller
+	metrics.InitPodPlacementControllerMetrics()
+	template, err := a.podTemplate(req.Kind.Kind, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if req.Operation == admissionv1.Update {
+		oldTemplate, err := a.podTemplate(req.Kind.Kind, req.OldObject)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if equality.Semantic.DeepEqual(oldTemplate.Spec, template.Spec) {
+			return admission.Allowed("the spec of the pod template is unchanged")
+		}
+	}
+	return podTemplateArchitectureResponse(ctx, a.imageInspector, a.maxConcurrentInspections, req, template)
+}
+
+// podTemplate decodes the Deployment, StatefulSet or ReplicaSet of the given kind and returns its pod template.
+func (a *WorkloadArchitectureValidatingWebHook) podTemplate(kind string, rawObject runtime.RawExtension) (
+	*corev1.PodTemplateSpec, error) {
+	switch kind {
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		if err := a.decoder.DecodeRaw(rawObject, deployment); err != nil {
+			return nil, err
+		}
+		return &deployment.Spec.Template, nil
+	case "StatefulSet":
+		statefulSet := &appsv1.StatefulSet{}
+		if err := a.decoder.DecodeRaw(rawObject, statefulSet); err != nil {
+			return nil, err
+		}
+		return &statefulSet.Spec.Template, nil
+	case "ReplicaSet":
+		replicaSet := &appsv1.ReplicaSet{}
+		if err := a.decoder.DecodeRaw(rawObject, replicaSet); err != nil {
+			return nil, err
+		}
+		return &replicaSet.Spec.Template, nil
+	default:
+		return nil, fmt.Errorf("unexpected kind %q", kind)
+	}
+}
+
+func NewWorkloadArchitectureValidatingWebHook(imageInspector image.ICache, maxConcurrentInspections int,
+	scheme *runtime.Scheme) *WorkloadArchitectureValidatingWebHook {
+	return &WorkloadArchitectureValidatingWebHook{
+		imageInspector:           imageInspector,
+		maxConcurrentInspections: maxConcurrentInspections,
+		scheme:                   scheme,
+	}
+}
@@ -0,0 +1,154 @@
+package podplacement
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/builder"
+	"github.com/openshift/multiarch-tuning-operator/pkg/testing/image/fake"
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func TestWorkloadArchitectureValidatingWebHook_Handle(t *testing.T) {
+	podTemplate := func(images ...string) corev1.PodTemplateSpec {
+		pod := builder.NewPod().WithContainersImages(images...).Build()
+		return corev1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta, Spec: pod.Spec}
+	}
+	conflictingImagesWarning := func(kind string) string {
+		return "the pods of the " + kind + " will not be schedulable: " +
+			"the images of the pod do not support any common architecture: " +
+			fake.SingleArchAmd64Image + " (amd64); " + fake.SingleArchArm64Image + " (arm64)"
+	}
+	tests := []struct {
+		name         string
+		kind         string
+		operation    admissionv1.Operation
+		namespace    string
+		object       runtime.Object
+		oldObject    runtime.Object
+		wantWarnings []string
+	}{
+		{
+			name:      "deployment with compatible images",
+			kind:      "Deployment",
+			operation: admissionv1.Create,
+			namespace: "test-namespace",
+			object: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+				Template: podTemplate(fake.MultiArchImage, fake.SingleArchAmd64Image),
+			}},
+		},
+		{
+			name:      "deployment with conflicting images",
+			kind:      "Deployment",
+			operation: admissionv1.Create,
+			namespace: "test-namespace",
+			object: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+			wantWarnings: []string{conflictingImagesWarning("Deployment")},
+		},
+		{
+			name:      "deployment updated with conflicting images",
+			kind:      "Deployment",
+			operation: admissionv1.Update,
+			namespace: "test-namespace",
+			object: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+			oldObject: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image),
+			}},
+			wantWarnings: []string{conflictingImagesWarning("Deployment")},
+		},
+		{
+			name:      "deployment scaled with an unchanged pod template",
+			kind:      "Deployment",
+			operation: admissionv1.Update,
+			namespace: "test-namespace",
+			object: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+				Replicas: utils.NewPtr(int32(3)),
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+			oldObject: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+				Replicas: utils.NewPtr(int32(1)),
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+		},
+		{
+			name:      "statefulset with compatible images",
+			kind:      "StatefulSet",
+			operation: admissionv1.Create,
+			namespace: "test-namespace",
+			object: &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{
+				Template: podTemplate(fake.MultiArchImage, fake.MultiArchImage2),
+			}},
+		},
+		{
+			name:      "statefulset with conflicting images",
+			kind:      "StatefulSet",
+			operation: admissionv1.Create,
+			namespace: "test-namespace",
+			object: &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+			wantWarnings: []string{conflictingImagesWarning("StatefulSet")},
+		},
+		{
+			name:      "replicaset with conflicting images",
+			kind:      "ReplicaSet",
+			operation: admissionv1.Create,
+			namespace: "test-namespace",
+			object: &appsv1.ReplicaSet{Spec: appsv1.ReplicaSetSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+			wantWarnings: []string{conflictingImagesWarning("ReplicaSet")},
+		},
+		{
+			name:      "deployment with conflicting images in an excluded namespace",
+			kind:      "Deployment",
+			operation: admissionv1.Create,
+			namespace: "kube-system",
+			object: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+				Template: podTemplate(fake.SingleArchAmd64Image, fake.SingleArchArm64Image),
+			}},
+		},
+	}
+	metrics.InitPodPlacementControllerMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			a := NewWorkloadArchitectureValidatingWebHook(fake.FacadeSingleton(), 0, scheme.Scheme)
+
+			raw, err := json.Marshal(tt.object)
+			g.Expect(err).NotTo(HaveOccurred())
+			var oldRaw []byte
+			if tt.oldObject != nil {
+				oldRaw, err = json.Marshal(tt.oldObject)
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			resp := a.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: tt.kind},
+					Namespace: tt.namespace,
+					Operation: tt.operation,
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			})
+			g.Expect(resp.Allowed).To(BeTrue(), "the workloads are never denied")
+			g.Expect(resp.Warnings).To(Equal(tt.wantWarnings))
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -33,6 +34,8 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -55,9 +58,15 @@ import (
 	"github.com/openshift/library-go/pkg/operator/events"
 
 	"github.com/panjf2000/ants/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	zapuber "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	ocpconfigv1 "github.com/openshift/api/config/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	multiarchv1alpha1 "github.com/openshift/multiarch-tuning-operator/apis/multiarch/v1alpha1"
@@ -66,6 +75,8 @@ import (
 	"github.com/openshift/multiarch-tuning-operator/apis/multiarch/common"
 	"github.com/openshift/multiarch-tuning-operator/controllers/operator"
 	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement"
+	"github.com/openshift/multiarch-tuning-operator/controllers/podplacement/metrics"
+	"github.com/openshift/multiarch-tuning-operator/pkg/image"
 	"github.com/openshift/multiarch-tuning-operator/pkg/informers/clusterpodplacementconfig"
 	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 )
@@ -82,17 +93,36 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 	metricsAddr,
 	probeAddr,
+	pprofAddr,
 	certDir,
 	globalPullSecretNamespace,
 	globalPullSecretName,
-	registryCertificatesConfigMapName string
+	registryCertificatesConfigMapName,
+	schedulingGateName,
+	managedSchedulingGates string
 	enableLeaderElection,
 	enableClusterPodPlacementConfigOperandWebHook,
 	enableClusterPodPlacementConfigOperandControllers,
-	enableCPPCInformer bool
-	enableOperator  bool
-	initialLogLevel int
-	postFuncs       []func()
+	enableCPPCInformer,
+	perNamespaceMetrics,
+	imageInspectionPinDigestInCache,
+	enablePprof bool
+	enableOperator                      bool
+	initialLogLevel                     int
+	webhookWorkerPoolSize               int
+	gateRemovalWorkerPoolSize           int
+	maxConcurrentInspections            int
+	imageInspectionCacheJitterFraction  float64
+	imageInspectionRetryPolicy          image.RetryPolicy
+	imageInspectionCircuitBreakerPolicy image.CircuitBreakerPolicy
+	imageInspectionRegistryTimeouts     image.RegistryTimeouts
+	delayedEventBackoff                 wait.Backoff
+	imageInspectionCacheSyncInterval,
+	imageInspectionCacheHorizon,
+	imageInspectionCacheGenerationSyncInterval,
+	maxGateDuration,
+	webhookDrainTimeout time.Duration
+	postFuncs []func()
 )
 
 func init() {
@@ -100,6 +130,7 @@ func init() {
 	utilruntime.Must(multiarchv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(multiarchv1beta1.AddToScheme(scheme))
 	utilruntime.Must(monitoringv1.AddToScheme(scheme))
+	utilruntime.Must(ocpconfigv1.Install(scheme))
 }
 
 func main() {
@@ -113,6 +144,12 @@ func main() {
 	leaderID := "208d7abd.multiarch.openshift.io"
 	if enableOperator {
 		leaderID = fmt.Sprintf("operator-%s", leaderID)
+		// The operator only watches the Secrets of its namespace, e.g., the custom CA bundle of the operands.
+		cacheOpts.ByObject = map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {
+				Namespaces: map[string]cache.Config{utils.Namespace(): {}},
+			},
+		}
 	}
 	if enableClusterPodPlacementConfigOperandControllers {
 		leaderID = fmt.Sprintf("ppc-controllers-%s", leaderID)
@@ -177,6 +214,13 @@ func main() {
 		must(mgr.Add(clusterpodplacementconfig.NewCPPCSyncer(mgr)), "unable to instantiate CPPCSyncer")
 	}
 
+	if enablePprof {
+		// The manager shuts down the pprof server gracefully when it stops
+		pprofServer, err := utils.NewPprofServer(pprofAddr)
+		must(err, "unable to create the pprof server")
+		must(mgr.Add(pprofServer), unableToAddRunnable, runnableKey, "PprofServer")
+	}
+
 	if enableOperator {
 		RunOperator(mgr)
 	}
@@ -187,6 +231,8 @@ func main() {
 		RunClusterPodPlacementConfigOperandWebHook(mgr)
 	}
 
+	setupTracing()
+
 	setupLog.Info("starting manager")
 	must(mgr.Start(ctrl.SetupSignalHandler()), "unable to start the manager")
 	setupLog.Info("the manager has stopped")
@@ -229,35 +275,117 @@ func RunOperator(mgr ctrl.Manager) {
 func RunClusterPodPlacementConfigOperandControllers(mgr ctrl.Manager) {
 	config := ctrl.GetConfigOrDie()
 	clientset := kubernetes.NewForConfigOrDie(config)
-
-	must((&podplacement.PodReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		ClientSet: clientset,
-		Recorder:  mgr.GetEventRecorderFor(utils.OperatorName),
-	}).SetupWithManager(mgr),
+	image.FacadeSingleton().SetRetryPolicy(imageInspectionRetryPolicy)
+	image.FacadeSingleton().SetCircuitBreakerPolicy(imageInspectionCircuitBreakerPolicy)
+	image.FacadeSingleton().SetCacheJitterFraction(imageInspectionCacheJitterFraction)
+	image.FacadeSingleton().SetPinDigestInCache(imageInspectionPinDigestInCache)
+	image.FacadeSingleton().SetRegistryTimeouts(imageInspectionRegistryTimeouts)
+	metrics.SetPerNamespaceMetrics(perNamespaceMetrics)
+	utils.SetSchedulingGateName(schedulingGateName)
+	utils.SetManagedSchedulingGates(managedSchedulingGatesList())
+
+	archInventory := podplacement.NewClusterArchInventory(clientset)
+	must(mgr.Add(archInventory), unableToAddRunnable, runnableKey, "ClusterArchInventory")
+
+	must(podplacement.NewPodReconciler(mgr.GetClient(), mgr.GetScheme(), clientset,
+		mgr.GetEventRecorderFor(utils.OperatorName), image.FacadeSingleton(), archInventory,
+		gateRemovalWorkerPoolSize, maxConcurrentInspections).SetupWithManager(mgr),
 		unableToCreateController, controllerKey, "PodReconciler")
 
+	for i, gateName := range utils.GetManagedSchedulingGates() {
+		must(podplacement.NewManagedSchedulingGateReconciler(mgr.GetClient(), mgr.GetEventRecorderFor(utils.OperatorName),
+			gateName, i).SetupWithManager(mgr),
+			unableToCreateController, controllerKey, "ManagedSchedulingGateReconciler", "schedulingGate", gateName)
+	}
+
 	must(mgr.Add(podplacement.NewGlobalPullSecretSyncer(clientset, globalPullSecretNamespace, globalPullSecretName)),
 		unableToAddRunnable, runnableKey, "GlobalPullSecretSyncer")
+
+	must(mgr.Add(podplacement.NewPersistentCacheSyncer(clientset, imageInspectionCacheSyncInterval, imageInspectionCacheHorizon)),
+		unableToAddRunnable, runnableKey, "PersistentCacheSyncer")
+
+	must(mgr.Add(podplacement.NewCacheGenerationSyncer(clientset, imageInspectionCacheGenerationSyncInterval)),
+		unableToAddRunnable, runnableKey, "CacheGenerationSyncer")
+
+	must(mgr.Add(podplacement.NewImageInspectionHealthReporter(clientset)),
+		unableToAddRunnable, runnableKey, "ImageInspectionHealthReporter")
+
+	must(mgr.Add(podplacement.NewStuckPodReconciler(clientset, mgr.GetEventRecorderFor(utils.OperatorName), maxGateDuration)),
+		unableToAddRunnable, runnableKey, "StuckPodReconciler")
+
+	must(mgr.Add(podplacement.NewGateInjectionKillSwitchReconciler(clientset, mgr.GetEventRecorderFor(utils.OperatorName))),
+		unableToAddRunnable, runnableKey, "GateInjectionKillSwitchReconciler")
+
+	must(mgr.Add(podplacement.NewLegacyAffinityRepairReconciler(clientset, mgr.GetEventRecorderFor(utils.OperatorName),
+		image.FacadeSingleton(), archInventory)),
+		unableToAddRunnable, runnableKey, "LegacyAffinityRepairReconciler")
+
+	must(mgr.Add(podplacement.NewCompletedPodLabelsReconciler(clientset)),
+		unableToAddRunnable, runnableKey, "CompletedPodLabelsReconciler")
 }
 
 func RunClusterPodPlacementConfigOperandWebHook(mgr ctrl.Manager) {
 	config := ctrl.GetConfigOrDie()
 	clientset := kubernetes.NewForConfigOrDie(config)
-	pool, err := ants.NewMultiPool(16, 16, ants.LeastTasks,
-		ants.WithPreAlloc(true))
+	image.FacadeSingleton().SetRetryPolicy(imageInspectionRetryPolicy)
+	image.FacadeSingleton().SetCircuitBreakerPolicy(imageInspectionCircuitBreakerPolicy)
+	image.FacadeSingleton().SetCacheJitterFraction(imageInspectionCacheJitterFraction)
+	image.FacadeSingleton().SetPinDigestInCache(imageInspectionPinDigestInCache)
+	image.FacadeSingleton().SetRegistryTimeouts(imageInspectionRegistryTimeouts)
+	metrics.SetPerNamespaceMetrics(perNamespaceMetrics)
+	utils.SetSchedulingGateName(schedulingGateName)
+	utils.SetManagedSchedulingGates(managedSchedulingGatesList())
+	registriesReadinessProbe := podplacement.NewRegistriesReadinessProbe()
+	must(mgr.Add(registriesReadinessProbe), unableToAddRunnable, runnableKey, "RegistriesReadinessProbe")
+	must(mgr.AddReadyzCheck("registries", registriesReadinessProbe.Check), "unable to set up the registries ready check")
+	pool, err := podplacement.NewWorkerPool(webhookWorkerPoolSize, ants.WithPreAlloc(true))
 	must(err, "unable to create multi pool for the webhook's event messages")
+	handler := podplacement.NewPodSchedulingGateMutatingWebHook(mgr.GetClient(), clientset, mgr.GetScheme(),
+		mgr.GetEventRecorderFor(utils.OperatorName), pool)
+	handler.SetDelayedEventBackoff(delayedEventBackoff)
+	must(mgr.AddReadyzCheck("webhook", handler.Check), "unable to set up the webhook ready check")
 	postFuncs = append(postFuncs, func() {
-		err = pool.ReleaseTimeout(30 * time.Second)
-		if err != nil {
-			setupLog.Error(err, "failed to release the worker pool")
+		// The in-flight event jobs are completed before exiting
+		ctx, cancel := context.WithTimeout(context.Background(), webhookDrainTimeout)
+		defer cancel()
+		if err := handler.Shutdown(ctx); err != nil {
+			setupLog.Error(err, "failed to drain the worker pool")
 		}
 		ants.Release()
 	})
-	handler := podplacement.NewPodSchedulingGateMutatingWebHook(mgr.GetClient(), clientset, mgr.GetScheme(),
-		mgr.GetEventRecorderFor(utils.OperatorName), pool)
 	mgr.GetWebhookServer().Register("/add-pod-scheduling-gate", &webhook.Admission{Handler: handler})
+	mgr.GetWebhookServer().Register("/validate-pod-architecture", &webhook.Admission{
+		Handler: podplacement.NewPodArchitectureValidatingWebHook(image.FacadeSingleton(),
+			maxConcurrentInspections, mgr.GetScheme())})
+	mgr.GetWebhookServer().Register("/validate-job-architecture", &webhook.Admission{
+		Handler: podplacement.NewJobArchitectureValidatingWebHook(image.FacadeSingleton(),
+			maxConcurrentInspections, mgr.GetScheme())})
+	mgr.GetWebhookServer().Register("/validate-workload-architecture", &webhook.Admission{
+		Handler: podplacement.NewWorkloadArchitectureValidatingWebHook(image.FacadeSingleton(),
+			maxConcurrentInspections, mgr.GetScheme())})
+}
+
+// setupTracing installs a global TracerProvider exporting the spans via OTLP/HTTP when an OTLP endpoint is configured
+// through the standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables.
+// Otherwise, the default no-op TracerProvider is kept.
+func setupTracing() {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return
+	}
+	exporter, err := otlptracehttp.New(context.Background())
+	must(err, "unable to create the OTLP trace exporter")
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(utils.OperatorName))),
+	)
+	otel.SetTracerProvider(tp)
+	postFuncs = append(postFuncs, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := tp.Shutdown(ctx); err != nil {
+			setupLog.Error(err, "failed to shutdown the tracer provider")
+		}
+	})
 }
 
 func validateFlags() error {
@@ -268,24 +396,119 @@ func validateFlags() error {
 	if btoi(enableOperator)+btoi(enableClusterPodPlacementConfigOperandControllers)+btoi(enableClusterPodPlacementConfigOperandWebHook) > 1 {
 		return errors.New("only one of the following flags can be set: --enable-operator, --enable-ppc-controllers, --enable-ppc-webhook")
 	}
+	if webhookWorkerPoolSize < 1 || gateRemovalWorkerPoolSize < 1 {
+		return errors.New("the --webhook-worker-pool-size and --gate-removal-worker-pool-size flags must be greater than 0")
+	}
+	if maxConcurrentInspections < 1 {
+		return errors.New("the --max-concurrent-inspections flag must be greater than 0")
+	}
+	if imageInspectionRetryPolicy.InitialInterval <= 0 || imageInspectionRetryPolicy.MaxInterval <= 0 ||
+		imageInspectionRetryPolicy.Multiplier < 1 || imageInspectionRetryPolicy.MaxRetries < 0 {
+		return errors.New("the --image-inspection-retry-* flags must be positive and the multiplier must be at least 1")
+	}
+	if delayedEventBackoff.Duration <= 0 || delayedEventBackoff.Factor < 1 || delayedEventBackoff.Steps < 1 ||
+		delayedEventBackoff.Jitter < 0 {
+		return errors.New("the --delayed-event-backoff-* flags must be positive and the factor must be at least 1")
+	}
+	if imageInspectionCircuitBreakerPolicy.FailureThreshold < 0 || imageInspectionCircuitBreakerPolicy.ResetTimeout <= 0 {
+		return errors.New("the --image-inspection-circuit-breaker-* flags must be positive")
+	}
+	if imageInspectionCacheSyncInterval <= 0 || imageInspectionCacheHorizon <= 0 || imageInspectionCacheGenerationSyncInterval <= 0 {
+		return errors.New("the --image-inspection-cache-sync-interval, --image-inspection-cache-horizon and " +
+			"--image-inspection-cache-generation-sync-interval flags must be positive")
+	}
+	if imageInspectionCacheJitterFraction < 0 || imageInspectionCacheJitterFraction >= 1 {
+		return errors.New("the --image-inspection-cache-jitter-fraction flag must be in [0, 1)")
+	}
+	if webhookDrainTimeout <= 0 {
+		return errors.New("the --webhook-drain-timeout flag must be positive")
+	}
+	if maxGateDuration <= 0 {
+		return errors.New("the --max-gate-duration flag must be positive")
+	}
+	if errs := validation.IsQualifiedName(schedulingGateName); len(errs) > 0 {
+		return fmt.Errorf("the --scheduling-gate-name flag must be a qualified name: %s", strings.Join(errs, "; "))
+	}
+	for _, name := range managedSchedulingGatesList() {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return fmt.Errorf("the --managed-scheduling-gates flag must list qualified names: %s", strings.Join(errs, "; "))
+		}
+		if name == schedulingGateName {
+			return errors.New("the --managed-scheduling-gates flag must not include the --scheduling-gate-name")
+		}
+	}
 	return nil
 }
 
 func bindFlags() {
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.BoolVar(&enablePprof, "enable-pprof", false, "Enable the pprof handlers at /debug/pprof/. The port must not be exposed outside the cluster.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", utils.DefaultPprofBindAddress, "The address the pprof endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&certDir, "cert-dir", "/var/run/manager/tls", "The directory where the TLS certs are stored")
 	// TODO: Change the defaults to match a local secret; the OCP specific settings will be provided by the operator
-	flag.StringVar(&globalPullSecretNamespace, "global-pull-secret-namespace", "openshift-config", "The namespace where the global pull secret is stored")
-	flag.StringVar(&globalPullSecretName, "global-pull-secret-name", "pull-secret", "The name of the global pull secret")
+	flag.StringVar(&globalPullSecretNamespace, "global-pull-secret-namespace", utils.DefaultGlobalPullSecretNamespace, "The namespace where the global pull secret is stored")
+	flag.StringVar(&globalPullSecretName, "global-pull-secret-name", utils.DefaultGlobalPullSecretName, "The name of the global pull secret")
 	flag.StringVar(&registryCertificatesConfigMapName, "registry-certificates-configmap-name", "image-registry-certificates", "The name of the configmap that contains the registry certificates")
 	flag.BoolVar(&enableClusterPodPlacementConfigOperandWebHook, "enable-ppc-webhook", false, "Enable the pod placement config operand webhook")
 	flag.BoolVar(&enableClusterPodPlacementConfigOperandControllers, "enable-ppc-controllers", false, "Enable the pod placement config operand controllers")
 	flag.BoolVar(&enableOperator, "enable-operator", false, "Enable the operator")
 	flag.BoolVar(&enableCPPCInformer, "enable-cppc-informer", false, "Enable informer for ClusterPodPlacementConfig")
+	flag.IntVar(&webhookWorkerPoolSize, "webhook-worker-pool-size", int(multiarchv1beta1.DefaultWebhookWorkerPoolSize),
+		"The number of workers the pod placement webhook uses to publish the events of the gated pods")
+	flag.IntVar(&gateRemovalWorkerPoolSize, "gate-removal-worker-pool-size", int(multiarchv1beta1.DefaultGateRemovalWorkerPoolSize),
+		"The maximum number of pods the pod placement controller processes concurrently")
+	flag.DurationVar(&delayedEventBackoff.Duration, "delayed-event-backoff-duration",
+		multiarchv1beta1.DefaultDelayedEventBackoffDuration,
+		"The time the pod placement webhook waits before retrying to get a gated pod to publish its event")
+	flag.Float64Var(&delayedEventBackoff.Factor, "delayed-event-backoff-factor", multiarchv1beta1.DefaultDelayedEventBackoffFactor,
+		"The factor the time to wait is multiplied by after each retry to get a gated pod")
+	flag.IntVar(&delayedEventBackoff.Steps, "delayed-event-backoff-steps", int(multiarchv1beta1.DefaultDelayedEventBackoffSteps),
+		"The maximum number of attempts to get a gated pod")
+	flag.Float64Var(&delayedEventBackoff.Jitter, "delayed-event-backoff-jitter", multiarchv1beta1.DefaultDelayedEventBackoffJitter,
+		"The maximum fraction of the time to wait randomly added to it before retrying to get a gated pod")
+	flag.DurationVar(&webhookDrainTimeout, "webhook-drain-timeout", podplacement.DefaultWebhookDrainTimeout,
+		"The maximum time the pod placement webhook waits on shutdown for the events of the gated pods to be published")
+	flag.IntVar(&maxConcurrentInspections, "max-concurrent-inspections", int(multiarchv1beta1.DefaultMaxConcurrentInspections),
+		"The maximum number of distinct images of a pod inspected concurrently")
+	flag.DurationVar(&imageInspectionRetryPolicy.InitialInterval, "image-inspection-retry-initial-interval",
+		multiarchv1beta1.DefaultImageInspectionRetryInitialInterval, "The time to wait before the first retry of a failed image inspection")
+	flag.DurationVar(&imageInspectionRetryPolicy.MaxInterval, "image-inspection-retry-max-interval",
+		multiarchv1beta1.DefaultImageInspectionRetryMaxInterval, "The maximum time to wait between two retries of a failed image inspection")
+	flag.Float64Var(&imageInspectionRetryPolicy.Multiplier, "image-inspection-retry-multiplier",
+		multiarchv1beta1.DefaultImageInspectionRetryMultiplier, "The factor the time to wait is multiplied by after each retry of a failed image inspection")
+	flag.IntVar(&imageInspectionRetryPolicy.MaxRetries, "image-inspection-retry-max-retries",
+		int(multiarchv1beta1.DefaultImageInspectionRetryMaxRetries), "The maximum number of retries of a failed image inspection")
+	flag.IntVar(&imageInspectionCircuitBreakerPolicy.FailureThreshold, "image-inspection-circuit-breaker-failure-threshold",
+		int(multiarchv1beta1.DefaultImageInspectionCircuitBreakerFailureThreshold),
+		"The number of consecutive transient errors of a registry that opens its circuit breaker (0 disables the circuit breakers)")
+	flag.DurationVar(&imageInspectionCircuitBreakerPolicy.ResetTimeout, "image-inspection-circuit-breaker-reset-timeout",
+		multiarchv1beta1.DefaultImageInspectionCircuitBreakerResetTimeout,
+		"The time after which an open circuit breaker lets a single image inspection through to probe the registry")
+	flag.BoolVar(&perNamespaceMetrics, "per-namespace-metrics", false,
+		"Add the namespace label to the per-namespace metrics of the gated and processed pods")
+	flag.DurationVar(&imageInspectionCacheSyncInterval, "image-inspection-cache-sync-interval", 5*time.Minute,
+		"The period of the synchronization of the image inspection cache with its ConfigMap")
+	flag.DurationVar(&imageInspectionCacheHorizon, "image-inspection-cache-horizon", 6*time.Hour,
+		"The maximum age of the image inspection cache entries loaded from the ConfigMap at startup")
+	flag.DurationVar(&imageInspectionCacheGenerationSyncInterval, "image-inspection-cache-generation-sync-interval", 30*time.Second,
+		"The period of the publication of the local image inspection cache invalidations in the generation shared by the replicas")
+	flag.Float64Var(&imageInspectionCacheJitterFraction, "image-inspection-cache-jitter-fraction",
+		multiarchv1beta1.DefaultCacheJitterFraction,
+		"The fraction of the TTL of the image inspection cache entries randomly added to or subtracted from it")
+	flag.BoolVar(&imageInspectionPinDigestInCache, "image-inspection-pin-digest-in-cache", false,
+		"Resolve the tags of the images to their digest before their inspection and key the cache entries by digest")
+	flag.Var(&imageInspectionRegistryTimeouts, "image-inspection-registry-timeouts",
+		"The comma-separated domain=duration pairs of the timeouts of the image inspections of the given registries")
+	flag.DurationVar(&maxGateDuration, "max-gate-duration", multiarchv1beta1.DefaultMaxGateDuration,
+		"The maximum time a pod can stay gated before its scheduling gate is forcibly removed")
+	flag.StringVar(&schedulingGateName, "scheduling-gate-name", utils.SchedulingGateName,
+		"The name of the scheduling gate the pod placement operands add to and remove from the pods")
+	flag.StringVar(&managedSchedulingGates, "managed-scheduling-gates", "",
+		"The comma-separated names of the additional scheduling gates the pod placement operands add to and remove from the pods")
 	// This may be deprecated in the future. It is used to support the current way of setting the log level for operands
 	// If operands will start to support a controller that watches the ClusterPodPlacementConfig, this flag may be removed
 	// and the log level will be set in the ClusterPodPlacementConfig at runtime (with no need for reconciliation)
@@ -299,6 +522,14 @@ func bindFlags() {
 	ctrllog.SetLogger(zapLogger)
 }
 
+// managedSchedulingGatesList returns the names of the scheduling gates in the --managed-scheduling-gates flag.
+func managedSchedulingGatesList() []string {
+	if managedSchedulingGates == "" {
+		return nil
+	}
+	return strings.Split(managedSchedulingGates, ",")
+}
+
 func must(err error, msg string, keysAndValues ...interface{}) {
 	if err != nil {
 		setupLog.Error(err, msg, keysAndValues...)
@@ -300,6 +300,7 @@ var _ = Describe("The Multiarch Tuning Operator", Serial, func() {
 		},
 			Entry(utils.ControlPlaneNodeSelectorLabel, utils.ControlPlaneNodeSelectorLabel),
 			Entry(utils.MasterNodeSelectorLabel, utils.MasterNodeSelectorLabel),
+			Entry(utils.InfraNodeSelectorLabel, utils.InfraNodeSelectorLabel),
 		)
 	})
 	Context("When a pod placement config is created", func() {
@@ -335,3 +335,17 @@ func Test_authCfg_expandGlobs(t *testing.T) {
 		})
 	}
 }
+
+func Test_marshaledImagePullSecrets_Precedence(t *testing.T) {
+	globalPullSecret := []byte(`{"registry.example.com":{"auth":"Z2xvYmFsOnBhc3M="},"quay.io":{"auth":"Z2xvYmFsOnF1YXk="}}`)
+	podPullSecret := []byte(`{"registry.example.com":{"auth":"cG9kOnBhc3M="}}`)
+	// The registry inspector passes the global pull secret first, followed by the pods' pull secrets
+	got, err := marshaledImagePullSecrets("//registry.example.com/foo/bar:latest", [][]byte{globalPullSecret, podPullSecret})
+	if err != nil {
+		t.Fatalf("marshaledImagePullSecrets() error = %v", err)
+	}
+	want := `{"auths":{"quay.io":{"auth":"Z2xvYmFsOnF1YXk="},"registry.example.com":{"auth":"cG9kOnBhc3M="}}}`
+	if string(got) != want {
+		t.Errorf("marshaledImagePullSecrets() = %s, want %s", got, want)
+	}
+}
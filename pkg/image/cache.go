@@ -20,52 +20,208 @@ import (
 	"context"
 	"encoding/hex"
 	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/openshift/multiarch-tuning-operator/pkg/image/metrics"
 	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
 
+	"github.com/containers/image/v5/docker/reference"
 	"github.com/hashicorp/golang-lru/v2/expirable"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	cacheSize = 256
+	cacheTTL  = 6 * time.Hour
+	// lruTTL is the TTL of the LRU cache. It is longer than the TTL of any entry, whose jitter fraction is lower
+	// than 1: the entries expire according to their own TTL.
+	lruTTL = 2 * cacheTTL
+
+	DefaultCacheJitterFraction = 0.2
+)
+
+type cacheEntry struct {
+	architectures sets.Set[string]
+	// addedAt is the time the image was inspected. It is preserved across the exports and imports of the entry.
+	addedAt time.Time
+	// ttl is the jittered TTL of the entry. The entries without TTL expire after cacheTTL.
+	ttl time.Duration
+}
+
+// expired returns true if the entry is older than its TTL.
+func (e cacheEntry) expired() bool {
+	ttl := e.ttl
+	if ttl <= 0 {
+		ttl = cacheTTL
+	}
+	return time.Since(e.addedAt) >= ttl
+}
+
+// CacheEntry is the serializable form of an entry of the inspection cache.
+// The Key is a hash of the image reference, the operating system and the pull secrets used to inspect it.
+type CacheEntry struct {
+	Key           string    `json:"key"`
+	Architectures []string  `json:"architectures"`
+	AddedAt       time.Time `json:"addedAt"`
+}
+
 type cacheProxy struct {
 	registryInspector IRegistryInspector
-	imageRefsCache    *expirable.LRU[string, sets.Set[string]] // LRU cache with expirable keys
+	imageRefsCache    *expirable.LRU[string, cacheEntry] // LRU cache with expirable keys
+	// invalidations counts the cached entries that a bypass of the cache found to be stale
+	invalidations atomic.Uint64
+	// mutex protects the jitterFraction field
+	mutex sync.Mutex
+	// jitterFraction spreads the TTLs of the entries over [cacheTTL*(1-jitterFraction), cacheTTL*(1+jitterFraction)),
+	// so that the entries added together, e.g., after a restart, do not expire together.
+	jitterFraction float64
+	// randFloat64 returns a pseudo-random number in [0, 1). It is replaced in the tests.
+	randFloat64 func() float64
+	// pinDigest enables the resolution of the tags of the images to their digest before looking them up in the
+	// cache, so that the entries are keyed by digest and an update of a tag cannot be served a stale entry.
+	pinDigest atomic.Bool
 }
 
-func (c *cacheProxy) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string,
+func (c *cacheProxy) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, operatingSystem string,
 	skipCache bool, secrets [][]byte) (sets.Set[string], error) {
 	metrics.InitCommonMetrics()
 	metrics.InspectionGauge.Set(float64(c.imageRefsCache.Len()))
 	now := time.Now()
+	log := ctrllog.FromContext(ctx).WithValues("imageReference", imageReference)
+	if c.pinDigest.Load() && !isDigested(imageReference) {
+		// The pinned reference is both the key of the cache entry and the image inspected on a miss: the
+		// architectures cached are the ones of the digest, even if the tag is updated during the inspection.
+		pinned, err := c.registryInspector.resolveDigest(ctx, imageReference, secrets)
+		if err != nil {
+			return nil, err
+		}
+		log.V(3).Info("Pinned the image reference to its digest", "pinnedImageReference", pinned)
+		imageReference = pinned
+	}
 	authJSON, err := marshaledImagePullSecrets(imageReference, secrets)
 	if err != nil {
 		return nil, err
 	}
 
-	log := ctrllog.FromContext(ctx).WithValues("imageReference", imageReference)
-	hash := computeFNV128Hash(imageReference, authJSON)
-	if architectures, ok := c.imageRefsCache.Get(hash); ok && !skipCache {
+	hash := computeFNV128Hash(imageReference, operatingSystem, authJSON)
+	// The imported entries expire in the LRU lruTTL after the import: the addedAt field tracks their actual age.
+	if entry, ok := c.imageRefsCache.Get(hash); ok && !skipCache && !entry.expired() {
+		architectures := entry.architectures
 		log.V(3).Info("Cache hit", "architectures", architectures, "hash", hash)
+		trace.SpanFromContext(ctx).SetAttributes(cacheHitAttributeKey.Bool(true))
 		defer utils.HistogramObserve(now, metrics.TimeToInspectImageGivenHit)
 		return architectures, nil
 	}
-	architectures, err := c.registryInspector.GetCompatibleArchitecturesSet(ctx, imageReference, true, secrets)
+	architectures, err := c.registryInspector.GetCompatibleArchitecturesSet(ctx, imageReference, operatingSystem, true, secrets)
 	if err != nil {
 		return nil, err
 	}
 
-	log.V(3).Info("Cache miss...adding to cache", "architectures", architectures, "hash", hash)
-	if !skipCache {
-		c.imageRefsCache.Add(hash, architectures)
+	// When skipCache is true, the fresh result replaces the (possibly stale) cached entry.
+	if previous, ok := c.imageRefsCache.Peek(hash); ok && skipCache && !previous.architectures.Equal(architectures) {
+		log.V(2).Info("Invalidating the stale cache entry", "previousArchitectures", previous.architectures,
+			"hash", hash)
+		c.invalidations.Add(1)
 	}
+	log.V(3).Info("Cache miss or bypass...adding to cache", "architectures", architectures, "hash", hash,
+		"skipCache", skipCache)
+	c.imageRefsCache.Add(hash, cacheEntry{architectures: architectures, addedAt: time.Now(), ttl: c.entryTTL()})
 	defer utils.HistogramObserve(now, metrics.TimeToInspectImageGivenMiss)
 	return architectures, nil
 }
 
+// exportEntries returns the non-expired entries of the cache, from the oldest to the newest.
+func (c *cacheProxy) exportEntries() []CacheEntry {
+	entries := make([]CacheEntry, 0, c.imageRefsCache.Len())
+	for _, key := range c.imageRefsCache.Keys() {
+		entry, ok := c.imageRefsCache.Peek(key)
+		if !ok || entry.expired() {
+			continue
+		}
+		entries = append(entries, CacheEntry{
+			Key:           key,
+			Architectures: sets.List(entry.architectures),
+			AddedAt:       entry.addedAt,
+		})
+	}
+	return entries
+}
+
+// importEntries adds the non-expired entries to the cache, unless their keys are already cached, and returns the
+// number of entries added.
+func (c *cacheProxy) importEntries(entries []CacheEntry) int {
+	entries = slices.Clone(entries)
+	// Add the oldest entries first, so that they are the first ones to be evicted
+	slices.SortFunc(entries, func(a, b CacheEntry) int {
+		return a.AddedAt.Compare(b.AddedAt)
+	})
+	importedCount := 0
+	for _, entry := range entries {
+		imported := cacheEntry{
+			architectures: sets.New(entry.Architectures...),
+			addedAt:       entry.AddedAt,
+			// The imported entries get a new jittered TTL, so that the ones exported together do not expire together
+			ttl: c.entryTTL(),
+		}
+		if imported.expired() || c.imageRefsCache.Contains(entry.Key) {
+			continue
+		}
+		c.imageRefsCache.Add(entry.Key, imported)
+		importedCount++
+	}
+	return importedCount
+}
+
+// setJitterFraction sets the jitter fraction of the TTLs of the entries added to the cache. Values outside [0, 1)
+// restore the default.
+func (c *cacheProxy) setJitterFraction(jitterFraction float64) {
+	if jitterFraction < 0 || jitterFraction >= 1 {
+		jitterFraction = DefaultCacheJitterFraction
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.jitterFraction = jitterFraction
+}
+
+// entryTTL returns cacheTTL multiplied by 1 + random(-jitterFraction, +jitterFraction).
+func (c *cacheProxy) entryTTL() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	randFloat64 := c.randFloat64
+	if randFloat64 == nil {
+		randFloat64 = rand.Float64
+	}
+	return time.Duration(float64(cacheTTL) * (1 + c.jitterFraction*(2*randFloat64()-1)))
+}
+
+// setPinDigest enables or disables the pinning of the image references to their digest.
+func (c *cacheProxy) setPinDigest(pinDigest bool) {
+	c.pinDigest.Store(pinDigest)
+}
+
+// pinDigestEnabled returns true if the image references are pinned to their digest.
+func (c *cacheProxy) pinDigestEnabled() bool {
+	return c.pinDigest.Load()
+}
+
+// purge removes all the entries of the cache.
+func (c *cacheProxy) purge() {
+	c.imageRefsCache.Purge()
+}
+
+// invalidationsCount returns the number of cached entries that were found to be stale and replaced.
+func (c *cacheProxy) invalidationsCount() uint64 {
+	return c.invalidations.Load()
+}
+
 func (c *cacheProxy) GetRegistryInspector() IRegistryInspector {
 	return c.registryInspector
 }
@@ -73,14 +229,30 @@ func (c *cacheProxy) GetRegistryInspector() IRegistryInspector {
 func newCacheProxy() *cacheProxy {
 	return &cacheProxy{
 		registryInspector: newRegistryInspector(),
-		imageRefsCache:    expirable.NewLRU[string, sets.Set[string]](256, nil, time.Hour*6),
+		imageRefsCache:    expirable.NewLRU[string, cacheEntry](cacheSize, nil, lruTTL),
+		jitterFraction:    DefaultCacheJitterFraction,
 	}
 }
 
-func computeFNV128Hash(imageReference string, secrets []byte) string {
+// computeFNV128Hash returns the key of the cache entries. The operating system is only part of the key when it is
+// not linux, so that the keys of the linux entries are stable across the exports and imports of the cache.
+func computeFNV128Hash(imageReference string, operatingSystem string, secrets []byte) string {
 	hash := fnv.New128()
 	hash.Write([]byte(imageReference)) // Add the image reference
-	hash.Write(secrets)                // Add the secrets
+	if operatingSystem != utils.OSLinux {
+		hash.Write([]byte(operatingSystem)) // Add the operating system
+	}
+	hash.Write(secrets) // Add the secrets
 
 	return hex.EncodeToString(hash.Sum(nil))
 }
+
+// isDigested returns true if the image reference, which is expected to start with `//`, includes a digest.
+func isDigested(imageReference string) bool {
+	named, err := reference.ParseNormalizedNamed(strings.TrimPrefix(imageReference, "//"))
+	if err != nil {
+		return false
+	}
+	_, ok := named.(reference.Canonical)
+	return ok
+}
@@ -0,0 +1,284 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+func TestCacheProxy_ExportImportEntries(t *testing.T) {
+	now := time.Now()
+	source := &cacheProxy{imageRefsCache: expirable.NewLRU[string, cacheEntry](cacheSize, nil, cacheTTL)}
+	source.imageRefsCache.Add("old", cacheEntry{architectures: sets.New("amd64"), addedAt: now.Add(-2 * time.Hour)})
+	source.imageRefsCache.Add("new", cacheEntry{architectures: sets.New("arm64", "amd64"), addedAt: now})
+	source.imageRefsCache.Add("expired", cacheEntry{architectures: sets.New("s390x"), addedAt: now.Add(-cacheTTL)})
+
+	entries := source.exportEntries()
+	if len(entries) != 2 {
+		t.Fatalf("exportEntries() returned %d entries, want 2", len(entries))
+	}
+
+	target := &cacheProxy{imageRefsCache: expirable.NewLRU[string, cacheEntry](cacheSize, nil, cacheTTL)}
+	target.imageRefsCache.Add("new", cacheEntry{architectures: sets.New("ppc64le"), addedAt: now})
+	if imported := target.importEntries(append(entries, CacheEntry{Key: "expired", AddedAt: now.Add(-cacheTTL)})); imported != 1 {
+		t.Fatalf("importEntries() = %d, want 1", imported)
+	}
+	if entry, _ := target.imageRefsCache.Peek("old"); !entry.architectures.Equal(sets.New("amd64")) ||
+		!entry.addedAt.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("unexpected imported entry %+v", entry)
+	}
+	// the entries already cached are not overridden
+	if entry, _ := target.imageRefsCache.Peek("new"); !entry.architectures.Equal(sets.New("ppc64le")) {
+		t.Errorf("the cached entry was overridden: %+v", entry)
+	}
+}
+
+// stubRegistryInspector returns the architectures it holds, as a registry whose image is being updated would.
+type stubRegistryInspector struct {
+	IRegistryInspector
+	architectures sets.Set[string]
+	// digests maps the tagged image references to the references pinned to their digest
+	digests map[string]string
+	// inspected are the image references inspected, in order
+	inspected []string
+}
+
+func (s *stubRegistryInspector) GetCompatibleArchitecturesSet(_ context.Context, imageReference string, _ string,
+	_ bool, _ [][]byte) (sets.Set[string], error) {
+	s.inspected = append(s.inspected, imageReference)
+	return s.architectures, nil
+}
+
+func (s *stubRegistryInspector) resolveDigest(_ context.Context, imageReference string, _ [][]byte) (string, error) {
+	pinned, ok := s.digests[imageReference]
+	if !ok {
+		return "", errors.New("manifest unknown")
+	}
+	return pinned, nil
+}
+
+func TestCacheProxy_Invalidations(t *testing.T) {
+	inspector := &stubRegistryInspector{architectures: sets.New("amd64")}
+	c := &cacheProxy{
+		registryInspector: inspector,
+		imageRefsCache:    expirable.NewLRU[string, cacheEntry](cacheSize, nil, cacheTTL),
+	}
+	const imageReference = "//quay.io/foo/bar:latest"
+	for _, step := range []struct {
+		name              string
+		architectures     sets.Set[string]
+		skipCache         bool
+		want              sets.Set[string]
+		wantInvalidations uint64
+	}{
+		{name: "cache miss", architectures: sets.New("amd64"), want: sets.New("amd64")},
+		{name: "cache hit of a stale entry", architectures: sets.New("amd64", "arm64"), want: sets.New("amd64")},
+		{name: "bypass with an unchanged result", architectures: sets.New("amd64"), skipCache: true,
+			want: sets.New("amd64")},
+		{name: "bypass replacing the stale entry", architectures: sets.New("amd64", "arm64"), skipCache: true,
+			want: sets.New("amd64", "arm64"), wantInvalidations: 1},
+		{name: "cache hit of the refreshed entry", architectures: sets.New("s390x"),
+			want: sets.New("amd64", "arm64"), wantInvalidations: 1},
+	} {
+		inspector.architectures = step.architectures
+		got, err := c.GetCompatibleArchitecturesSet(context.TODO(), imageReference, utils.OSLinux, step.skipCache, nil)
+		if err != nil {
+			t.Fatalf("%s: GetCompatibleArchitecturesSet() error = %v", step.name, err)
+		}
+		if !got.Equal(step.want) {
+			t.Errorf("%s: GetCompatibleArchitecturesSet() = %v, want %v", step.name, sets.List(got), sets.List(step.want))
+		}
+		if invalidations := c.invalidationsCount(); invalidations != step.wantInvalidations {
+			t.Errorf("%s: invalidationsCount() = %d, want %d", step.name, invalidations, step.wantInvalidations)
+		}
+	}
+	c.purge()
+	if c.imageRefsCache.Len() != 0 {
+		t.Errorf("purge() left %d entries in the cache", c.imageRefsCache.Len())
+	}
+}
+
+func TestCacheProxy_EntryTTLJitter(t *testing.T) {
+	tests := []struct {
+		name           string
+		jitterFraction float64
+		random         float64
+		want           time.Duration
+	}{
+		{name: "lowest TTL", jitterFraction: 0.2, random: 0, want: cacheTTL * 8 / 10},
+		{name: "no jitter at the middle of the range", jitterFraction: 0.2, random: 0.5, want: cacheTTL},
+		{name: "positive jitter", jitterFraction: 0.2, random: 0.75, want: cacheTTL * 11 / 10},
+		{name: "jitter disabled", jitterFraction: 0, random: 0.9, want: cacheTTL},
+		{name: "invalid jitter fraction", jitterFraction: 1.5, random: 0, want: cacheTTL * 8 / 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cacheProxy{
+				registryInspector: &stubRegistryInspector{architectures: sets.New("amd64")},
+				imageRefsCache:    expirable.NewLRU[string, cacheEntry](cacheSize, nil, lruTTL),
+				randFloat64:       func() float64 { return tt.random },
+			}
+			c.setJitterFraction(tt.jitterFraction)
+			if _, err := c.GetCompatibleArchitecturesSet(context.TODO(), "//quay.io/foo/bar:latest", utils.OSLinux,
+				false, nil); err != nil {
+				t.Fatalf("GetCompatibleArchitecturesSet() error = %v", err)
+			}
+			entry, ok := c.imageRefsCache.Peek(c.imageRefsCache.Keys()[0])
+			if !ok {
+				t.Fatal("the entry was not cached")
+			}
+			if entry.ttl != tt.want {
+				t.Errorf("the TTL of the entry = %s, want %s", entry.ttl, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheProxy_EntryTTLJitterRange(t *testing.T) {
+	c := &cacheProxy{imageRefsCache: expirable.NewLRU[string, cacheEntry](cacheSize, nil, lruTTL)}
+	c.setJitterFraction(0.2)
+	minTTL, maxTTL := cacheTTL*8/10, cacheTTL*12/10
+	ttls := sets.New[time.Duration]()
+	for i := 0; i < 1000; i++ {
+		ttl := c.entryTTL()
+		if ttl < minTTL || ttl >= maxTTL {
+			t.Fatalf("entryTTL() = %s, want a TTL in [%s, %s)", ttl, minTTL, maxTTL)
+		}
+		ttls.Insert(ttl)
+	}
+	if ttls.Len() < 2 {
+		t.Errorf("entryTTL() returned the same TTL %d times", 1000)
+	}
+}
+
+func TestCacheEntry_Expired(t *testing.T) {
+	now := time.Now()
+	for _, tt := range []struct {
+		name  string
+		entry cacheEntry
+		want  bool
+	}{
+		{name: "entry younger than its TTL", entry: cacheEntry{addedAt: now.Add(-time.Hour), ttl: 2 * time.Hour}},
+		{name: "entry older than its TTL", entry: cacheEntry{addedAt: now.Add(-3 * time.Hour), ttl: 2 * time.Hour},
+			want: true},
+		{name: "entry without TTL younger than cacheTTL", entry: cacheEntry{addedAt: now.Add(-time.Hour)}},
+		{name: "entry without TTL older than cacheTTL", entry: cacheEntry{addedAt: now.Add(-cacheTTL)}, want: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.expired(); got != tt.want {
+				t.Errorf("expired() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheProxy_PinDigest(t *testing.T) {
+	const (
+		taggedReference  = "//quay.io/foo/bar:latest"
+		multiArchDigest  = "//quay.io/foo/bar@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		singleArchDigest = "//quay.io/foo/bar@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	inspector := &stubRegistryInspector{
+		architectures: sets.New("amd64", "arm64"),
+		digests:       map[string]string{taggedReference: multiArchDigest},
+	}
+	c := &cacheProxy{
+		registryInspector: inspector,
+		imageRefsCache:    expirable.NewLRU[string, cacheEntry](cacheSize, nil, cacheTTL),
+	}
+	c.setPinDigest(true)
+	cacheKey := func(imageReference string) string {
+		authJSON, err := marshaledImagePullSecrets(imageReference, nil)
+		if err != nil {
+			t.Fatalf("marshaledImagePullSecrets() error = %v", err)
+		}
+		return computeFNV128Hash(imageReference, utils.OSLinux, authJSON)
+	}
+	for _, step := range []struct {
+		name           string
+		imageReference string
+		architectures  sets.Set[string]
+		digest         string
+		want           sets.Set[string]
+		wantInspected  []string
+	}{
+		{name: "the tag is resolved to the digest inspected", imageReference: taggedReference,
+			architectures: sets.New("amd64", "arm64"), digest: multiArchDigest, want: sets.New("amd64", "arm64"),
+			wantInspected: []string{multiArchDigest}},
+		{name: "the digest hits the entry of the tag", imageReference: multiArchDigest,
+			architectures: sets.New("s390x"), digest: multiArchDigest, want: sets.New("amd64", "arm64"),
+			wantInspected: []string{multiArchDigest}},
+		{name: "the tag updated to a single-arch image misses the cache", imageReference: taggedReference,
+			architectures: sets.New("amd64"), digest: singleArchDigest, want: sets.New("amd64"),
+			wantInspected: []string{multiArchDigest, singleArchDigest}},
+		{name: "the tag hits the entry of its new digest", imageReference: taggedReference,
+			architectures: sets.New("s390x"), digest: singleArchDigest, want: sets.New("amd64"),
+			wantInspected: []string{multiArchDigest, singleArchDigest}},
+	} {
+		inspector.architectures = step.architectures
+		inspector.digests[taggedReference] = step.digest
+		got, err := c.GetCompatibleArchitecturesSet(context.TODO(), step.imageReference, utils.OSLinux, false, nil)
+		if err != nil {
+			t.Fatalf("%s: GetCompatibleArchitecturesSet() error = %v", step.name, err)
+		}
+		if !got.Equal(step.want) {
+			t.Errorf("%s: GetCompatibleArchitecturesSet() = %v, want %v", step.name, sets.List(got), sets.List(step.want))
+		}
+		if !slices.Equal(inspector.inspected, step.wantInspected) {
+			t.Errorf("%s: inspected %v, want %v", step.name, inspector.inspected, step.wantInspected)
+		}
+	}
+	for _, key := range []string{cacheKey(multiArchDigest), cacheKey(singleArchDigest)} {
+		if !c.imageRefsCache.Contains(key) {
+			t.Errorf("the cache has no entry keyed by the digest %s", key)
+		}
+	}
+	if c.imageRefsCache.Contains(cacheKey(taggedReference)) {
+		t.Errorf("the cache has an entry keyed by the tag")
+	}
+
+	// The errors resolving the digest are returned without inspecting the image
+	inspector.inspected = nil
+	if _, err := c.GetCompatibleArchitecturesSet(context.TODO(), "//quay.io/foo/unknown:latest", utils.OSLinux,
+		false, nil); err == nil {
+		t.Errorf("GetCompatibleArchitecturesSet() of an unresolvable tag did not fail")
+	}
+	if len(inspector.inspected) != 0 {
+		t.Errorf("the unresolvable tag was inspected")
+	}
+
+	// Without pinning, the entries are keyed by tag
+	c.setPinDigest(false)
+	if _, err := c.GetCompatibleArchitecturesSet(context.TODO(), taggedReference, utils.OSLinux, false, nil); err != nil {
+		t.Fatalf("GetCompatibleArchitecturesSet() error = %v", err)
+	}
+	if !slices.Equal(inspector.inspected, []string{taggedReference}) || !c.imageRefsCache.Contains(cacheKey(taggedReference)) {
+		t.Errorf("the tag was not inspected and cached as is: inspected %v", inspector.inspected)
+	}
+}
+
+func TestIsDigested(t *testing.T) {
+	tests := []struct {
+		imageReference string
+		want           bool
+	}{
+		{imageReference: "//quay.io/foo/bar:latest", want: false},
+		{imageReference: "//quay.io/foo/bar", want: false},
+		{imageReference: "//nginx", want: false},
+		{imageReference: "//quay.io/foo/bar@sha256:1111111111111111111111111111111111111111111111111111111111111111", want: true},
+		{imageReference: "//quay.io/foo/bar:latest@sha256:1111111111111111111111111111111111111111111111111111111111111111", want: true},
+		{imageReference: "//Invalid Reference", want: false},
+	}
+	for _, tt := range tests {
+		if got := isDigested(tt.imageReference); got != tt.want {
+			t.Errorf("isDigested(%q) = %v, want %v", tt.imageReference, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/image/metrics"
+)
+
+const (
+	DefaultCircuitBreakerFailureThreshold = 5
+	DefaultCircuitBreakerResetTimeout     = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned, without inspecting the image, when the circuit breaker of the registry is open.
+var ErrCircuitOpen = errors.New("the circuit breaker of the registry is open: skipping the image inspection")
+
+type circuitState int
+
+// The values of the circuit states are the ones exposed by the metrics.CircuitBreakerState gauge.
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreakerPolicy configures the circuit breakers of the registries.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive transient errors that opens the circuit of a registry.
+	// Zero disables the circuit breakers.
+	FailureThreshold int
+	// ResetTimeout is the time after which an open circuit lets a single inspection through to probe the registry.
+	ResetTimeout time.Duration
+}
+
+// DefaultCircuitBreakerPolicy returns the CircuitBreakerPolicy used when none is configured.
+func DefaultCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return CircuitBreakerPolicy{
+		FailureThreshold: DefaultCircuitBreakerFailureThreshold,
+		ResetTimeout:     DefaultCircuitBreakerResetTimeout,
+	}
+}
+
+// circuitBreaker tracks the consecutive transient errors of a registry domain.
+// When they reach the FailureThreshold, the circuit opens and the inspections are skipped.
+// After the ResetTimeout, the circuit is half-open: a single inspection is let through and its result
+// closes the circuit or opens it again.
+type circuitBreaker struct {
+	domain              string
+	policy              CircuitBreakerPolicy
+	clock               clock.PassiveClock
+	mutex               sync.Mutex
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+}
+
+// allow returns true if the inspection of an image in the registry can proceed.
+func (cb *circuitBreaker) allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	switch cb.state {
+	case circuitOpen:
+		if cb.clock.Since(cb.openedAt) < cb.policy.ResetTimeout {
+			return false
+		}
+		cb.setState(circuitHalfOpen)
+		return true
+	case circuitHalfOpen:
+		// the probe is still in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// recordResult updates the state of the circuit given the error of an inspection allowed by allow.
+// Only the transient errors count as failures: the other ones are not a symptom of a failing registry.
+func (cb *circuitBreaker) recordResult(err error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if err == nil || !isRetryableError(err) {
+		cb.consecutiveFailures = 0
+		cb.setState(circuitClosed)
+		return
+	}
+	cb.consecutiveFailures++
+	if cb.state == circuitHalfOpen || cb.consecutiveFailures >= cb.policy.FailureThreshold {
+		cb.openedAt = cb.clock.Now()
+		cb.setState(circuitOpen)
+	}
+}
+
+func (cb *circuitBreaker) setState(state circuitState) {
+	cb.state = state
+	metrics.CircuitBreakerState.WithLabelValues(cb.domain).Set(float64(state))
+}
+
+func newCircuitBreaker(domain string, policy CircuitBreakerPolicy, clock clock.PassiveClock) *circuitBreaker {
+	metrics.InitCommonMetrics()
+	cb := &circuitBreaker{
+		domain: domain,
+		policy: policy,
+		clock:  clock,
+	}
+	cb.setState(circuitClosed)
+	return cb
+}
@@ -18,9 +18,28 @@ package image
 
 import (
 	"context"
+	"maps"
+	"strings"
 	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+
+	"github.com/containers/image/v5/docker/reference"
+)
+
+const (
+	// TracerName is the name of the tracer used to instrument the image inspection.
+	TracerName = "github.com/openshift/multiarch-tuning-operator/pkg/image"
+
+	registryDomainAttributeKey = attribute.Key("registry.domain")
+	imageReferenceAttributeKey = attribute.Key("image.reference")
+	cacheHitAttributeKey       = attribute.Key("cache.hit")
 )
 
 var (
@@ -30,29 +49,187 @@ var (
 )
 
 type Facade struct {
-	inspectionCache       ICache
-	storeGlobalPullSecret func(pullSecret []byte)
+	inspectionCache        ICache
+	storeGlobalPullSecret  func(pullSecret []byte)
+	setRetryPolicy         func(retryPolicy RetryPolicy)
+	setCacheJitterFraction func(jitterFraction float64)
+	setPinDigestInCache    func(pinDigest bool)
+	pinDigestInCache       func() bool
+	exportCacheEntries     func() []CacheEntry
+	importCacheEntries     func(entries []CacheEntry) int
+	purgeCache             func()
+	cacheInvalidations     func() uint64
+	tracer                 trace.Tracer
+	clock                  clock.PassiveClock
+	// mutex protects the circuitBreakerPolicy, circuitBreakers and registryTimeouts fields
+	mutex                sync.Mutex
+	circuitBreakerPolicy CircuitBreakerPolicy
+	// circuitBreakers maps the registry domains to their circuit breaker
+	circuitBreakers  map[string]*circuitBreaker
+	registryTimeouts RegistryTimeouts
 }
 
-func (i *Facade) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, skipCache bool, secrets [][]byte) (architectures sets.Set[string], err error) {
-	return i.inspectionCache.GetCompatibleArchitecturesSet(ctx, imageReference, skipCache, secrets)
+// GetCompatibleArchitecturesSet wraps the inspection of the image in a child span of the one in ctx, if any.
+// The span reports the image reference, the registry domain, whether the result came from the cache and the
+// error of the inspection, if any.
+// The inspection is skipped, and ErrCircuitOpen is returned, while the circuit breaker of the registry is open.
+// If a timeout is configured for the registry, the inspection is canceled when it expires; the deadline of ctx,
+// if any, still applies when it is earlier.
+func (i *Facade) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, operatingSystem string,
+	skipCache bool, secrets [][]byte) (architectures sets.Set[string], err error) {
+	domain := registryDomain(imageReference)
+	ctx, span := i.tracer.Start(ctx, "GetCompatibleArchitecturesSet", trace.WithAttributes(
+		imageReferenceAttributeKey.String(imageReference),
+		registryDomainAttributeKey.String(domain),
+		// the cache proxy overrides this attribute on cache hits
+		cacheHitAttributeKey.Bool(false),
+	))
+	defer span.End()
+	if timeout := i.getRegistryTimeout(domain); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cb := i.getCircuitBreaker(domain)
+	if cb != nil && !cb.allow() {
+		err = ErrCircuitOpen
+	} else {
+		architectures, err = i.inspectionCache.GetCompatibleArchitecturesSet(ctx, imageReference, operatingSystem, skipCache, secrets)
+		if cb != nil {
+			cb.recordResult(err)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return architectures, err
 }
 
 func (i *Facade) StoreGlobalPullSecret(pullSecret []byte) {
 	i.storeGlobalPullSecret(pullSecret)
 }
 
-func newImageFacade() *Facade {
+// SetCircuitBreakerPolicy sets the CircuitBreakerPolicy of the registries and resets their circuit breakers.
+func (i *Facade) SetCircuitBreakerPolicy(policy CircuitBreakerPolicy) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.circuitBreakerPolicy = policy
+	i.circuitBreakers = make(map[string]*circuitBreaker)
+}
+
+// getCircuitBreaker returns the circuit breaker of the registry domain, or nil if the circuit breakers are disabled.
+func (i *Facade) getCircuitBreaker(domain string) *circuitBreaker {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if i.circuitBreakerPolicy.FailureThreshold <= 0 {
+		return nil
+	}
+	cb, ok := i.circuitBreakers[domain]
+	if !ok {
+		cb = newCircuitBreaker(domain, i.circuitBreakerPolicy, i.clock)
+		i.circuitBreakers[domain] = cb
+	}
+	return cb
+}
+
+// SetRegistryTimeouts sets the timeouts of the inspections of the images hosted by the given registry domains.
+// The inspections of the images hosted by the other registries are only bound by the deadline of their context.
+func (i *Facade) SetRegistryTimeouts(timeouts RegistryTimeouts) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.registryTimeouts = maps.Clone(timeouts)
+}
+
+// getRegistryTimeout returns the timeout of the inspections of the images hosted by the registry domain, or zero if
+// none is configured.
+func (i *Facade) getRegistryTimeout(domain string) time.Duration {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.registryTimeouts[domain]
+}
+
+// ExportCacheEntries returns the non-expired entries of the inspection cache, from the oldest to the newest.
+func (i *Facade) ExportCacheEntries() []CacheEntry {
+	return i.exportCacheEntries()
+}
+
+// ImportCacheEntries adds the non-expired entries to the inspection cache, unless their keys are already cached,
+// and returns the number of entries added.
+func (i *Facade) ImportCacheEntries(entries []CacheEntry) int {
+	return i.importCacheEntries(entries)
+}
+
+// PurgeCache removes all the entries of the inspection cache.
+func (i *Facade) PurgeCache() {
+	i.purgeCache()
+}
+
+// CacheInvalidations returns the number of cached entries that a bypass of the cache found to be stale and replaced
+// since the start of the process.
+func (i *Facade) CacheInvalidations() uint64 {
+	return i.cacheInvalidations()
+}
+
+// SetRetryPolicy sets the RetryPolicy used to retry the manifest fetches failing with transient errors.
+func (i *Facade) SetRetryPolicy(retryPolicy RetryPolicy) {
+	i.setRetryPolicy(retryPolicy)
+}
+
+// SetCacheJitterFraction sets the fraction of the TTL of the inspection cache entries that is randomly added or
+// subtracted to it, so that the entries added together do not expire together. Values outside [0, 1) restore the
+// default.
+func (i *Facade) SetCacheJitterFraction(jitterFraction float64) {
+	i.setCacheJitterFraction(jitterFraction)
+}
+
+// SetPinDigestInCache enables the resolution of the tags of the images to their digest before their inspection. The
+// inspection cache is then keyed by digest: the images referenced by the same digest share their entry, and a tag
+// updated, e.g., from a multi-arch image to a single-arch one, does not hit the entry of the previous image.
+// Each lookup of a tagged image costs a request to its registry.
+func (i *Facade) SetPinDigestInCache(pinDigest bool) {
+	i.setPinDigestInCache(pinDigest)
+}
+
+// PinDigestInCache returns true if the tags of the images are resolved to their digest before their inspection.
+func (i *Facade) PinDigestInCache() bool {
+	return i.pinDigestInCache()
+}
+
+func newImageFacade(tracer trace.Tracer) *Facade {
 	inspectionCache := newCacheProxy()
 	return &Facade{
-		inspectionCache:       inspectionCache,
-		storeGlobalPullSecret: inspectionCache.registryInspector.storeGlobalPullSecret,
+		inspectionCache:        inspectionCache,
+		storeGlobalPullSecret:  inspectionCache.registryInspector.storeGlobalPullSecret,
+		setRetryPolicy:         inspectionCache.registryInspector.setRetryPolicy,
+		setCacheJitterFraction: inspectionCache.setJitterFraction,
+		setPinDigestInCache:    inspectionCache.setPinDigest,
+		pinDigestInCache:       inspectionCache.pinDigestEnabled,
+		exportCacheEntries:     inspectionCache.exportEntries,
+		importCacheEntries:     inspectionCache.importEntries,
+		purgeCache:             inspectionCache.purge,
+		cacheInvalidations:     inspectionCache.invalidationsCount,
+		tracer:                 tracer,
+		clock:                  clock.RealClock{},
+		circuitBreakerPolicy:   DefaultCircuitBreakerPolicy(),
+		circuitBreakers:        make(map[string]*circuitBreaker),
 	}
 }
 
+// FacadeSingleton returns the Facade singleton. Its tracer is provided by the global TracerProvider.
 func FacadeSingleton() *Facade {
 	once.Do(func() {
-		singletonImageFacade = newImageFacade()
+		singletonImageFacade = newImageFacade(otel.Tracer(TracerName))
 	})
 	return singletonImageFacade
 }
+
+// registryDomain returns the domain of the registry hosting the image, or an empty string if the image reference
+// cannot be parsed. The imageReference is expected to start with `//`, as required by docker.ParseReference.
+func registryDomain(imageReference string) string {
+	named, err := reference.ParseNormalizedNamed(strings.TrimPrefix(imageReference, "//"))
+	if err != nil {
+		return ""
+	}
+	return reference.Domain(named)
+}
@@ -0,0 +1,258 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/containers/image/v5/docker"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+type mockCache struct {
+	architectures sets.Set[string]
+	cacheHit      bool
+	err           error
+	calls         int
+}
+
+func (m *mockCache) GetCompatibleArchitecturesSet(ctx context.Context, _ string, _ string, _ bool, _ [][]byte) (sets.Set[string], error) {
+	m.calls++
+	if m.cacheHit {
+		trace.SpanFromContext(ctx).SetAttributes(cacheHitAttributeKey.Bool(true))
+	}
+	return m.architectures, m.err
+}
+
+func TestFacade_GetCompatibleArchitecturesSet_Tracing(t *testing.T) {
+	tests := []struct {
+		name           string
+		imageReference string
+		cache          *mockCache
+		wantDomain     string
+		wantCacheHit   bool
+		wantErr        bool
+	}{
+		{
+			name:           "cache miss",
+			imageReference: "//quay.io/foo/bar:latest",
+			cache:          &mockCache{architectures: sets.New("amd64", "arm64")},
+			wantDomain:     "quay.io",
+		},
+		{
+			name:           "cache hit",
+			imageReference: "//registry.example.com:5000/foo/bar:latest",
+			cache:          &mockCache{architectures: sets.New("amd64"), cacheHit: true},
+			wantDomain:     "registry.example.com:5000",
+			wantCacheHit:   true,
+		},
+		{
+			name:           "short name is normalized to docker.io",
+			imageReference: "//busybox:latest",
+			cache:          &mockCache{architectures: sets.New("amd64")},
+			wantDomain:     "docker.io",
+		},
+		{
+			name:           "inspection error",
+			imageReference: "//quay.io/foo/bar:latest",
+			cache:          &mockCache{err: errors.New("inspection failed")},
+			wantDomain:     "quay.io",
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			facade := &Facade{
+				inspectionCache: tt.cache,
+				tracer:          tp.Tracer(TracerName),
+			}
+			_, err := facade.GetCompatibleArchitecturesSet(context.Background(), tt.imageReference, utils.OSLinux, false, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCompatibleArchitecturesSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			attrs := attribute.NewSet(spans[0].Attributes()...)
+			if v, _ := attrs.Value(imageReferenceAttributeKey); v.AsString() != tt.imageReference {
+				t.Errorf("image.reference = %q, want %q", v.AsString(), tt.imageReference)
+			}
+			if v, _ := attrs.Value(registryDomainAttributeKey); v.AsString() != tt.wantDomain {
+				t.Errorf("registry.domain = %q, want %q", v.AsString(), tt.wantDomain)
+			}
+			if v, _ := attrs.Value(cacheHitAttributeKey); v.AsBool() != tt.wantCacheHit {
+				t.Errorf("cache.hit = %v, want %v", v.AsBool(), tt.wantCacheHit)
+			}
+			if tt.wantErr != (spans[0].Status().Code == codes.Error) {
+				t.Errorf("span status = %v, wantErr %v", spans[0].Status(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFacade_GetCompatibleArchitecturesSet_NoopTracer(t *testing.T) {
+	facade := &Facade{
+		inspectionCache: &mockCache{architectures: sets.New("amd64")},
+		tracer:          noop.NewTracerProvider().Tracer(TracerName),
+	}
+	got, err := facade.GetCompatibleArchitecturesSet(context.Background(), "//quay.io/foo/bar:latest", utils.OSLinux, false, nil)
+	if err != nil {
+		t.Fatalf("GetCompatibleArchitecturesSet() unexpected error = %v", err)
+	}
+	if !got.Equal(sets.New("amd64")) {
+		t.Errorf("GetCompatibleArchitecturesSet() = %v, want %v", sets.List(got), []string{"amd64"})
+	}
+}
+
+func TestFacade_GetCompatibleArchitecturesSet_CircuitBreaker(t *testing.T) {
+	const imageReference = "//quay.io/foo/bar:latest"
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	cache := &mockCache{err: docker.UnexpectedHTTPStatusError{StatusCode: 503}}
+	facade := &Facade{
+		inspectionCache: cache,
+		tracer:          noop.NewTracerProvider().Tracer(TracerName),
+		clock:           fakeClock,
+	}
+	facade.SetCircuitBreakerPolicy(CircuitBreakerPolicy{FailureThreshold: 3, ResetTimeout: time.Minute})
+	inspect := func() error {
+		_, err := facade.GetCompatibleArchitecturesSet(context.Background(), imageReference, utils.OSLinux, false, nil)
+		return err
+	}
+	assertState := func(want circuitState) {
+		t.Helper()
+		if got := facade.getCircuitBreaker("quay.io").state; got != want {
+			t.Fatalf("circuit state = %v, want %v", got, want)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := inspect(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("inspection %d: expected the registry error, got %v", i, err)
+		}
+	}
+	assertState(circuitOpen)
+
+	// The circuit is open: the inspections short-circuit without reaching the registry
+	if err := inspect(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if cache.calls != 3 {
+		t.Fatalf("expected 3 calls to the cache, got %d", cache.calls)
+	}
+	// The other registries are not affected
+	if _, err := facade.GetCompatibleArchitecturesSet(context.Background(), "//registry.example.com/foo/bar:latest",
+		utils.OSLinux, false, nil); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the inspection of other registries to proceed")
+	}
+
+	// After the reset timeout, the failing probe opens the circuit again
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	if err := inspect(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the probe to reach the registry, got %v", err)
+	}
+	assertState(circuitOpen)
+	if err := inspect(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// After the reset timeout, the successful probe closes the circuit
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	cache.err = nil
+	cache.architectures = sets.New("amd64")
+	if err := inspect(); err != nil {
+		t.Fatalf("unexpected error = %v", err)
+	}
+	assertState(circuitClosed)
+	if err := inspect(); err != nil {
+		t.Fatalf("unexpected error = %v", err)
+	}
+}
+
+func TestFacade_GetCompatibleArchitecturesSet_CircuitBreakerIgnoresTerminalErrors(t *testing.T) {
+	cache := &mockCache{err: docker.UnexpectedHTTPStatusError{StatusCode: 404}}
+	facade := &Facade{
+		inspectionCache: cache,
+		tracer:          noop.NewTracerProvider().Tracer(TracerName),
+		clock:           clocktesting.NewFakePassiveClock(time.Now()),
+	}
+	facade.SetCircuitBreakerPolicy(CircuitBreakerPolicy{FailureThreshold: 1, ResetTimeout: time.Minute})
+	for i := 0; i < 3; i++ {
+		if _, err := facade.GetCompatibleArchitecturesSet(context.Background(), "//quay.io/foo/bar:latest", utils.OSLinux,
+			false, nil); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("inspection %d: the terminal errors must not open the circuit", i)
+		}
+	}
+}
+
+// slowCache simulates a registry that answers after the delay, unless the context is done first.
+type slowCache struct {
+	delay time.Duration
+}
+
+func (s *slowCache) GetCompatibleArchitecturesSet(ctx context.Context, _ string, _ string, _ bool, _ [][]byte) (sets.Set[string], error) {
+	select {
+	case <-time.After(s.delay):
+		return sets.New("amd64"), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestFacade_GetCompatibleArchitecturesSet_RegistryTimeouts(t *testing.T) {
+	facade := &Facade{
+		inspectionCache: &slowCache{delay: 200 * time.Millisecond},
+		tracer:          noop.NewTracerProvider().Tracer(TracerName),
+		clock:           clocktesting.NewFakePassiveClock(time.Now()),
+	}
+	facade.SetRegistryTimeouts(RegistryTimeouts{
+		"slow.example.com":        20 * time.Millisecond,
+		"mirror.example.com:5000": time.Minute,
+	})
+	// The global timeout is the deadline of the context, which the slow registry does not exceed
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := facade.GetCompatibleArchitecturesSet(ctx, "//slow.example.com/foo/bar:latest", utils.OSLinux, false, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the per-registry timeout to be exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Fatalf("expected the inspection to be canceled by the per-registry timeout, it took %s", elapsed)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("expected the global timeout not to be exceeded")
+	}
+
+	for _, imageReference := range []string{
+		"//mirror.example.com:5000/foo/bar:latest",
+		// the registries without a per-registry timeout are only bound by the global timeout
+		"//quay.io/foo/bar:latest",
+	} {
+		if _, err := facade.GetCompatibleArchitecturesSet(ctx, imageReference, utils.OSLinux, false, nil); err != nil {
+			t.Fatalf("unexpected error inspecting %s: %v", imageReference, err)
+		}
+	}
+
+	// The earlier global timeout still applies to the registries with a per-registry timeout
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer shortCancel()
+	_, err = facade.GetCompatibleArchitecturesSet(shortCtx, "//mirror.example.com:5000/foo/bar:latest", utils.OSLinux, false, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the global timeout to be exceeded, got %v", err)
+	}
+}
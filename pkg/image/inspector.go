@@ -21,18 +21,20 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
 	"github.com/containers/image/v5/image"
 	"github.com/containers/image/v5/manifest"
-	"github.com/containers/image/v5/pkg/sysregistriesv2"
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/types"
 	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"golang.org/x/sys/unix"
 
@@ -41,26 +43,54 @@ import (
 
 const (
 	operatorSDKBuilderBundleAnnotation = "operators.operatorframework.io.metrics.builder"
+	// dockerReferenceTypeAnnotation is set by buildkit on the index entries of the attestation manifests
+	dockerReferenceTypeAnnotation    = "vnd.docker.reference.type"
+	attestationManifestReferenceType = "attestation-manifest"
+	unknownPlatformValue             = "unknown"
 )
 
+// architectureSynonyms maps the architecture names embedded by some toolchains in the image manifests, e.g., the
+// uname -m ones, to the GOARCH values the nodes report in the kubernetes.io/arch label. The arm synonyms imply the
+// v7 variant.
+var architectureSynonyms = map[string]string{
+	"x86_64":      utils.ArchitectureAmd64,
+	"x86-64":      utils.ArchitectureAmd64,
+	"aarch64":     utils.ArchitectureArm64,
+	"armhfp":      utils.ArchitectureArmV7,
+	"armhf":       utils.ArchitectureArmV7,
+	"armv7l":      utils.ArchitectureArmV7,
+	"ppc64el":     utils.ArchitecturePpc64le,
+	"powerpc64le": utils.ArchitecturePpc64le,
+}
+
 type registryInspector struct {
 	globalPullSecret []byte
-	// mutex is used to protect the globalPullSecret field of the singletonImageFacade from concurrent write access
+	retryPolicy      RetryPolicy
+	// mutex is used to protect the globalPullSecret and retryPolicy fields of the singletonImageFacade from
+	// concurrent write access
 	mutex sync.RWMutex
 }
 
 // GetCompatibleArchitecturesSet returns the set of compatibles architectures given an imageReference and a list of secrets.
 // It uses the containers/image library to get the manifest of the image and extract the architecture from it.
+// Only the platforms with the given operating system are considered (linux if empty).
 // If the image is a manifest list, it will return the set of architectures supported by the manifest list.
-// If the image is a manifest, it will return the architecture set in the manifest's config.
+// If the image is a manifest, it will return the architecture set in the manifest's config, or an empty set if the
+// operating system in the config does not match.
 // If the image is an operator bundle image, it will return an empty set. This is because operator bundle images
 // are not tied to a specific architecture, and we should not set any constraints based on the architecture they report.
-func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, _ bool, secrets [][]byte) (supportedArchitectures sets.Set[string], err error) {
+func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, operatingSystem string,
+	_ bool, secrets [][]byte) (supportedArchitectures sets.Set[string], err error) {
+	if operatingSystem == "" {
+		operatingSystem = utils.OSLinux
+	}
 	// Create the auth file
-	log := ctrllog.FromContext(ctx, "imageReference", imageReference)
+	log := ctrllog.FromContext(ctx, "imageReference", imageReference, "operatingSystem", operatingSystem)
 	i.mutex.RLock()
 	globalPullSecret := i.globalPullSecret
+	retryPolicy := i.retryPolicy
 	i.mutex.RUnlock()
+	// The global pull secret comes first: the credentials of the pods' pull secrets take precedence on the same registries.
 	authFile, err := i.createAuthFile(imageReference, append([][]byte{globalPullSecret}, secrets...)...)
 	if err != nil {
 		log.Error(err, "Couldn't write auth file")
@@ -75,7 +105,7 @@ func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, i
 	// Invalidate registry cache before calling image APIs to catch updates to registry configurations.
 	// TODO: watch ICSP/IDMS/ITMS for changes or alternatively invalidate only on MCP updates rather
 	// than do this everytime
-	sysregistriesv2.InvalidateCache()
+	invalidateRegistriesCache()
 
 	// Check if the image is a manifest list
 	ref, err := docker.ParseReference(imageReference)
@@ -83,16 +113,22 @@ func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, i
 		log.Error(err, "Error parsing the image reference for the image")
 		return nil, err
 	}
-	sys := &types.SystemContext{
-		AuthFilePath:                authFile.Name(),
-		SystemRegistriesConfPath:    RegistriesConfPath(),
-		SystemRegistriesConfDirPath: RegistryCertsDir(),
-		SignaturePolicyPath:         PolicyConfPath(),
-		DockerPerHostCertDirPath:    DockerCertsDir(),
-	}
-	src, err := ref.NewImageSource(ctx, sys)
+	sys := newSystemContext(authFile.Name())
+	var (
+		src          types.ImageSource
+		rawManifest  []byte
+		manifestType string
+	)
+	// Creating the image source fetches the manifest too: both are retried on transient errors.
+	err = retryPolicy.retry(ctx, func() error {
+		src, rawManifest, manifestType, err = fetchManifest(ctx, ref, sys)
+		if err != nil {
+			log.V(3).Info("Error fetching the image manifest", "error", err)
+		}
+		return err
+	})
 	if err != nil {
-		log.Error(err, "Error creating the image source")
+		log.Error(err, "Error getting the image manifest")
 		return nil, err
 	}
 	defer func(src types.ImageSource) {
@@ -101,11 +137,6 @@ func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, i
 			log.Error(err, "Error closing the image source for the image")
 		}
 	}(src)
-	rawManifest, _, err := src.GetManifest(ctx, nil)
-	if err != nil {
-		log.Error(err, "Error getting the image manifest: %v")
-		return nil, err
-	}
 	policy, err := signature.DefaultPolicy(sys)
 	if err != nil {
 		log.Error(err, "Error loading the systemContext's policy")
@@ -119,22 +150,50 @@ func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, i
 
 	supportedArchitectures = sets.New[string]()
 	var instanceDigest *digest.Digest = nil
-	if manifest.MIMETypeIsMultiImage(manifest.GuessMIMEType(rawManifest)) {
+	// Some registries report an unreliable Content-Type (e.g., text/plain): the MIME type is also guessed from the
+	// manifest content, which recognizes the OCI image indexes without the (optional) mediaType field.
+	isMultiImage := manifest.MIMETypeIsMultiImage(manifest.NormalizedMIMEType(manifestType)) ||
+		manifest.MIMETypeIsMultiImage(manifest.GuessMIMEType(rawManifest))
+	if isMultiImage {
 		index, err := manifest.OCI1IndexFromManifest(rawManifest)
 		if err != nil {
 			log.Error(err, "Error parsing the OCI index from the raw manifest of the image")
 			return nil, err
 		}
-		for _, m := range index.Manifests {
-			supportedArchitectures = sets.Insert(supportedArchitectures, m.Platform.Architecture)
+		hasPlatformManifests := false
+		for i, m := range index.Manifests {
+			if !isPlatformManifest(m) {
+				log.V(4).Info("Skipping the index entry not describing an image for a platform", "digest", m.Digest,
+					"artifactType", m.ArtifactType)
+				continue
+			}
+			hasPlatformManifests = true
+			architecture, ok := compatibleArchitecture(m.Platform.OS, m.Platform.Architecture, m.Platform.Variant,
+				operatingSystem)
+			if !ok {
+				continue
+			}
+			supportedArchitectures = sets.Insert(supportedArchitectures, architecture)
+			if instanceDigest == nil {
+				instanceDigest = &index.Manifests[i].Digest
+			}
+		}
+		if !hasPlatformManifests {
+			err = fmt.Errorf("the index of the image %s has no manifest for any platform", imageReference)
+			log.Error(err, "Error parsing the OCI index from the raw manifest of the image")
+			return nil, err
+		}
+		if instanceDigest == nil {
+			// The image does not support any platform with the given operating system
+			log.V(3).Info("The index of the image has no manifest for the operating system")
+			return supportedArchitectures, nil
 		}
 		// In the case of non-manifest-list images, we will not execute this code path and the instanceDigest will be nil.
 		// The architecture will be only one, i.e., the one from the config object of the single manifest.
-		// In the case of manifest-list images, we will get the first manifest and check the config object for the operator-sdk label.
-		// The set of architectures will be the union of the architectures of all the manifests in the index and computed later.
+		// In the case of manifest-list images, we get the first platform manifest and check the config object for the operator-sdk label.
+		// The set of architectures will be the union of the architectures of all the platform manifests in the index, computed above.
 		// In this way, we can avoid the library from looking for the manifest that matches the architecture of the node where this
 		// code is running. That would lead to a failure if the node architecture is not present in the list of architectures of the image.
-		instanceDigest = &index.Manifests[0].Digest
 	}
 
 	unparsedImage := image.UnparsedInstance(src, instanceDigest)
@@ -175,13 +234,151 @@ func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, i
 		return utils.AllSupportedArchitecturesSet(), nil
 	}
 
-	if !manifest.MIMETypeIsMultiImage(manifest.GuessMIMEType(rawManifest)) {
+	if !isMultiImage {
 		log.V(3).Info("The image is not a manifest list... getting the supported architecture")
-		return sets.New[string](config.Architecture), nil
+		architecture, ok := compatibleArchitecture(config.OS, config.Architecture, config.Variant, operatingSystem)
+		if !ok {
+			log.V(3).Info("The image does not support the operating system", "imageOS", config.OS)
+			return sets.New[string](), nil
+		}
+		return sets.New[string](architecture), nil
 	}
 	return supportedArchitectures, nil
 }
 
+// resolveDigest returns the image reference pinned to the digest of the manifest the imageReference resolves to,
+// e.g., //quay.io/foo/bar@sha256:..., so that the inspection of the returned reference is not affected by a later
+// update of the tag.
+func (i *registryInspector) resolveDigest(ctx context.Context, imageReference string, secrets [][]byte) (string, error) {
+	log := ctrllog.FromContext(ctx, "imageReference", imageReference)
+	i.mutex.RLock()
+	globalPullSecret := i.globalPullSecret
+	retryPolicy := i.retryPolicy
+	i.mutex.RUnlock()
+	authFile, err := i.createAuthFile(imageReference, append([][]byte{globalPullSecret}, secrets...)...)
+	if err != nil {
+		log.Error(err, "Couldn't write auth file")
+		return "", err
+	}
+	defer func(f *os.File) {
+		if err := f.Close(); err != nil {
+			log.Error(err, "Failed to close auth file", "filename", f.Name())
+		}
+	}(authFile)
+	invalidateRegistriesCache()
+	ref, err := docker.ParseReference(imageReference)
+	if err != nil {
+		log.Error(err, "Error parsing the image reference for the image")
+		return "", err
+	}
+	var rawManifest []byte
+	// The manifest is fetched through an image source, rather than with a HEAD request, so that the mirrors of the
+	// registries are honoured and the registries not reporting the Docker-Content-Digest header are supported.
+	err = retryPolicy.retry(ctx, func() error {
+		var src types.ImageSource
+		src, rawManifest, _, err = fetchManifest(ctx, ref, newSystemContext(authFile.Name()))
+		if err != nil {
+			log.V(3).Info("Error fetching the image manifest", "error", err)
+			return err
+		}
+		if err := src.Close(); err != nil {
+			log.Error(err, "Error closing the image source for the image")
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(err, "Error getting the image manifest")
+		return "", err
+	}
+	manifestDigest, err := manifest.Digest(rawManifest)
+	if err != nil {
+		log.Error(err, "Error computing the digest of the image manifest")
+		return "", err
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(ref.DockerReference()), manifestDigest)
+	if err != nil {
+		return "", err
+	}
+	return "//" + pinned.String(), nil
+}
+
+// newSystemContext returns the SystemContext of the inspections using the given auth file.
+func newSystemContext(authFilePath string) *types.SystemContext {
+	return &types.SystemContext{
+		AuthFilePath:                authFilePath,
+		SystemRegistriesConfPath:    RegistriesConfPath(),
+		SystemRegistriesConfDirPath: RegistryCertsDir(),
+		SignaturePolicyPath:         PolicyConfPath(),
+		DockerPerHostCertDirPath:    DockerCertsDir(),
+	}
+}
+
+// fetchManifest creates the image source for ref and gets its manifest and the MIME type reported by the registry.
+// The caller must close the returned image source when no error is returned.
+func fetchManifest(ctx context.Context, ref types.ImageReference, sys *types.SystemContext) (types.ImageSource, []byte, string, error) {
+	src, err := ref.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	rawManifest, mimeType, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		_ = src.Close()
+		return nil, nil, "", err
+	}
+	return src, rawManifest, mimeType, nil
+}
+
+// platformOS returns the operating system of a platform, defaulting to linux when it is not set.
+func platformOS(operatingSystem string) string {
+	if operatingSystem == "" {
+		return utils.OSLinux
+	}
+	return operatingSystem
+}
+
+// compatibleArchitecture returns the architecture of a platform, qualified by its variant, and whether the platform
+// is compatible with the given operating system. The WebAssembly platforms are compatible with linux and report the
+// utils.ArchitectureWasm32 architecture, whatever the architecture they declare (e.g., wasm).
+func compatibleArchitecture(imageOS, architecture, variant, operatingSystem string) (string, bool) {
+	switch imageOS := platformOS(imageOS); {
+	case imageOS == operatingSystem:
+		architecture = normalizeArchitecture(architecture)
+		if variant != "" {
+			// The variant of the platform takes precedence on the one implied by the synonym
+			architecture = utils.ArchitectureWithoutVariant(architecture)
+		}
+		return utils.PlatformArchitecture(architecture, variant), true
+	case (imageOS == utils.OSWasip1 || imageOS == utils.OSWasm) && operatingSystem == utils.OSLinux:
+		return utils.ArchitectureWasm32, true
+	default:
+		return "", false
+	}
+}
+
+// normalizeArchitecture returns the GOARCH value of the architecture, mapping the known synonyms (e.g., x86_64 or
+// aarch64) to their canonical value, qualified by the variant they imply, if any. The other architectures are
+// returned as they are.
+func normalizeArchitecture(architecture string) string {
+	if canonical, ok := architectureSynonyms[strings.ToLower(architecture)]; ok {
+		return canonical
+	}
+	return architecture
+}
+
+// isPlatformManifest returns whether the index entry describes the image for a platform.
+// OCI image index v1.1 entries can also reference artifacts without a platform, and buildkit stores the attestation
+// manifests in the index with the unknown/unknown platform: they must not contribute to the supported architectures.
+func isPlatformManifest(m imgspecv1.Descriptor) bool {
+	if m.Platform == nil || m.Platform.Architecture == "" || m.Platform.Architecture == unknownPlatformValue {
+		return false
+	}
+	if m.Annotations[dockerReferenceTypeAnnotation] == attestationManifestReferenceType {
+		return false
+	}
+	// The artifactType is only set for the entries referencing artifacts, not images
+	return m.ArtifactType == ""
+}
+
 func (i *registryInspector) createAuthFile(imageReference string, secrets ...[]byte) (*os.File, error) {
 	authJSON, err := marshaledImagePullSecrets(imageReference, secrets)
 	if err != nil {
@@ -258,7 +455,15 @@ func (i *registryInspector) storeGlobalPullSecret(pullSecret []byte) {
 	i.globalPullSecret = pullSecret
 }
 
+func (i *registryInspector) setRetryPolicy(retryPolicy RetryPolicy) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.retryPolicy = retryPolicy
+}
+
 func newRegistryInspector() IRegistryInspector {
-	ri := &registryInspector{}
+	ri := &registryInspector{
+		retryPolicy: DefaultRetryPolicy(),
+	}
 	return ri
 }
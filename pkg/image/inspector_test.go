@@ -0,0 +1,249 @@
+package image
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// blob is a content-addressed object served by the registry returned by newIndexRegistry.
+type blob struct {
+	mediaType string
+	content   []byte
+}
+
+func (b blob) digest() string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b.content))
+}
+
+// newImageBlobs returns the config and manifest blobs of a single-arch image for the given platform.
+func newImageBlobs(architecture, variant string) (config blob, imageManifest blob) {
+	config = blob{
+		mediaType: "application/vnd.oci.image.config.v1+json",
+		content: []byte(fmt.Sprintf(`{"architecture":%q,"variant":%q,"os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`,
+			architecture, variant)),
+	}
+	imageManifest = blob{
+		mediaType: "application/vnd.oci.image.manifest.v1+json",
+		content: []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",`+
+			`"config":{"mediaType":%q,"digest":%q,"size":%d},"layers":[]}`,
+			config.mediaType, config.digest(), len(config.content))),
+	}
+	return config, imageManifest
+}
+
+// newIndexRegistry returns an insecure registry serving foo/bar:latest with the given index content and
+// Content-Type. The manifests and blobs are served by digest.
+func newIndexRegistry(t *testing.T, indexContentType string, index []byte, blobs ...blob) *httptest.Server {
+	byDigest := map[string]blob{}
+	for _, b := range blobs {
+		byDigest[b.digest()] = b
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/foo/bar/manifests/latest":
+			w.Header().Set("Content-Type", indexContentType)
+			_, _ = w.Write(index)
+		case strings.HasPrefix(r.URL.Path, "/v2/foo/bar/manifests/"), strings.HasPrefix(r.URL.Path, "/v2/foo/bar/blobs/"):
+			b, ok := byDigest[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", b.mediaType)
+			_, _ = w.Write(b.content)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRegistryInspector_GetCompatibleArchitecturesSet_OCIIndex(t *testing.T) {
+	attestationConfig, attestationManifest := newImageBlobs("unknown", "")
+	amd64Config, amd64Manifest := newImageBlobs("amd64", "")
+	arm64Config, arm64Manifest := newImageBlobs("arm64", "v8")
+	armConfig, armManifest := newImageBlobs("arm", "v7")
+	x8664Config, x8664Manifest := newImageBlobs("x86_64", "")
+	blobs := []blob{attestationConfig, attestationManifest, amd64Config, amd64Manifest, arm64Config, arm64Manifest,
+		armConfig, armManifest, x8664Config, x8664Manifest}
+	descriptor := func(b blob, fields string) string {
+		return fmt.Sprintf(`{"mediaType":%q,"digest":%q,"size":%d%s}`, b.mediaType, b.digest(), len(b.content), fields)
+	}
+	// The attestation manifest is the first entry on purpose: it must neither contribute to the architectures
+	// nor be used to look for the operator-sdk label.
+	v11Manifests := strings.Join([]string{
+		descriptor(attestationManifest, `,"platform":{"architecture":"unknown","os":"unknown"},`+
+			`"annotations":{"vnd.docker.reference.type":"attestation-manifest"}`),
+		descriptor(attestationManifest, `,"artifactType":"application/vnd.example.sbom.v1+json"`),
+		descriptor(amd64Manifest, `,"platform":{"architecture":"amd64","os":"linux"}`),
+		descriptor(arm64Manifest, `,"platform":{"architecture":"arm64","os":"linux","variant":"v8"}`),
+		descriptor(armManifest, `,"platform":{"architecture":"arm","os":"linux","variant":"v7"}`),
+		descriptor(amd64Manifest, `,"platform":{"architecture":"amd64","os":"windows","os.version":"10.0.17763.1234"}`),
+	}, ",")
+	tests := []struct {
+		name            string
+		contentType     string
+		index           string
+		operatingSystem string
+		want            sets.Set[string]
+		wantErr         bool
+	}{
+		{
+			name:        "OCI image index v1.1 with artifacts and attestations",
+			contentType: "application/vnd.oci.image.index.v1+json",
+			index: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json",` +
+				`"artifactType":"application/vnd.example.index.v1","manifests":[` + v11Manifests + `]}`,
+			operatingSystem: utils.OSLinux,
+			want:            sets.New("amd64", "arm64", "arm/v7"),
+		},
+		{
+			name:        "OCI image index v1.1 inspected for windows",
+			contentType: "application/vnd.oci.image.index.v1+json",
+			index: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json",` +
+				`"manifests":[` + v11Manifests + `]}`,
+			operatingSystem: utils.OSWindows,
+			want:            sets.New("amd64"),
+		},
+		{
+			name:        "OCI image index without windows manifests inspected for windows",
+			contentType: "application/vnd.oci.image.index.v1+json",
+			index: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+				descriptor(arm64Manifest, `,"platform":{"architecture":"arm64","os":"linux"}`) + `]}`,
+			operatingSystem: utils.OSWindows,
+			want:            sets.New[string](),
+		},
+		{
+			name:        "OCI image index with a WebAssembly manifest",
+			contentType: "application/vnd.oci.image.index.v1+json",
+			index: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+				descriptor(amd64Manifest, `,"platform":{"architecture":"amd64","os":"linux"}`) + `,` +
+				descriptor(amd64Manifest, `,"platform":{"architecture":"wasm32","os":"wasip1"}`) + `]}`,
+			operatingSystem: utils.OSLinux,
+			want:            sets.New(utils.ArchitectureAmd64, utils.ArchitectureWasm32),
+		},
+		{
+			name:        "OCI image index with architecture synonyms",
+			contentType: "application/vnd.oci.image.index.v1+json",
+			index: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+				descriptor(amd64Manifest, `,"platform":{"architecture":"x86_64","os":"linux"}`) + `,` +
+				descriptor(arm64Manifest, `,"platform":{"architecture":"aarch64","os":"linux"}`) + `,` +
+				descriptor(armManifest, `,"platform":{"architecture":"armhfp","os":"linux"}`) + `]}`,
+			operatingSystem: utils.OSLinux,
+			want:            sets.New(utils.ArchitectureAmd64, utils.ArchitectureArm64, utils.ArchitectureArmV7),
+		},
+		{
+			name:            "OCI image manifest with an architecture synonym",
+			contentType:     "application/vnd.oci.image.manifest.v1+json",
+			index:           string(x8664Manifest.content),
+			operatingSystem: utils.OSLinux,
+			want:            sets.New(utils.ArchitectureAmd64),
+		},
+		{
+			name:            "OCI image index without mediaType and an unreliable Content-Type",
+			contentType:     "text/plain",
+			index:           `{"schemaVersion":2,"manifests":[` + v11Manifests + `]}`,
+			operatingSystem: utils.OSLinux,
+			want:            sets.New("amd64", "arm64", "arm/v7"),
+		},
+		{
+			name:        "OCI image index with only the attestation manifests",
+			contentType: "application/vnd.oci.image.index.v1+json",
+			index: `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+				descriptor(attestationManifest, `,"platform":{"architecture":"unknown","os":"unknown"}`) + `]}`,
+			operatingSystem: utils.OSLinux,
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newIndexRegistry(t, tt.contentType, []byte(tt.index), blobs...)
+			registryHost := strings.TrimPrefix(server.URL, "http://")
+			setupSystemConfig(t, registryHost)
+			got, err := newRegistryInspector().GetCompatibleArchitecturesSet(context.Background(),
+				fmt.Sprintf("//%s/foo/bar:latest", registryHost), tt.operatingSystem, true, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCompatibleArchitecturesSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("GetCompatibleArchitecturesSet() = %v, want %v", sets.List(got), sets.List(tt.want))
+			}
+		})
+	}
+}
+
+func TestRegistryInspector_resolveDigest(t *testing.T) {
+	amd64Config, amd64Manifest := newImageBlobs("amd64", "")
+	arm64Config, arm64Manifest := newImageBlobs("arm64", "")
+	index := blob{
+		mediaType: "application/vnd.oci.image.index.v1+json",
+		content: []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json",`+
+			`"manifests":[{"mediaType":%q,"digest":%q,"size":%d,"platform":{"architecture":"amd64","os":"linux"}},`+
+			`{"mediaType":%q,"digest":%q,"size":%d,"platform":{"architecture":"arm64","os":"linux"}}]}`,
+			amd64Manifest.mediaType, amd64Manifest.digest(), len(amd64Manifest.content),
+			arm64Manifest.mediaType, arm64Manifest.digest(), len(arm64Manifest.content))),
+	}
+	server := newIndexRegistry(t, index.mediaType, index.content, index, amd64Config, amd64Manifest, arm64Config,
+		arm64Manifest)
+	registryHost := strings.TrimPrefix(server.URL, "http://")
+	setupSystemConfig(t, registryHost)
+	inspector := newRegistryInspector()
+
+	pinned, err := inspector.resolveDigest(context.Background(), fmt.Sprintf("//%s/foo/bar:latest", registryHost), nil)
+	if err != nil {
+		t.Fatalf("resolveDigest() error = %v", err)
+	}
+	if want := fmt.Sprintf("//%s/foo/bar@%s", registryHost, index.digest()); pinned != want {
+		t.Errorf("resolveDigest() = %q, want %q", pinned, want)
+	}
+	// The pinned reference is inspected by digest
+	got, err := inspector.GetCompatibleArchitecturesSet(context.Background(), pinned, utils.OSLinux, true, nil)
+	if err != nil {
+		t.Fatalf("GetCompatibleArchitecturesSet() error = %v", err)
+	}
+	if want := sets.New(utils.ArchitectureAmd64, utils.ArchitectureArm64); !got.Equal(want) {
+		t.Errorf("GetCompatibleArchitecturesSet() = %v, want %v", sets.List(got), sets.List(want))
+	}
+
+	if _, err = inspector.resolveDigest(context.Background(), fmt.Sprintf("//%s/foo/unknown:latest", registryHost),
+		nil); err == nil {
+		t.Errorf("resolveDigest() of an unknown image did not fail")
+	}
+}
+
+func TestCompatibleArchitecture_Synonyms(t *testing.T) {
+	tests := []struct {
+		architecture string
+		variant      string
+		want         string
+	}{
+		{architecture: "x86_64", want: utils.ArchitectureAmd64},
+		{architecture: "X86_64", want: utils.ArchitectureAmd64},
+		{architecture: "aarch64", want: utils.ArchitectureArm64},
+		{architecture: "aarch64", variant: "v8", want: utils.ArchitectureArm64},
+		{architecture: "armhfp", want: utils.ArchitectureArmV7},
+		{architecture: "armv7l", variant: "v6", want: utils.ArchitectureArmV6},
+		{architecture: "ppc64el", want: utils.ArchitecturePpc64le},
+		{architecture: utils.ArchitectureS390x, want: utils.ArchitectureS390x},
+		{architecture: "arm", variant: "v7", want: utils.ArchitectureArmV7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.architecture+"/"+tt.variant, func(t *testing.T) {
+			got, ok := compatibleArchitecture(utils.OSLinux, tt.architecture, tt.variant, utils.OSLinux)
+			if !ok || got != tt.want {
+				t.Errorf("compatibleArchitecture() = %q, %t, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
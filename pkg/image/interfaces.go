@@ -23,9 +23,10 @@ import (
 )
 
 type ICache interface {
-	// GetCompatibleArchitecturesSet takes an image reference. a list of secrets and the client to the cluster and
-	// returns a set of architectures that are compatible with the image reference.
-	GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, skipCache bool, secrets [][]byte) (sets.Set[string], error)
+	// GetCompatibleArchitecturesSet takes an image reference, the operating system of the platforms to consider
+	// and a list of secrets and returns a set of architectures that are compatible with the image reference.
+	GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, operatingSystem string, skipCache bool,
+		secrets [][]byte) (sets.Set[string], error)
 }
 
 type IRegistryInspector interface {
@@ -34,4 +35,8 @@ type IRegistryInspector interface {
 	// in charge of watching the global pull secret and to store it in the ImageFacade's relevant private field.
 	// Then, the ImageFacade will be responsible for consuming it during the inspection.
 	storeGlobalPullSecret(pullSecret []byte)
+	// setRetryPolicy sets the RetryPolicy used to retry the manifest fetches failing with transient errors.
+	setRetryPolicy(retryPolicy RetryPolicy)
+	// resolveDigest returns the image reference pinned to the digest of the manifest the image reference resolves to.
+	resolveDigest(ctx context.Context, imageReference string, secrets [][]byte) (string, error)
 }
@@ -15,6 +15,7 @@ var (
 	InspectionGauge             prometheus.Gauge
 	TimeToInspectImageGivenHit  prometheus.Histogram
 	TimeToInspectImageGivenMiss prometheus.Histogram
+	CircuitBreakerState         *prometheus.GaugeVec
 )
 
 func InitCommonMetrics() {
@@ -38,6 +39,12 @@ func InitCommonMetrics() {
 				Buckets: utils.Buckets(),
 			})
 
-		metrics2.Registry.MustRegister(InspectionGauge)
+		CircuitBreakerState = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "mto_image_inspection_circuit_breaker_state",
+				Help: "The state of the image inspection circuit breaker of a registry (0: closed, 1: open, 2: half-open)",
+			}, []string{"registry"})
+
+		metrics2.Registry.MustRegister(InspectionGauge, CircuitBreakerState)
 	})
 }
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RegistryTimeouts maps the registry domains, e.g., registry.example.com:5000, to the maximum duration of the
+// inspections of the images they host. It implements flag.Value: its string form is the comma-separated list of
+// domain=duration pairs, sorted by domain, e.g., mirror.example.com=1m0s,quay.io=10s.
+type RegistryTimeouts map[string]time.Duration
+
+// String returns the comma-separated list of the domain=duration pairs, sorted by domain.
+func (r RegistryTimeouts) String() string {
+	pairs := make([]string, 0, len(r))
+	for _, domain := range slices.Sorted(maps.Keys(r)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", domain, r[domain]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set replaces the timeouts with the ones in the comma-separated list of domain=duration pairs.
+// The durations must be positive.
+func (r *RegistryTimeouts) Set(value string) error {
+	timeouts := RegistryTimeouts{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair == "" {
+			continue
+		}
+		domain, duration, ok := strings.Cut(pair, "=")
+		if !ok || domain == "" {
+			return fmt.Errorf("invalid registry timeout %q: it must be in the domain=duration form", pair)
+		}
+		timeout, err := time.ParseDuration(duration)
+		if err != nil {
+			return fmt.Errorf("invalid registry timeout %q: %w", pair, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("invalid registry timeout %q: the duration must be positive", pair)
+		}
+		timeouts[domain] = timeout
+	}
+	*r = timeouts
+	return nil
+}
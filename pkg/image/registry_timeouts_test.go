@@ -0,0 +1,77 @@
+package image
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegistryTimeouts_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    RegistryTimeouts
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  RegistryTimeouts{},
+		},
+		{
+			name:  "multiple registries",
+			value: "quay.io=10s,mirror.example.com:5000=1m",
+			want: RegistryTimeouts{
+				"quay.io":                 10 * time.Second,
+				"mirror.example.com:5000": time.Minute,
+			},
+		},
+		{
+			name:    "missing duration",
+			value:   "quay.io",
+			wantErr: true,
+		},
+		{
+			name:    "missing domain",
+			value:   "=10s",
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			value:   "quay.io=ten",
+			wantErr: true,
+		},
+		{
+			name:    "non-positive duration",
+			value:   "quay.io=0s",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RegistryTimeouts
+			err := got.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Set() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryTimeouts_String(t *testing.T) {
+	timeouts := RegistryTimeouts{
+		"quay.io":                 10 * time.Second,
+		"mirror.example.com:5000": time.Minute,
+	}
+	want := "mirror.example.com:5000=1m0s,quay.io=10s"
+	if got := timeouts.String(); got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+	var parsed RegistryTimeouts
+	if err := parsed.Set(timeouts.String()); err != nil || !reflect.DeepEqual(parsed, timeouts) {
+		t.Fatalf("Set(String()) = %v, %v, want %v", parsed, err, timeouts)
+	}
+}
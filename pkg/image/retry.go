@@ -0,0 +1,118 @@
+/*
+Copyright 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/docker/distribution/registry/api/errcode"
+)
+
+const (
+	DefaultRetryInitialInterval = 500 * time.Millisecond
+	DefaultRetryMaxInterval     = 10 * time.Second
+	DefaultRetryMultiplier      = 2.0
+	DefaultRetryMaxRetries      = 3
+)
+
+// RetryPolicy configures the retries of the manifest fetches that fail with transient errors.
+type RetryPolicy struct {
+	// InitialInterval is the time to wait before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the time to wait between two retries.
+	MaxInterval time.Duration
+	// Multiplier is the factor the time to wait is multiplied by after each retry.
+	Multiplier float64
+	// MaxRetries is the maximum number of retries. Zero disables the retries.
+	MaxRetries int
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialInterval: DefaultRetryInitialInterval,
+		MaxInterval:     DefaultRetryMaxInterval,
+		Multiplier:      DefaultRetryMultiplier,
+		MaxRetries:      DefaultRetryMaxRetries,
+	}
+}
+
+// retry runs fn until it succeeds, it returns a terminal error, the retries are exhausted or ctx is done.
+// It returns the last error returned by fn.
+// The wait.Backoff Cap is not used to enforce the MaxInterval as, once it is reached, it would also stop the retries.
+func (p RetryPolicy) retry(ctx context.Context, fn func() error) error {
+	backoff := wait.Backoff{
+		Duration: p.InitialInterval,
+		Factor:   p.Multiplier,
+		Steps:    p.MaxRetries + 1,
+	}
+	for {
+		err := fn()
+		if err == nil || backoff.Steps <= 1 || !isRetryableError(err) {
+			return err
+		}
+		timer := time.NewTimer(min(backoff.Step(), p.MaxInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryableError returns true if err is a transient error: a 5xx or 429 response from the registry,
+// a connection reset or a network timeout. Any other error, including the 4xx responses, is terminal.
+func isRetryableError(err error) bool {
+	if errors.Is(err, docker.ErrTooManyRequests) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var statusErr docker.UnexpectedHTTPStatusError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatusCode(statusErr.StatusCode)
+	}
+	var registryErr errcode.Error
+	if errors.As(err, &registryErr) {
+		return isRetryableStatusCode(registryErr.ErrorCode().Descriptor().HTTPStatusCode)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsTimeoutError returns true if err is caused by an exceeded deadline or a network timeout, e.g., because a
+// registry is slow to respond.
+func IsTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isRetryableStatusCode(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests
+}
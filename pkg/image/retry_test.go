@@ -0,0 +1,187 @@
+package image
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/containers/image/v5/docker"
+
+	"github.com/openshift/multiarch-tuning-operator/pkg/utils"
+)
+
+// newFlakyRegistry returns an insecure registry serving the single-arch image foo/bar:latest for the given
+// architecture. The first failures requests to the manifest return the failureStatusCode.
+func newFlakyRegistry(t *testing.T, architecture string, failures int32, failureStatusCode int) (*httptest.Server, *atomic.Int32) {
+	config := []byte(fmt.Sprintf(`{"architecture":%q,"os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`, architecture))
+	configDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(config))
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",`+
+		`"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":%q,"size":%d},"layers":[]}`,
+		configDigest, len(config)))
+	manifestRequests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/foo/bar/manifests/latest":
+			if manifestRequests.Add(1) <= failures {
+				w.WriteHeader(failureStatusCode)
+				return
+			}
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write(manifest)
+		case "/v2/foo/bar/blobs/" + configDigest:
+			_, _ = w.Write(config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, manifestRequests
+}
+
+// setupSystemConfig points the system configuration used by the registryInspector to a temporary directory
+// that marks registryHost as insecure.
+func setupSystemConfig(t *testing.T, registryHost string) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"registries.conf": fmt.Sprintf("[[registry]]\nlocation = %q\ninsecure = true\n", registryHost),
+		"policy.json":     `{"default":[{"type":"insecureAcceptAnything"}]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	rwMutex.Lock()
+	defer rwMutex.Unlock()
+	oldDockerCertsDir, oldRegistriesCertsDir, oldRegistriesConfPath, oldPolicyConfPath :=
+		dockerCertsDir, registriesCertsDir, registriesConfPath, policyConfPath
+	dockerCertsDir, registriesCertsDir = filepath.Join(dir, "certs.d"), filepath.Join(dir, "registries.d")
+	registriesConfPath, policyConfPath = filepath.Join(dir, "registries.conf"), filepath.Join(dir, "policy.json")
+	invalidateRegistriesCache()
+	t.Cleanup(func() {
+		rwMutex.Lock()
+		defer rwMutex.Unlock()
+		dockerCertsDir, registriesCertsDir, registriesConfPath, policyConfPath =
+			oldDockerCertsDir, oldRegistriesCertsDir, oldRegistriesConfPath, oldPolicyConfPath
+		invalidateRegistriesCache()
+	})
+}
+
+func TestRegistryInspector_GetCompatibleArchitecturesSet_Retry(t *testing.T) {
+	tests := []struct {
+		name                 string
+		failures             int32
+		failureStatusCode    int
+		maxRetries           int
+		want                 sets.Set[string]
+		wantErr              bool
+		wantManifestRequests int32
+	}{
+		{
+			name:                 "two 503 responses followed by a 200 response",
+			failures:             2,
+			failureStatusCode:    http.StatusServiceUnavailable,
+			maxRetries:           3,
+			want:                 sets.New("arm64"),
+			wantManifestRequests: 3,
+		},
+		{
+			name:                 "retries are exhausted",
+			failures:             3,
+			failureStatusCode:    http.StatusServiceUnavailable,
+			maxRetries:           2,
+			wantErr:              true,
+			wantManifestRequests: 3,
+		},
+		{
+			name:                 "4xx responses are terminal",
+			failures:             1,
+			failureStatusCode:    http.StatusNotFound,
+			maxRetries:           3,
+			wantErr:              true,
+			wantManifestRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, manifestRequests := newFlakyRegistry(t, "arm64", tt.failures, tt.failureStatusCode)
+			registryHost := strings.TrimPrefix(server.URL, "http://")
+			setupSystemConfig(t, registryHost)
+			inspector := newRegistryInspector()
+			inspector.setRetryPolicy(RetryPolicy{
+				InitialInterval: time.Millisecond,
+				MaxInterval:     10 * time.Millisecond,
+				Multiplier:      2,
+				MaxRetries:      tt.maxRetries,
+			})
+			got, err := inspector.GetCompatibleArchitecturesSet(context.Background(),
+				fmt.Sprintf("//%s/foo/bar:latest", registryHost), utils.OSLinux, true, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCompatibleArchitecturesSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("GetCompatibleArchitecturesSet() = %v, want %v", sets.List(got), sets.List(tt.want))
+			}
+			if manifestRequests.Load() != tt.wantManifestRequests {
+				t.Errorf("manifest requests = %d, want %d", manifestRequests.Load(), tt.wantManifestRequests)
+			}
+		})
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"503", fmt.Errorf("reading manifest: %w", docker.UnexpectedHTTPStatusError{StatusCode: 503}), true},
+		{"500", docker.UnexpectedHTTPStatusError{StatusCode: 500}, true},
+		{"404", docker.UnexpectedHTTPStatusError{StatusCode: 404}, false},
+		{"too many requests", docker.ErrTooManyRequests, true},
+		{"connection reset", fmt.Errorf("pinging registry: %w", syscall.ECONNRESET), true},
+		{"other error", errors.New("invalid reference"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTimeoutError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"deadline exceeded", fmt.Errorf("pinging registry: %w", context.DeadlineExceeded), true},
+		{"network timeout", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, true},
+		{"canceled", context.Canceled, false},
+		{"503", docker.UnexpectedHTTPStatusError{StatusCode: 503}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTimeoutError(tt.err); got != tt.want {
+				t.Errorf("IsTimeoutError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
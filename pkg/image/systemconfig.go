@@ -17,8 +17,14 @@ limitations under the License.
 package image
 
 import (
+	"context"
 	"os"
+	"slices"
 	"sync"
+
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/types"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var (
@@ -93,6 +99,48 @@ func PolicyConfPath() string {
 	return policyConfPath
 }
 
+// searchRegistriesCache caches the unqualified-search-registries of the registries.conf file, so that they are not
+// parsed again for each pod. It is invalidated along with the cache of the registries configuration of the
+// containers/image library, by invalidateRegistriesCache.
+var searchRegistriesCache struct {
+	mutex      sync.Mutex
+	loaded     bool
+	registries []string
+}
+
+// UnqualifiedSearchRegistries returns the unqualified-search-registries of the registries.conf file, in the order the
+// short names of the images are resolved. It returns nil if the file cannot be parsed.
+func UnqualifiedSearchRegistries(ctx context.Context) []string {
+	searchRegistriesCache.mutex.Lock()
+	defer searchRegistriesCache.mutex.Unlock()
+	if searchRegistriesCache.loaded {
+		return slices.Clone(searchRegistriesCache.registries)
+	}
+	registries, err := sysregistriesv2.UnqualifiedSearchRegistries(&types.SystemContext{
+		SystemRegistriesConfPath:    RegistriesConfPath(),
+		SystemRegistriesConfDirPath: RegistryCertsDir(),
+	})
+	if err != nil {
+		// The errors are not cached: the file is parsed again on the next call.
+		ctrllog.FromContext(ctx).Error(err, "Unable to read the unqualified search registries",
+			"registriesConfPath", RegistriesConfPath())
+		return nil
+	}
+	searchRegistriesCache.loaded = true
+	searchRegistriesCache.registries = registries
+	return slices.Clone(registries)
+}
+
+// invalidateRegistriesCache invalidates the caches of the registries configuration, so that the updates of the
+// registries.conf file are caught.
+func invalidateRegistriesCache() {
+	sysregistriesv2.InvalidateCache()
+	searchRegistriesCache.mutex.Lock()
+	defer searchRegistriesCache.mutex.Unlock()
+	searchRegistriesCache.loaded = false
+	searchRegistriesCache.registries = nil
+}
+
 func lookupEnvOr(key, defaultValue string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
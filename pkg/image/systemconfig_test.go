@@ -0,0 +1,36 @@
+package image
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestUnqualifiedSearchRegistries_Cache(t *testing.T) {
+	setupSystemConfig(t, "registry.example.com")
+	writeSearchRegistries := func(content string) {
+		if err := os.WriteFile(RegistriesConfPath(), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write the registries.conf file: %v", err)
+		}
+	}
+	writeSearchRegistries(`unqualified-search-registries = ["quay.io", "docker.io"]` + "\n")
+	if got := UnqualifiedSearchRegistries(context.Background()); len(got) != 2 || got[0] != "quay.io" {
+		t.Fatalf("UnqualifiedSearchRegistries() = %v, want [quay.io docker.io]", got)
+	}
+
+	writeSearchRegistries(`unqualified-search-registries = ["registry.example.com"]` + "\n")
+	got := UnqualifiedSearchRegistries(context.Background())
+	if len(got) != 2 || got[0] != "quay.io" {
+		t.Fatalf("UnqualifiedSearchRegistries() = %v, want the cached [quay.io docker.io]", got)
+	}
+	// The callers get a copy of the cached registries
+	got[0] = "mutated.example.com"
+	if got := UnqualifiedSearchRegistries(context.Background()); got[0] != "quay.io" {
+		t.Fatalf("UnqualifiedSearchRegistries() = %v, want the cached [quay.io docker.io]", got)
+	}
+
+	invalidateRegistriesCache()
+	if got := UnqualifiedSearchRegistries(context.Background()); len(got) != 1 || got[0] != "registry.example.com" {
+		t.Fatalf("UnqualifiedSearchRegistries() = %v, want [registry.example.com] after the invalidation", got)
+	}
+}
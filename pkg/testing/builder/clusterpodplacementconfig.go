@@ -64,3 +64,73 @@ func (p *ClusterPodPlacementConfigBuilder) WithNodeAffinityScoringTerm(architect
 	})
 	return p
 }
+
+func (p *ClusterPodPlacementConfigBuilder) WithInspectionTimeoutFallback(fallback v1beta1.InspectionTimeoutFallback) *ClusterPodPlacementConfigBuilder {
+	p.Spec.InspectionTimeoutFallback = fallback
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithFallbackArchitectures(architectures ...string) *ClusterPodPlacementConfigBuilder {
+	p.Spec.FallbackArchitectures = append(p.Spec.FallbackArchitectures, architectures...)
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithVirtualNodeTolerationStrategy(strategy v1beta1.VirtualNodeTolerationStrategy) *ClusterPodPlacementConfigBuilder {
+	p.Spec.VirtualNodeTolerationStrategy = strategy
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithVirtualNodeMatchers(matchers *v1beta1.VirtualNodeMatchers) *ClusterPodPlacementConfigBuilder {
+	p.Spec.VirtualNodeMatchers = matchers
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithTrustedOwnerKinds(kinds ...string) *ClusterPodPlacementConfigBuilder {
+	p.Spec.TrustedOwnerKinds = append(p.Spec.TrustedOwnerKinds, kinds...)
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithExcludedOwnerKinds(kinds ...string) *ClusterPodPlacementConfigBuilder {
+	p.Spec.ExcludedOwnerKinds = append(p.Spec.ExcludedOwnerKinds, kinds...)
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithExcludedOwnerReferenceKinds(refs ...v1beta1.ExcludedOwnerRef) *ClusterPodPlacementConfigBuilder {
+	p.Spec.ExcludedOwnerReferenceKinds = append(p.Spec.ExcludedOwnerReferenceKinds, refs...)
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithEnableArchitectureLabels(enabled bool) *ClusterPodPlacementConfigBuilder {
+	p.Spec.EnableArchitectureLabels = &enabled
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithLogSamplingRate(rate string) *ClusterPodPlacementConfigBuilder {
+	p.Spec.LogSamplingRate = rate
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithPinDigestInCache(enabled bool) *ClusterPodPlacementConfigBuilder {
+	p.Spec.PinDigestInCache = enabled
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithTrimToClusterArchitectures(enabled bool) *ClusterPodPlacementConfigBuilder {
+	p.Spec.TrimToClusterArchitectures = enabled
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithIgnoreHostNetworkPods(enabled bool) *ClusterPodPlacementConfigBuilder {
+	p.Spec.IgnoreHostNetworkPods = enabled
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithRepairStaleArchitectureAffinity(enabled bool) *ClusterPodPlacementConfigBuilder {
+	p.Spec.RepairStaleArchitectureAffinity = enabled
+	return p
+}
+
+func (p *ClusterPodPlacementConfigBuilder) WithRequireArchLabelOnNodes(enabled bool) *ClusterPodPlacementConfigBuilder {
+	p.Spec.RequireArchLabelOnNodes = enabled
+	return p
+}
@@ -2,6 +2,8 @@ package builder
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // OwnerReferenceBuilder is a builder for metav1.OwnerReference objects to be used only in unit tests.
@@ -21,6 +23,28 @@ func (o *OwnerReferenceBuilder) WithKind(kind string) *OwnerReferenceBuilder {
 	return o
 }
 
+func (o *OwnerReferenceBuilder) WithAPIVersion(apiVersion string) *OwnerReferenceBuilder {
+	o.ownerReference.APIVersion = apiVersion
+	return o
+}
+
+// WithGroupVersion sets the APIVersion of the owner reference from its API group and version. The group is empty for
+// the core API group.
+func (o *OwnerReferenceBuilder) WithGroupVersion(group, version string) *OwnerReferenceBuilder {
+	o.ownerReference.APIVersion = schema.GroupVersion{Group: group, Version: version}.String()
+	return o
+}
+
+func (o *OwnerReferenceBuilder) WithName(name string) *OwnerReferenceBuilder {
+	o.ownerReference.Name = name
+	return o
+}
+
+func (o *OwnerReferenceBuilder) WithUID(uid types.UID) *OwnerReferenceBuilder {
+	o.ownerReference.UID = uid
+	return o
+}
+
 func (o *OwnerReferenceBuilder) WithController(controller *bool) *OwnerReferenceBuilder {
 	o.ownerReference.Controller = controller
 	return o
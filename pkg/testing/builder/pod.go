@@ -52,19 +52,36 @@ func (p *PodBuilder) WithContainerImagePullAlways(image string) *PodBuilder {
 }
 
 func (p *PodBuilder) WithContainer(image string, imagePullPolicy v1.PullPolicy) *PodBuilder {
-	// compute hash of the image name
+	p.pod.Spec.Containers = append(p.pod.Spec.Containers, v1.Container{
+		Image:           image,
+		Name:            containerName(image),
+		ImagePullPolicy: imagePullPolicy,
+	})
+	return p
+}
+
+func (p *PodBuilder) WithEphemeralContainersImages(images ...string) *PodBuilder {
+	for _, image := range images {
+		p.pod.Spec.EphemeralContainers = append(p.pod.Spec.EphemeralContainers, v1.EphemeralContainer{
+			EphemeralContainerCommon: v1.EphemeralContainerCommon{
+				Image:           image,
+				Name:            containerName(image),
+				ImagePullPolicy: v1.PullIfNotPresent,
+			},
+		})
+	}
+	return p
+}
+
+// containerName returns the name of the container with the given image: the hash of the image name.
+func containerName(image string) string {
 	hasher := fnv.New128()
 	hasher.Write([]byte(image))
 	name := hex.EncodeToString(hasher.Sum(nil))
 	if len(name) > 63 {
 		name = name[:63]
 	}
-	p.pod.Spec.Containers = append(p.pod.Spec.Containers, v1.Container{
-		Image:           image,
-		Name:            name,
-		ImagePullPolicy: imagePullPolicy,
-	})
-	return p
+	return name
 }
 
 func (p *PodBuilder) WithInitContainersImages(images ...string) *PodBuilder {
@@ -172,6 +189,16 @@ func (p *PodBuilder) WithNodeName(nodeName string) *PodBuilder {
 	return p
 }
 
+func (p *PodBuilder) WithHostNetwork(hostNetwork bool) *PodBuilder {
+	p.pod.Spec.HostNetwork = hostNetwork
+	return p
+}
+
+func (p *PodBuilder) WithHostPID(hostPID bool) *PodBuilder {
+	p.pod.Spec.HostPID = hostPID
+	return p
+}
+
 func (p *PodBuilder) WithLabels(labelsKeyValuesPair ...string) *PodBuilder {
 	if p.pod.Labels == nil {
 		p.pod.Labels = make(map[string]string)
@@ -186,6 +213,20 @@ func (p *PodBuilder) WithLabels(labelsKeyValuesPair ...string) *PodBuilder {
 	return p
 }
 
+func (p *PodBuilder) WithAnnotations(annotationsKeyValuesPair ...string) *PodBuilder {
+	if p.pod.Annotations == nil {
+		p.pod.Annotations = make(map[string]string)
+	}
+	if len(annotationsKeyValuesPair)%2 != 0 {
+		// It's ok to panic as this is only used in unit tests.
+		panic("the number of arguments must be even")
+	}
+	for i := 0; i < len(annotationsKeyValuesPair); i += 2 {
+		p.pod.Annotations[annotationsKeyValuesPair[i]] = annotationsKeyValuesPair[i+1]
+	}
+	return p
+}
+
 func (p *PodBuilder) Build() *v1.Pod {
 	return p.pod
 }
@@ -2,7 +2,7 @@ package fake
 
 import (
 	"context"
-	"errors"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -10,17 +10,28 @@ import (
 type cacheProxy struct {
 	registryInspector        *registryInspector
 	imageRefsArchitectureMap map[string]sets.Set[string]
+	mutex                    sync.Mutex
 }
 
-func (c *cacheProxy) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, skipCache bool,
-	secrets [][]byte) (supportedArchitectures sets.Set[string], err error) {
-	// we expect the imageReference to start with `//`. Let's remove it
-	imageReference = imageReference[2:]
-	if archSet, ok := MockImagesArchitectureMap()[imageReference]; ok {
+// GetCompatibleArchitecturesSet emulates the cache of the image package: the registryInspector is only called
+// on cache misses or when skipCache is true, and its results refresh the cached entries.
+func (c *cacheProxy) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, operatingSystem string,
+	skipCache bool, secrets [][]byte) (supportedArchitectures sets.Set[string], err error) {
+	key := operatingSystem + imageReference
+	c.mutex.Lock()
+	archSet, ok := c.imageRefsArchitectureMap[key]
+	c.mutex.Unlock()
+	if ok && !skipCache {
 		return archSet, nil
 	}
-	// The image is not in the mock map, return an empty set (emulating an image not found or any other error)
-	return nil, errors.New("image not found")
+	archSet, err = c.registryInspector.GetCompatibleArchitecturesSet(ctx, imageReference, operatingSystem, true, secrets)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.imageRefsArchitectureMap[key] = archSet
+	c.mutex.Unlock()
+	return archSet, nil
 }
 
 func newCacheProxy() *cacheProxy {
@@ -16,21 +16,34 @@ var (
 )
 
 type Facade struct {
-	inspectionCache image.ICache
+	inspectionCache   image.ICache
+	registryInspector *registryInspector
 }
 
-func (i *Facade) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, skipCache bool,
-	secrets [][]byte) (architectures sets.Set[string], err error) {
-	return i.inspectionCache.GetCompatibleArchitecturesSet(ctx, imageReference, skipCache, secrets)
+func (i *Facade) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string, operatingSystem string,
+	skipCache bool, secrets [][]byte) (architectures sets.Set[string], err error) {
+	return i.inspectionCache.GetCompatibleArchitecturesSet(ctx, imageReference, operatingSystem, skipCache, secrets)
+}
+
+// InspectionsCount returns the number of calls to the remote manifest API for the given image reference, i.e.,
+// the inspections not served by the cache.
+func (i *Facade) InspectionsCount(imageReference string) int {
+	return i.registryInspector.inspectionsCount(imageReference)
 }
 
 func newImageFacade() *Facade {
 	inspectionCache := newCacheProxy()
 	return &Facade{
-		inspectionCache: inspectionCache,
+		inspectionCache:   inspectionCache,
+		registryInspector: inspectionCache.registryInspector,
 	}
 }
 
+// NewFacade returns a Facade with an empty cache and no recorded inspections.
+func NewFacade() *Facade {
+	return newImageFacade()
+}
+
 func FacadeSingleton() *Facade {
 	once.Do(func() {
 		singletonImageFacade = newImageFacade()
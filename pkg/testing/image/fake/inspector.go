@@ -3,6 +3,8 @@ package fake
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -12,6 +14,9 @@ import (
 type registryInspector struct {
 	// TBD: implement test with global pull secret merging
 	// globalPullSecret []byte
+	// inspections counts the calls to the remote manifest API, by image reference
+	inspections map[string]int
+	mutex       sync.Mutex
 }
 
 const (
@@ -19,6 +24,21 @@ const (
 	SingleArchArm64Image = "my-registry.io/library/single-arch-arm64-image:latest"
 	MultiArchImage       = "my-registry.io/library/multi-arch-image:latest"
 	MultiArchImage2      = "my-registry.io/library/multi-arch-image2:latest"
+	SingleArchArmV7Image = "my-registry.io/library/single-arch-arm-v7-image:latest"
+	MultiArchArmImage    = "my-registry.io/library/multi-arch-arm-image:latest"
+	// MultiArchOCIIndexImage is served as an OCI image index v1.1 that also references the attestation manifests
+	MultiArchOCIIndexImage = "my-registry.io/library/multi-arch-oci-index-image:latest"
+	// MultiOSImage has both linux and windows platform entries
+	MultiOSImage = "my-registry.io/library/multi-os-image:latest"
+	// SingleArchWindowsImage only has a windows/amd64 platform entry
+	SingleArchWindowsImage = "my-registry.io/library/single-arch-windows-image:latest"
+	// WasmImage only has a wasip1/wasm32 platform entry
+	WasmImage = "my-registry.io/library/wasm-image:latest"
+	// EphemeralContainerImage only has a linux/s390x platform entry: it is the image of the ephemeral debug
+	// containers, which do not constrain the architectures of the pods as they are added to the scheduled pods only
+	EphemeralContainerImage = "my-registry.io/library/ephemeral-container-image:latest"
+	// TimeoutImage is hosted by a registry that never responds in time: its inspection fails with a deadline exceeded
+	TimeoutImage = "my-slow-registry.io/library/timeout-image:latest"
 )
 
 // MockImagesArchitectureMap returns a map of image references to their supported architectures
@@ -30,20 +50,58 @@ func MockImagesArchitectureMap() map[string]sets.Set[string] {
 		MultiArchImage:       sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
 		MultiArchImage2: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64,
 			utils.ArchitecturePpc64le, utils.ArchitectureS390x),
+		SingleArchArmV7Image: sets.New[string](utils.ArchitectureArmV7),
+		MultiArchArmImage: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64,
+			utils.ArchitectureArmV6, utils.ArchitectureArmV7),
+		MultiArchOCIIndexImage: sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64,
+			utils.ArchitecturePpc64le),
+		MultiOSImage:            sets.New[string](utils.ArchitectureAmd64, utils.ArchitectureArm64),
+		SingleArchWindowsImage:  sets.New[string](),
+		WasmImage:               sets.New[string](utils.ArchitectureWasm32),
+		EphemeralContainerImage: sets.New[string](utils.ArchitectureS390x),
+	}
+}
+
+// MockWindowsImagesArchitectureMap returns a map of image references to the architectures they support on windows.
+// The images that are not in the map support no architecture on windows.
+func MockWindowsImagesArchitectureMap() map[string]sets.Set[string] {
+	return map[string]sets.Set[string]{
+		MultiOSImage:           sets.New[string](utils.ArchitectureAmd64),
+		SingleArchWindowsImage: sets.New[string](utils.ArchitectureAmd64),
 	}
 }
 
 func (i *registryInspector) GetCompatibleArchitecturesSet(ctx context.Context, imageReference string,
-	skipCache bool, secrets [][]byte) (supportedArchitectures sets.Set[string], err error) {
+	operatingSystem string, skipCache bool, secrets [][]byte) (supportedArchitectures sets.Set[string], err error) {
+	i.mutex.Lock()
+	i.inspections[imageReference]++
+	i.mutex.Unlock()
 	// we expect the imageReference to start with `//`. Let's remove it
 	imageReference = imageReference[2:]
 	if archSet, ok := MockImagesArchitectureMap()[imageReference]; ok {
+		if operatingSystem == utils.OSWindows {
+			if archSet, ok = MockWindowsImagesArchitectureMap()[imageReference]; !ok {
+				return sets.New[string](), nil
+			}
+		}
 		return archSet, nil
 	}
+	if imageReference == TimeoutImage {
+		return nil, fmt.Errorf("pinging container registry my-slow-registry.io: %w", context.DeadlineExceeded)
+	}
 	// The image is not in the mock map, return an empty set (emulating an image not found or any other error)
 	return nil, errors.New("image not found")
 }
 
+// inspectionsCount returns the number of calls to the remote manifest API for the given image reference.
+func (i *registryInspector) inspectionsCount(imageReference string) int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.inspections[imageReference]
+}
+
 func newRegistryInspector() *registryInspector {
-	return &registryInspector{}
+	return &registryInspector{
+		inspections: map[string]int{},
+	}
 }
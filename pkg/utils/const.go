@@ -15,8 +15,29 @@ const (
 	ArchitectureS390x   = "s390x"
 )
 
+// The architectures qualified by the ARM variant, as reported by the platform of the images.
+const (
+	ArchitectureArmV6 = "arm/v6"
+	ArchitectureArmV7 = "arm/v7"
+	ArchitectureArmV8 = "arm64/v8"
+)
+
+// ArchitectureWasm32 is the architecture of the WebAssembly images. It only intersects with itself: the pods whose
+// images all support it are scheduled on the nodes labeled with it.
+const ArchitectureWasm32 = "wasm32"
+
+// The operating systems of the platform entries of the images the pods can target.
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+	// OSWasip1 and OSWasm are the operating systems of the WebAssembly platform entries. They run on the linux nodes.
+	OSWasip1 = "wasip1"
+	OSWasm   = "wasm"
+)
+
 const (
 	ArchLabel                       = "kubernetes.io/arch"
+	OSLabel                         = "kubernetes.io/os"
 	NodeAffinityLabel               = "multiarch.openshift.io/node-affinity"
 	PreferredNodeAffinityLabel      = "multiarch.openshift.io/preferred-node-affinity"
 	NodeAffinityLabelValueSet       = "set"
@@ -29,9 +50,46 @@ const (
 	SingleArchLabel                 = "multiarch.openshift.io/single-arch"
 	MultiArchLabel                  = "multiarch.openshift.io/multi-arch"
 	NoSupportedArchLabel            = "multiarch.openshift.io/no-supported-arch"
+	WasmArchLabel                   = "multiarch.openshift.io/arch-wasm32"
 	ImageInspectionErrorLabel       = "multiarch.openshift.io/image-inspect-error"
 	ImageInspectionErrorCountLabel  = "multiarch.openshift.io/image-inspect-error-count"
 	LabelGroup                      = "multiarch.openshift.io"
+	// InspectionFallbackLabel is set to the InspectionTimeoutFallback applied to the pods whose images could not be
+	// inspected in time, e.g., allow-all: their architecture requirement is not the one of their images.
+	InspectionFallbackLabel = "multiarch.openshift.io/inspection-fallback"
+	// StaleArchitectureAffinityLabel is set on the pods whose architecture requirement was set by a previous version
+	// of the operator and cannot be updated anymore, as they are not gated: they must be recreated to update it.
+	StaleArchitectureAffinityLabel = "multiarch.openshift.io/stale-architecture-affinity"
+	// ArchLabelPrefix is the prefix of the keys of the labels the operator sets on the pods, e.g., the
+	// MultiArchLabel, the NoSupportedArchLabel, and the per-architecture labels.
+	ArchLabelPrefix = LabelGroup + "/"
+	// ArchitectureOverrideAnnotation lets the users set the comma-separated list of the architectures supported by
+	// the pod, skipping the inspection of its images.
+	ArchitectureOverrideAnnotation = "multiarch.openshift.io/override-arch"
+	// ForceRefreshAnnotation, when set to "true", lets the images of the pod be inspected bypassing the cache,
+	// e.g., when a tag is pushed again with a different set of architectures.
+	ForceRefreshAnnotation = "multiarch.openshift.io/force-refresh"
+	// IgnoreAnnotation, when set to "true", lets the pod be ignored by the pod placement operands, e.g., to
+	// disable the architecture-aware scheduling of specific pods. The only valid values are "true" and "false".
+	IgnoreAnnotation = "multiarch.openshift.io/ignore"
+	// OwnerKindAnnotation lets the pods created without owner references declare the kind of their owner, e.g.,
+	// DaemonSet. It is only honoured for the kinds in the TrustedOwnerKinds of the ClusterPodPlacementConfig.
+	OwnerKindAnnotation = "multiarch.openshift.io/owner-kind"
+	// CompletedSchedulingGatesAnnotation lets the stages of a processing pipeline declare, as a comma-separated
+	// list, the managed scheduling gates whose processing is completed, so that the operand removes them from the pod.
+	CompletedSchedulingGatesAnnotation = "multiarch.openshift.io/completed-scheduling-gates"
+	// InspectedImagesAnnotation is set by the operand to the JSON array of the images it inspected to compute the
+	// architecture requirement of the pod.
+	InspectedImagesAnnotation = "multiarch.openshift.io/inspected-images"
+	// InspectedAtAnnotation is set by the operand to the RFC3339 time of the inspection of the images of the pod.
+	InspectedAtAnnotation = "multiarch.openshift.io/inspected-at"
+	// ProcessingDurationAnnotation is set by the operand, when it removes the scheduling gate, to the seconds elapsed
+	// from the gate injection to the gate removal.
+	ProcessingDurationAnnotation = "multiarch.openshift.io/processing-duration-seconds"
+	// PreferredArchitectureAnnotation lets the users set the weights of the architectures the pod prefers, as a
+	// comma-separated list of architecture:weight pairs, e.g., arm64:100,amd64:50. The weights range from 1 to 100.
+	// It takes precedence over the NodeAffinityScoring plugin of the ClusterPodPlacementConfig.
+	PreferredArchitectureAnnotation = "multiarch.openshift.io/preferred-arch"
 )
 
 const (
@@ -39,13 +97,42 @@ const (
 	SchedulingGateName            = "multiarch.openshift.io/scheduling-gate"
 	MasterNodeSelectorLabel       = "node-role.kubernetes.io/master"
 	ControlPlaneNodeSelectorLabel = "node-role.kubernetes.io/control-plane"
+	// InfraNodeSelectorLabel and InfraNodeSelectorValue select the OpenShift infrastructure nodes, e.g., running the
+	// routers and the registry.
+	InfraNodeSelectorLabel = "node-role.kubernetes.io/infra"
+	InfraNodeSelectorValue = ""
+)
+
+const (
+	// DefaultGlobalPullSecretNamespace and DefaultGlobalPullSecretName locate the cluster global pull secret.
+	DefaultGlobalPullSecretNamespace = "openshift-config"
+	DefaultGlobalPullSecretName      = "pull-secret"
+)
+
+const (
+	PodMutatingWebhookConfigurationName   = "pod-placement-mutating-webhook-configuration"
+	PodMutatingWebhookName                = "pod-placement-scheduling-gate.multiarch.openshift.io"
+	PodValidatingWebhookConfigurationName = "pod-placement-validating-webhook-configuration"
+	PodValidatingWebhookName              = "pod-architecture-validation.multiarch.openshift.io"
+	JobValidatingWebhookName              = "job-architecture-validation.multiarch.openshift.io"
+	WorkloadValidatingWebhookName         = "workload-architecture-validation.multiarch.openshift.io"
+	PodPlacementControllerName            = "pod-placement-controller"
+	PodPlacementWebhookName               = "pod-placement-web-hook"
+	ImageInspectionCacheConfigMapName     = "pod-placement-image-inspection-cache"
+	// ImageInspectionCacheGenerationConfigMapName is the name of the ConfigMap holding the generation of the image
+	// inspection caches shared by the replicas of the pod placement controller.
+	ImageInspectionCacheGenerationConfigMapName = "pod-placement-image-inspection-cache-generation"
+	// ImageInspectionHealthConfigMapName is the name of the ConfigMap the pod placement controller reports the
+	// image inspections of the last rolling window in, for the operator to derive the CacheHealthy condition.
+	ImageInspectionHealthConfigMapName = "pod-placement-image-inspection-health"
 )
 
+// The keys of the data of the ImageInspectionHealthConfigMapName ConfigMap.
 const (
-	PodMutatingWebhookConfigurationName = "pod-placement-mutating-webhook-configuration"
-	PodMutatingWebhookName              = "pod-placement-scheduling-gate.multiarch.openshift.io"
-	PodPlacementControllerName          = "pod-placement-controller"
-	PodPlacementWebhookName             = "pod-placement-web-hook"
+	ImageInspectionHealthInspectionsKey = "inspections"
+	ImageInspectionHealthFailuresKey    = "failures"
+	ImageInspectionHealthWindowKey      = "window"
+	ImageInspectionHealthUpdateTimeKey  = "updateTime"
 )
 
 func AllSupportedArchitecturesSet() sets.Set[string] {
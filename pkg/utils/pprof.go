@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	// Register the pprof handlers in the http.DefaultServeMux. They are only served by the PprofServer.
+	//#nosec G108 (CWE-200): Profiling endpoint is automatically exposed on /debug/pprof
+	_ "net/http/pprof"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	DefaultPprofBindAddress = ":8083"
+	pprofShutdownTimeout    = 10 * time.Second
+)
+
+// PprofServer serves the net/http/pprof handlers of the http.DefaultServeMux at /debug/pprof/.
+// The port must not be exposed outside the cluster: no Service targets it.
+type PprofServer struct {
+	listener net.Listener
+}
+
+// NewPprofServer returns a PprofServer listening on the given address.
+func NewPprofServer(addr string) (*PprofServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &PprofServer{listener: listener}, nil
+}
+
+// Start serves the pprof handlers until ctx is done, then shuts down the server gracefully.
+func (s *PprofServer) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx, "handler", "PprofServer", "address", s.listener.Addr().String())
+	server := &http.Server{
+		Handler:           http.DefaultServeMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("Starting the pprof server")
+		if err := server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Info("Stopping the pprof server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), pprofShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// NeedLeaderElection returns false: all the replicas can be profiled.
+func (s *PprofServer) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+func TestPprofServer(t *testing.T) {
+	server, err := NewPprofServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewPprofServer() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(ctx)
+	}()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/debug/pprof/goroutine", server.listener.Addr()))
+	if err != nil {
+		t.Fatalf("failed to get the goroutine profile: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read the goroutine profile: %v", err)
+	}
+	if _, err := profile.ParseData(data); err != nil {
+		t.Errorf("the goroutine profile is not valid pprof data: %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("the pprof server did not stop")
+	}
+}
@@ -72,6 +72,9 @@ func ApplyResource(ctx context.Context, clientSet *kubernetes.Clientset, client
 	case *admissionv1.MutatingWebhookConfiguration:
 		return resourceapply.ApplyMutatingWebhookConfigurationImproved(ctx, clientSet.AdmissionregistrationV1(),
 			recorder, t, resourceCache)
+	case *admissionv1.ValidatingWebhookConfiguration:
+		return resourceapply.ApplyValidatingWebhookConfigurationImproved(ctx, clientSet.AdmissionregistrationV1(),
+			recorder, t, resourceCache)
 	case *rbacv1.Role:
 		return resourceapply.ApplyRole(ctx, clientSet.RbacV1(), recorder, t)
 	case *rbacv1.RoleBinding:
@@ -14,6 +14,8 @@ import (
 
 var namespace string
 var image string
+var schedulingGateName = SchedulingGateName
+var managedSchedulingGates []string
 var AtomicLevel zap.AtomicLevel = zap.NewAtomicLevelAt(-5)
 var availableResourcesMap = map[schema.GroupVersionResource]bool{}
 var rwMutex = sync.RWMutex{}
@@ -37,6 +39,32 @@ func Namespace() string {
 	return namespace
 }
 
+// GetSchedulingGateName returns the name of the scheduling gate the operands add to and remove from the pods.
+func GetSchedulingGateName() string {
+	return schedulingGateName
+}
+
+// SetSchedulingGateName sets the name of the scheduling gate used by the operands. It must be called before
+// starting them. An empty name restores the default SchedulingGateName.
+func SetSchedulingGateName(name string) {
+	if name == "" {
+		name = SchedulingGateName
+	}
+	schedulingGateName = name
+}
+
+// GetManagedSchedulingGates returns the names of the additional scheduling gates the operands add to the pods and
+// remove from them once their processing is completed.
+func GetManagedSchedulingGates() []string {
+	return managedSchedulingGates
+}
+
+// SetManagedSchedulingGates sets the names of the additional scheduling gates managed by the operands. It must be
+// called before starting them.
+func SetManagedSchedulingGates(names []string) {
+	managedSchedulingGates = names
+}
+
 // Image returns the image used to run the operator.
 func Image() string {
 	return image
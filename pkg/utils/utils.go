@@ -2,6 +2,7 @@ package utils
 
 import (
 	"path"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -11,8 +12,25 @@ func NewPtr[T any](a T) *T {
 	return &a
 }
 
+// ArchLabelValue returns the label key for the given architecture. The variant-qualified architectures,
+// e.g., arm/v7, are mapped to arm-v7 as a label key cannot have more than one slash.
 func ArchLabelValue(arch string) string {
-	return path.Join(LabelGroup, arch)
+	return path.Join(LabelGroup, strings.ReplaceAll(arch, "/", "-"))
+}
+
+// PlatformArchitecture returns the architecture of an image platform qualified by its variant, e.g., arm/v7.
+// The variant v8 is the default one for arm64: it is omitted so that arm64/v8 images match the arm64 ones.
+func PlatformArchitecture(arch, variant string) string {
+	if variant == "" || path.Join(arch, variant) == ArchitectureArmV8 {
+		return arch
+	}
+	return path.Join(arch, variant)
+}
+
+// ArchitectureWithoutVariant returns the architecture without the variant, e.g., arm for arm/v7.
+func ArchitectureWithoutVariant(arch string) string {
+	base, _, _ := strings.Cut(arch, "/")
+	return base
 }
 
 func HistogramObserve(initialTime time.Time, histogram prometheus.Histogram) {